@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/account"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler handles account administration requests
+type AccountHandler struct {
+	repo account.AccountRepository
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(repo account.AccountRepository) *AccountHandler {
+	return &AccountHandler{repo: repo}
+}
+
+// CreateAccount godoc
+// @Summary Open a new account
+// @Description Register an account that orders can belong to. API keys name it in account_id and tokens in their account claim; callers only see their own account's orders.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Param account body models.AccountRequest true "Account details"
+// @Success 201 {object} models.Account
+// @Failure 400 {object} models.ValidationErrorResponse "Validation failed"
+// @Failure 409 {object} models.ErrorResponse "Account already exists"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/accounts [post]
+func (h *AccountHandler) CreateAccount(c *gin.Context) {
+	var request models.AccountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		writeValidationErrors(c, newValidationErrorResponse(err))
+		return
+	}
+
+	created := models.Account{ID: request.ID, Name: request.Name}
+	err := h.repo.Create(c.Request.Context(), &created)
+	if errors.Is(err, account.ErrAccountExists) {
+		c.JSON(http.StatusConflict, errorResponse(c, "Account already exists"))
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to create account", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, &created)
+}
+
+// GetAccounts godoc
+// @Summary Get accounts
+// @Description Retrieve every account, ordered by ID
+// @Tags accounts
+// @Produce json
+// @Success 200 {array} models.Account
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/accounts [get]
+func (h *AccountHandler) GetAccounts(c *gin.Context) {
+	accounts, err := h.repo.GetAll(c.Request.Context())
+	if err != nil {
+		writeServerError(c, "Failed to fetch accounts", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// GetAccount godoc
+// @Summary Get an account
+// @Description Retrieve a single account by its ID
+// @Tags accounts
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} models.Account
+// @Failure 404 {object} models.ErrorResponse "Account not found"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/accounts/{id} [get]
+func (h *AccountHandler) GetAccount(c *gin.Context) {
+	found, err := h.repo.GetByID(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, account.ErrAccountNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "Account not found"))
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to fetch account", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, found)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/account"
+)
+
+// MockAccountRepository is a mock implementation of AccountRepository interface
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountRepository) Create(ctx context.Context, account *models.Account) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) GetAll(ctx context.Context) ([]models.Account, error) {
+	args := m.Called(ctx)
+	accounts, _ := args.Get(0).([]models.Account)
+	return accounts, args.Error(1)
+}
+
+func (m *MockAccountRepository) GetByID(ctx context.Context, id string) (*models.Account, error) {
+	args := m.Called(ctx, id)
+	found, _ := args.Get(0).(*models.Account)
+	return found, args.Error(1)
+}
+
+func TestCreateAccountHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name         string
+		body         string
+		createErr    error
+		expectedCode int
+	}{
+		{name: "Created", body: `{"id": "acme", "name": "Acme Corp"}`, expectedCode: http.StatusCreated},
+		{name: "Already exists", body: `{"id": "acme", "name": "Acme Corp"}`, createErr: account.ErrAccountExists, expectedCode: http.StatusConflict},
+		{name: "Missing name", body: `{"id": "acme"}`, expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockAccountRepository)
+			handler := NewAccountHandler(mockRepo)
+
+			// Setup expectations
+			if tc.expectedCode != http.StatusBadRequest {
+				mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(created *models.Account) bool {
+					return created.ID == "acme" && created.Name == "Acme Corp"
+				})).Return(tc.createErr)
+			}
+
+			// Setup Gin router
+			router := gin.Default()
+			router.POST("/api/v1/admin/accounts", handler.CreateAccount)
+
+			// Perform request
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/accounts", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAccountHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockAccountRepository)
+
+	// Create handler with mock repo
+	handler := NewAccountHandler(mockRepo)
+
+	// Setup expectations
+	mockRepo.On("GetAll", mock.Anything).Return([]models.Account{{ID: "acme", Name: "Acme Corp"}}, nil)
+	mockRepo.On("GetByID", mock.Anything, "acme").Return(&models.Account{ID: "acme", Name: "Acme Corp"}, nil)
+	mockRepo.On("GetByID", mock.Anything, "ghost").Return(nil, account.ErrAccountNotFound)
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/admin/accounts", handler.GetAccounts)
+	router.GET("/api/v1/admin/accounts/:id", handler.GetAccount)
+
+	// Perform requests
+	list := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/accounts", nil)
+	router.ServeHTTP(list, req)
+
+	w := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/accounts/acme", nil)
+	router.ServeHTTP(w, req)
+
+	notFound := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/accounts/ghost", nil)
+	router.ServeHTTP(notFound, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, list.Code)
+	var accounts []models.Account
+	err := json.Unmarshal(list.Body.Bytes(), &accounts)
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 1)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.Account
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme Corp", response.Name)
+
+	assert.Equal(t, http.StatusNotFound, notFound.Code)
+
+	mockRepo.AssertExpectations(t)
+}
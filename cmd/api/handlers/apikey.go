@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/apikey"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler handles API key administration requests
+type APIKeyHandler struct {
+	repo apikey.APIKeyRepository
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(repo apikey.APIKeyRepository) *APIKeyHandler {
+	return &APIKeyHandler{repo: repo}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Issue a key with the given scopes: orders:read, orders:write or admin, which grants every scope, together with a secret for signing requests. Both are only returned in this response; store them safely. A key needs an account_id, naming a registered account, to use the order routes.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param key body models.APIKeyRequest true "Key details"
+// @Success 201 {object} models.APIKeySecret
+// @Failure 400 {object} models.ValidationErrorResponse "Validation failed"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var request models.APIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		writeValidationErrors(c, newValidationErrorResponse(err))
+		return
+	}
+
+	secret, prefix, keyHash, err := apikey.Generate()
+	if err != nil {
+		writeServerError(c, "Failed to create API key", err)
+		return
+	}
+	signingSecret, err := apikey.GenerateSigningSecret()
+	if err != nil {
+		writeServerError(c, "Failed to create API key", err)
+		return
+	}
+
+	key := models.APIKey{
+		Name:          request.Name,
+		Prefix:        prefix,
+		KeyHash:       keyHash,
+		SigningSecret: signingSecret,
+		Scopes:        models.Scopes(request.Scopes),
+	}
+	if request.AccountID != "" {
+		key.AccountID = &request.AccountID
+	}
+
+	err = h.repo.Create(c.Request.Context(), &key)
+	if errors.Is(err, apikey.ErrAccountNotFound) {
+		writeValidationErrors(c, models.ValidationErrorResponse{Errors: []models.ValidationError{{
+			Field:   "accountid",
+			Message: fmt.Sprintf("account_id %s is not a registered account", request.AccountID),
+		}}})
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to create API key", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIKeySecret{APIKey: key, Key: secret, SigningSecret: signingSecret})
+}
+
+// GetAPIKeys godoc
+// @Summary Get API keys
+// @Description Retrieve every API key, revoked ones included, without their secrets
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} models.APIKey
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	keys, err := h.repo.GetAll(c.Request.Context())
+	if err != nil {
+		writeServerError(c, "Failed to fetch API keys", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate an API key
+// @Description Replace the key and signing secret of a live key, keeping its name and scopes. The previous ones stop working at once; the new ones are only returned in this response.
+// @Tags api-keys
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} models.APIKeySecret
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 404 {object} models.ErrorResponse "API key not found or revoked"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	id, ok := parseID(c, "Invalid API key ID")
+	if !ok {
+		return
+	}
+
+	secret, prefix, keyHash, err := apikey.Generate()
+	if err != nil {
+		writeServerError(c, "Failed to rotate API key", err)
+		return
+	}
+	signingSecret, err := apikey.GenerateSigningSecret()
+	if err != nil {
+		writeServerError(c, "Failed to rotate API key", err)
+		return
+	}
+
+	key, err := h.repo.Rotate(c.Request.Context(), id, prefix, keyHash, signingSecret)
+	if err != nil {
+		writeAPIKeyError(c, err, "Failed to rotate API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIKeySecret{APIKey: *key, Key: secret, SigningSecret: signingSecret})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Disable a key for good. Requests made with it are rejected from then on.
+// @Tags api-keys
+// @Param id path int true "API key ID"
+// @Success 204 "API key revoked"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID"
+// @Failure 404 {object} models.ErrorResponse "API key not found or revoked"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, ok := parseID(c, "Invalid API key ID")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Revoke(c.Request.Context(), id); err != nil {
+		writeAPIKeyError(c, err, "Failed to revoke API key")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// writeAPIKeyError maps API key repository errors onto HTTP responses
+func writeAPIKeyError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, apikey.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "API key not found or revoked"))
+		return
+	}
+	writeServerError(c, fallback, err)
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/apikey"
+)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository interface
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetAll(ctx context.Context) ([]models.APIKey, error) {
+	args := m.Called(ctx)
+	keys, _ := args.Get(0).([]models.APIKey)
+	return keys, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	key, _ := args.Get(0).(*models.APIKey)
+	return key, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetSigningSecret(ctx context.Context, id int64) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Rotate(ctx context.Context, id int64, prefix, keyHash, signingSecret string) (*models.APIKey, error) {
+	args := m.Called(ctx, id, prefix, keyHash, signingSecret)
+	key, _ := args.Get(0).(*models.APIKey)
+	return key, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCreateAPIKeyHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockAPIKeyRepository)
+
+	// Create handler with mock repo
+	handler := NewAPIKeyHandler(mockRepo)
+
+	// Setup expectations: only the hash of the key is stored
+	var stored *models.APIKey
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.APIKey)
+		stored.ID = 7
+		stored.CreatedAt = time.Now()
+	}).Return(nil)
+
+	// Prepare request
+	body := `{"name": "trading-bot", "scopes": ["orders:read", "orders:write"]}`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/api-keys", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/admin/api-keys", handler.CreateAPIKey)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.APIKeySecret
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), response.ID)
+	assert.Equal(t, models.Scopes{models.ScopeOrdersRead, models.ScopeOrdersWrite}, response.Scopes)
+	assert.True(t, strings.HasPrefix(response.Key, response.Prefix))
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, apikey.Hash(response.Key), stored.KeyHash)
+		assert.Len(t, response.SigningSecret, 64)
+		assert.Equal(t, stored.SigningSecret, response.SigningSecret)
+	}
+	assert.NotContains(t, w.Body.String(), stored.KeyHash)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateAPIKeyValidation(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{name: "Missing name", body: `{"scopes": ["orders:read"]}`},
+		{name: "No scopes", body: `{"name": "bot", "scopes": []}`},
+		{name: "Unknown scope", body: `{"name": "bot", "scopes": ["orders:delete"]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository; nothing is stored
+			mockRepo := new(MockAPIKeyRepository)
+			handler := NewAPIKeyHandler(mockRepo)
+
+			// Setup Gin router
+			router := gin.Default()
+			router.POST("/api/v1/admin/api-keys", handler.CreateAPIKey)
+
+			// Perform request
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/api-keys", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateAPIKeyForAccount(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockAPIKeyRepository)
+
+	// Create handler with mock repo
+	handler := NewAPIKeyHandler(mockRepo)
+
+	// Setup expectations: the key acts for the named account, which must be registered
+	forAccount := func(account string) interface{} {
+		return mock.MatchedBy(func(key *models.APIKey) bool {
+			return key.AccountID != nil && *key.AccountID == account
+		})
+	}
+	mockRepo.On("Create", mock.Anything, forAccount("acme")).Return(nil)
+	mockRepo.On("Create", mock.Anything, forAccount("ghost")).Return(apikey.ErrAccountNotFound)
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/admin/api-keys", handler.CreateAPIKey)
+
+	// Perform requests
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/api-keys", bytes.NewBufferString(`{"name": "acme-bot", "account_id": "acme", "scopes": ["orders:write"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	unknown := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/admin/api-keys", bytes.NewBufferString(`{"name": "ghost-bot", "account_id": "ghost", "scopes": ["orders:write"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(unknown, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"account_id":"acme"`)
+
+	assert.Equal(t, http.StatusBadRequest, unknown.Code)
+	var response models.ValidationErrorResponse
+	err := json.Unmarshal(unknown.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, "accountid", response.Errors[0].Field)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRotateAPIKeyHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockAPIKeyRepository)
+
+	// Create handler with mock repo
+	handler := NewAPIKeyHandler(mockRepo)
+
+	// Setup expectations: key 7 gets a new secret, key 8 is revoked
+	var rotatedHash string
+	var rotatedSecret string
+	mockRepo.On("Rotate", mock.Anything, int64(7), mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		rotatedHash = args.String(3)
+		rotatedSecret = args.String(4)
+	}).Return(&models.APIKey{ID: 7, Name: "trading-bot", Scopes: models.Scopes{models.ScopeOrdersRead}}, nil)
+	mockRepo.On("Rotate", mock.Anything, int64(8), mock.Anything, mock.Anything, mock.Anything).Return(nil, apikey.ErrAPIKeyNotFound)
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/admin/api-keys/:id/rotate", handler.RotateAPIKey)
+
+	// Perform request
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/api-keys/7/rotate", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert: the new secrets are returned and match the stored ones
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.APIKeySecret
+	if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response)) {
+		assert.Equal(t, apikey.Hash(response.Key), rotatedHash)
+		assert.Equal(t, rotatedSecret, response.SigningSecret)
+	}
+
+	// Perform requests for a revoked key and malformed IDs
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/admin/api-keys/8/rotate", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	for _, id := range []string{"abc", "0", "-7"} {
+		req, _ = http.NewRequest(http.MethodPost, "/api/v1/admin/api-keys/"+id+"/rotate", nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, id)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRevokeAPIKeyHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name         string
+		repoErr      error
+		expectedCode int
+	}{
+		{name: "Live key", repoErr: nil, expectedCode: http.StatusNoContent},
+		{name: "Revoked key", repoErr: apikey.ErrAPIKeyNotFound, expectedCode: http.StatusNotFound},
+		{name: "Database error", repoErr: assert.AnError, expectedCode: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockAPIKeyRepository)
+			mockRepo.On("Revoke", mock.Anything, int64(7)).Return(tc.repoErr)
+			handler := NewAPIKeyHandler(mockRepo)
+
+			// Setup Gin router
+			router := gin.Default()
+			router.DELETE("/api/v1/admin/api-keys/:id", handler.RevokeAPIKey)
+
+			// Perform request
+			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/api-keys/7", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeAPIKeyInvalidID(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockAPIKeyRepository)
+	handler := NewAPIKeyHandler(mockRepo)
+
+	// Setup Gin router
+	router := gin.Default()
+	router.DELETE("/api/v1/admin/api-keys/:id", handler.RevokeAPIKey)
+
+	for _, id := range []string{"abc", "0", "-7"} {
+		// Perform request
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/api-keys/"+id, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert: the repository is never asked
+		assert.Equal(t, http.StatusBadRequest, w.Code, id)
+	}
+	mockRepo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Javlopez/go-api/pkg/logging"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+// errorResponse builds an error body tagged with the ID of the request it answers
+func errorResponse(c *gin.Context, message string) models.ErrorResponse {
+	return models.ErrorResponse{
+		Error:     message,
+		RequestID: logging.RequestID(c.Request.Context()),
+	}
+}
+
+// writeServerError logs the error behind a failed request and answers 500 with message
+func writeServerError(c *gin.Context, message string, err error) {
+	slog.ErrorContext(c.Request.Context(), message, slog.Any("error", err))
+	c.JSON(http.StatusInternalServerError, errorResponse(c, message))
+}
+
+// writeValidationErrors answers 400 with field errors tagged with the ID of the request
+func writeValidationErrors(c *gin.Context, errs models.ValidationErrorResponse) {
+	errs.RequestID = logging.RequestID(c.Request.Context())
+	c.JSON(http.StatusBadRequest, errs)
+}
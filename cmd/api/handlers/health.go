@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds the dependency checks of one readiness probe
+const readinessTimeout = 2 * time.Second
+
+// DatabaseChecker reports on the database the API serves from
+type DatabaseChecker interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (uint, bool, error)
+}
+
+// HealthHandler handles liveness and readiness probes
+type HealthHandler struct {
+	db            DatabaseChecker
+	schemaVersion uint
+	draining      atomic.Bool
+}
+
+// NewHealthHandler creates a new health handler that expects the database
+// at schemaVersion
+func NewHealthHandler(db DatabaseChecker, schemaVersion uint) *HealthHandler {
+	return &HealthHandler{db: db, schemaVersion: schemaVersion}
+}
+
+// Drain marks the API as shutting down, so readiness fails from now on
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Liveness godoc
+// @Summary Liveness probe
+// @Description Report that the process is running and serving requests. It checks no dependencies.
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.HealthResponse
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, models.HealthResponse{Status: models.HealthOK})
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Report whether the API can serve traffic: Postgres answers a ping and its migrations are at the version this build expects. Returns 503 with the failing checks, or with status draining once a shutdown has started.
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.HealthResponse
+// @Failure 503 {object} models.HealthResponse "Not ready"
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, models.HealthResponse{Status: models.HealthDraining})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	checks := map[string]models.CheckResult{
+		"postgres": checkResult(h.db.Ping(ctx)),
+		"schema":   checkResult(h.checkSchema(ctx)),
+	}
+
+	status, code := models.HealthOK, http.StatusOK
+	for _, check := range checks {
+		if check.Status != models.HealthOK {
+			status, code = models.HealthUnavailable, http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(code, models.HealthResponse{Status: status, Checks: checks})
+}
+
+// checkSchema fails unless every migration this build expects has been applied cleanly
+func (h *HealthHandler) checkSchema(ctx context.Context) error {
+	version, dirty, err := h.db.MigrationVersion(ctx)
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to read migration version: %w", err)
+	case dirty:
+		return fmt.Errorf("migration %d failed partway", version)
+	case version != h.schemaVersion:
+		return fmt.Errorf("database is at migration %d, expected %d", version, h.schemaVersion)
+	}
+	return nil
+}
+
+// checkResult reports a dependency check that failed with err, or passed if err is nil
+func checkResult(err error) models.CheckResult {
+	if err != nil {
+		return models.CheckResult{Status: models.HealthUnavailable, Error: err.Error()}
+	}
+	return models.CheckResult{Status: models.HealthOK}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDatabaseChecker is a mock implementation of DatabaseChecker
+type MockDatabaseChecker struct {
+	mock.Mock
+}
+
+func (m *MockDatabaseChecker) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockDatabaseChecker) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(uint), args.Bool(1), args.Error(2)
+}
+
+func TestLivenessHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create handler with a database that is never consulted
+	mockDB := new(MockDatabaseChecker)
+	handler := NewHealthHandler(mockDB, 14)
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/healthz", handler.Liveness)
+
+	// Perform request
+	req, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status": "ok"}`, w.Body.String())
+	mockDB.AssertNotCalled(t, "Ping", mock.Anything)
+}
+
+func TestReadinessHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		pingErr        error
+		version        uint
+		dirty          bool
+		versionErr     error
+		expectedStatus int
+		expectedChecks map[string]string
+	}{
+		{
+			name:           "Ready",
+			version:        14,
+			expectedStatus: http.StatusOK,
+			expectedChecks: map[string]string{"postgres": models.HealthOK, "schema": models.HealthOK},
+		},
+		{
+			name:           "Postgres unreachable",
+			pingErr:        errors.New("connection refused"),
+			versionErr:     errors.New("connection refused"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedChecks: map[string]string{"postgres": models.HealthUnavailable, "schema": models.HealthUnavailable},
+		},
+		{
+			name:           "Migrations behind",
+			version:        13,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedChecks: map[string]string{"postgres": models.HealthOK, "schema": models.HealthUnavailable},
+		},
+		{
+			name:           "Migration failed partway",
+			version:        14,
+			dirty:          true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedChecks: map[string]string{"postgres": models.HealthOK, "schema": models.HealthUnavailable},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create mock database
+			mockDB := new(MockDatabaseChecker)
+			mockDB.On("Ping", mock.Anything).Return(tt.pingErr)
+			mockDB.On("MigrationVersion", mock.Anything).Return(tt.version, tt.dirty, tt.versionErr)
+
+			// Create handler expecting migration 14
+			handler := NewHealthHandler(mockDB, 14)
+
+			// Setup Gin router
+			router := gin.Default()
+			router.GET("/readyz", handler.Readiness)
+
+			// Perform request
+			req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response models.HealthResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			for name, status := range tt.expectedChecks {
+				assert.Equal(t, status, response.Checks[name].Status, name)
+				if status != models.HealthOK {
+					assert.NotEmpty(t, response.Checks[name].Error, name)
+				}
+			}
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReadinessWhileDraining(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create handler for a healthy database, then start shutting down
+	mockDB := new(MockDatabaseChecker)
+	handler := NewHealthHandler(mockDB, 14)
+	handler.Drain()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/readyz", handler.Readiness)
+
+	// Perform request
+	req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"status": "draining"}`, w.Body.String())
+	mockDB.AssertNotCalled(t, "Ping", mock.Anything)
+}
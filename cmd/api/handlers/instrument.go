@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/instrument"
+	"github.com/gin-gonic/gin"
+)
+
+// InstrumentHandler handles instrument master requests
+type InstrumentHandler struct {
+	repo instrument.InstrumentRepository
+}
+
+// NewInstrumentHandler creates a new instrument handler
+func NewInstrumentHandler(repo instrument.InstrumentRepository) *InstrumentHandler {
+	return &InstrumentHandler{repo: repo}
+}
+
+// CreateInstrument godoc
+// @Summary List a new instrument
+// @Description Add a symbol to the instrument master. Orders are only accepted for listed, tradable symbols, at prices on the tick size and in whole lots between min_quantity (default one lot) and max_quantity (0 for no limit).
+// @Tags instruments
+// @Accept json
+// @Produce json
+// @Param instrument body models.InstrumentRequest true "Instrument details"
+// @Success 201 {object} models.Instrument
+// @Failure 400 {object} models.ValidationErrorResponse "Validation failed"
+// @Failure 409 {object} models.ErrorResponse "Symbol already listed"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/instruments [post]
+func (h *InstrumentHandler) CreateInstrument(c *gin.Context) {
+	var request models.InstrumentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		writeValidationErrors(c, newValidationErrorResponse(err))
+		return
+	}
+
+	listing := models.Instrument{Symbol: request.Symbol}
+	request.Apply(&listing)
+	if errs := checkQuantityLimits(&listing); len(errs) > 0 {
+		writeValidationErrors(c, models.ValidationErrorResponse{Errors: errs})
+		return
+	}
+
+	err := h.repo.Create(c.Request.Context(), &listing)
+	if errors.Is(err, instrument.ErrInstrumentExists) {
+		c.JSON(http.StatusConflict, errorResponse(c, "Instrument already exists"))
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to create instrument", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, &listing)
+}
+
+// GetInstruments godoc
+// @Summary Get instruments
+// @Description Retrieve every listed instrument, ordered by symbol
+// @Tags instruments
+// @Produce json
+// @Success 200 {array} models.Instrument
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/instruments [get]
+func (h *InstrumentHandler) GetInstruments(c *gin.Context) {
+	instruments, err := h.repo.GetAll(c.Request.Context())
+	if err != nil {
+		writeServerError(c, "Failed to fetch instruments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, instruments)
+}
+
+// GetInstrument godoc
+// @Summary Get an instrument
+// @Description Retrieve a single instrument by its symbol
+// @Tags instruments
+// @Produce json
+// @Param symbol path string true "Symbol"
+// @Success 200 {object} models.Instrument
+// @Failure 404 {object} models.ErrorResponse "Instrument not found"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/instruments/{symbol} [get]
+func (h *InstrumentHandler) GetInstrument(c *gin.Context) {
+	listing, err := h.repo.GetBySymbol(c.Request.Context(), c.Param("symbol"))
+	if err != nil {
+		writeInstrumentError(c, err, "Failed to fetch instrument")
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// UpdateInstrument godoc
+// @Summary Update an instrument
+// @Description Replace the terms of a listed instrument. Set tradable to false to halt new orders and amendments; live orders stay on the book and can still be cancelled.
+// @Tags instruments
+// @Accept json
+// @Produce json
+// @Param symbol path string true "Symbol"
+// @Param instrument body models.InstrumentSpec true "New instrument terms"
+// @Success 200 {object} models.Instrument
+// @Failure 400 {object} models.ValidationErrorResponse "Validation failed"
+// @Failure 404 {object} models.ErrorResponse "Instrument not found"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/instruments/{symbol} [put]
+func (h *InstrumentHandler) UpdateInstrument(c *gin.Context) {
+	var spec models.InstrumentSpec
+	if err := c.ShouldBindJSON(&spec); err != nil {
+		writeValidationErrors(c, newValidationErrorResponse(err))
+		return
+	}
+
+	listing := models.Instrument{Symbol: c.Param("symbol")}
+	spec.Apply(&listing)
+	if errs := checkQuantityLimits(&listing); len(errs) > 0 {
+		writeValidationErrors(c, models.ValidationErrorResponse{Errors: errs})
+		return
+	}
+
+	if err := h.repo.Update(c.Request.Context(), &listing); err != nil {
+		writeInstrumentError(c, err, "Failed to update instrument")
+		return
+	}
+
+	c.JSON(http.StatusOK, &listing)
+}
+
+// DeleteInstrument godoc
+// @Summary Delete an instrument
+// @Description Remove an instrument from the master. Instruments with orders cannot be deleted; halt them instead.
+// @Tags instruments
+// @Param symbol path string true "Symbol"
+// @Success 204 "Instrument deleted"
+// @Failure 404 {object} models.ErrorResponse "Instrument not found"
+// @Failure 409 {object} models.ErrorResponse "Instrument has orders"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/instruments/{symbol} [delete]
+func (h *InstrumentHandler) DeleteInstrument(c *gin.Context) {
+	if err := h.repo.Delete(c.Request.Context(), c.Param("symbol")); err != nil {
+		writeInstrumentError(c, err, "Failed to delete instrument")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// writeInstrumentError maps instrument repository errors onto HTTP responses
+func writeInstrumentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, instrument.ErrInstrumentNotFound):
+		c.JSON(http.StatusNotFound, errorResponse(c, "Instrument not found"))
+	case errors.Is(err, instrument.ErrInstrumentInUse):
+		c.JSON(http.StatusConflict, errorResponse(c, "Instrument has orders; halt it instead"))
+	default:
+		writeServerError(c, fallback, err)
+	}
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/instrument"
+)
+
+// MockInstrumentRepository is a mock implementation of InstrumentRepository interface
+type MockInstrumentRepository struct {
+	mock.Mock
+}
+
+func (m *MockInstrumentRepository) Create(ctx context.Context, instrument *models.Instrument) error {
+	args := m.Called(ctx, instrument)
+	return args.Error(0)
+}
+
+func (m *MockInstrumentRepository) GetAll(ctx context.Context) ([]models.Instrument, error) {
+	args := m.Called(ctx)
+	instruments, _ := args.Get(0).([]models.Instrument)
+	return instruments, args.Error(1)
+}
+
+func (m *MockInstrumentRepository) GetBySymbol(ctx context.Context, symbol string) (*models.Instrument, error) {
+	args := m.Called(ctx, symbol)
+	listing, _ := args.Get(0).(*models.Instrument)
+	return listing, args.Error(1)
+}
+
+func (m *MockInstrumentRepository) Update(ctx context.Context, instrument *models.Instrument) error {
+	args := m.Called(ctx, instrument)
+	return args.Error(0)
+}
+
+func (m *MockInstrumentRepository) Delete(ctx context.Context, symbol string) error {
+	args := m.Called(ctx, symbol)
+	return args.Error(0)
+}
+
+// newListedInstruments returns an instrument master that lists AAPL and MSFT
+// with the finest tick and single-share lots, so order tests are unconstrained
+func newListedInstruments() *MockInstrumentRepository {
+	instruments := new(MockInstrumentRepository)
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		instruments.On("GetBySymbol", mock.Anything, symbol).Return(&models.Instrument{
+			Symbol:      symbol,
+			Currency:    "USD",
+			TickSize:    models.NewDecimal(1, models.DecimalScale),
+			LotSize:     1,
+			MinQuantity: 1,
+			Tradable:    true,
+		}, nil).Maybe()
+	}
+	return instruments
+}
+
+func TestCreateInstrumentHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockInstrumentRepository)
+
+	// Create handler with mock repo
+	handler := NewInstrumentHandler(mockRepo)
+
+	// Setup expectations: min quantity defaults to one lot and tradable to true
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(listing *models.Instrument) bool {
+		return listing.Symbol == "AAPL" &&
+			listing.TickSize.Equal(models.MustParseDecimal("0.01")) &&
+			listing.LotSize == 100 &&
+			listing.MinQuantity == 100 &&
+			listing.Tradable
+	})).Return(nil)
+
+	// Prepare request
+	body := `{"symbol": "AAPL", "description": "Apple Inc.", "currency": "USD", "tick_size": 0.01, "lot_size": 100}`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/instruments", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/admin/instruments", handler.CreateInstrument)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.Instrument
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "AAPL", response.Symbol)
+	assert.Equal(t, 100, response.MinQuantity)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateInstrumentValidation(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name          string
+		body          string
+		expectedField string
+	}{
+		{"Missing symbol", `{"currency": "USD", "tick_size": 0.01, "lot_size": 1}`, "symbol"},
+		{"Lowercase symbol", `{"symbol": "aapl", "currency": "USD", "tick_size": 0.01, "lot_size": 1}`, "symbol"},
+		{"Invalid currency", `{"symbol": "AAPL", "currency": "DOLLARS", "tick_size": 0.01, "lot_size": 1}`, "currency"},
+		{"Zero tick size", `{"symbol": "AAPL", "currency": "USD", "tick_size": 0, "lot_size": 1}`, "ticksize"},
+		{"Zero lot size", `{"symbol": "AAPL", "currency": "USD", "tick_size": 0.01, "lot_size": 0}`, "lotsize"},
+		{"Max below min", `{"symbol": "AAPL", "currency": "USD", "tick_size": 0.01, "lot_size": 1, "min_quantity": 10, "max_quantity": 5}`, "maxquantity"},
+		{"Max below default min", `{"symbol": "AAPL", "currency": "USD", "tick_size": 0.01, "lot_size": 100, "max_quantity": 50}`, "maxquantity"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockInstrumentRepository)
+
+			// Create handler with mock repo
+			handler := NewInstrumentHandler(mockRepo)
+
+			// Prepare request
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/instruments", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Prepare response recorder
+			w := httptest.NewRecorder()
+
+			// Setup Gin router
+			router := gin.Default()
+			router.POST("/api/v1/admin/instruments", handler.CreateInstrument)
+
+			// Perform request
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ValidationErrorResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			if assert.Len(t, response.Errors, 1) {
+				assert.Equal(t, tc.expectedField, response.Errors[0].Field)
+			}
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateInstrumentDuplicate(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockInstrumentRepository)
+
+	// Create handler with mock repo
+	handler := NewInstrumentHandler(mockRepo)
+
+	// Setup expectations
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(instrument.ErrInstrumentExists)
+
+	// Prepare request
+	body := `{"symbol": "AAPL", "currency": "USD", "tick_size": 0.01, "lot_size": 1}`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/instruments", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/admin/instruments", handler.CreateInstrument)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetInstrumentHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockInstrumentRepository)
+
+	// Create handler with mock repo
+	handler := NewInstrumentHandler(mockRepo)
+
+	// Setup expectations
+	mockRepo.On("GetBySymbol", mock.Anything, "AAPL").Return(&models.Instrument{Symbol: "AAPL", TickSize: models.MustParseDecimal("0.01")}, nil)
+	mockRepo.On("GetBySymbol", mock.Anything, "APPL").Return(nil, instrument.ErrInstrumentNotFound)
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/admin/instruments/:symbol", handler.GetInstrument)
+
+	// Perform requests
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/instruments/AAPL", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"tick_size":0.01`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/instruments/APPL", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateInstrumentHandlerHalts(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockInstrumentRepository)
+
+	// Create handler with mock repo
+	handler := NewInstrumentHandler(mockRepo)
+
+	// Setup expectations: the symbol comes from the path
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(listing *models.Instrument) bool {
+		return listing.Symbol == "AAPL" && !listing.Tradable
+	})).Return(nil)
+
+	// Prepare request
+	body := `{"currency": "USD", "tick_size": 0.01, "lot_size": 1, "tradable": false}`
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/instruments/AAPL", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.PUT("/api/v1/admin/instruments/:symbol", handler.UpdateInstrument)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"tradable":false`)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateInstrumentMaxBelowDefaultMin(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockInstrumentRepository)
+
+	// Create handler with mock repo
+	handler := NewInstrumentHandler(mockRepo)
+
+	// Prepare request: the minimum defaults to one lot of 100, above the maximum
+	body := `{"currency": "USD", "tick_size": 0.01, "lot_size": 100, "max_quantity": 50}`
+	req, _ := http.NewRequest(http.MethodPut, "/api/v1/admin/instruments/AAPL", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.PUT("/api/v1/admin/instruments/:symbol", handler.UpdateInstrument)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"maxquantity"`)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestDeleteInstrumentHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{"Deleted", nil, http.StatusNoContent},
+		{"Not found", instrument.ErrInstrumentNotFound, http.StatusNotFound},
+		{"Has orders", instrument.ErrInstrumentInUse, http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockInstrumentRepository)
+
+			// Create handler with mock repo
+			handler := NewInstrumentHandler(mockRepo)
+
+			// Setup expectations
+			mockRepo.On("Delete", mock.Anything, "AAPL").Return(tc.err)
+
+			// Setup Gin router
+			router := gin.Default()
+			router.DELETE("/api/v1/admin/instruments/:symbol", handler.DeleteInstrument)
+
+			// Perform request
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/instruments/AAPL", nil)
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -2,107 +2,829 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/Javlopez/go-api/cmd/api/middleware"
+	"github.com/Javlopez/go-api/pkg/expiry"
+	"github.com/Javlopez/go-api/pkg/lifecycle"
+	"github.com/Javlopez/go-api/pkg/matching"
+	"github.com/Javlopez/go-api/pkg/metrics"
+	"github.com/Javlopez/go-api/pkg/repositories/instrument"
 	"github.com/Javlopez/go-api/pkg/repositories/order"
 	"net/http"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/Javlopez/go-api/pkg/models"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // OrderHandler handles order-related requests
 type OrderHandler struct {
-	repo order.OrderRepository
+	repo        order.OrderRepository
+	instruments instrument.InstrumentRepository
+	engine      *matching.Engine
+	scales      models.PriceScales
 }
 
-// NewOrderHandler creates a new order handler
-func NewOrderHandler(repo order.OrderRepository) *OrderHandler {
-	return &OrderHandler{repo: repo}
+// NewOrderHandler creates a new order handler. Orders are only accepted for
+// tradable instruments and must follow their tick and lot rules. scales limits
+// the decimal places of each symbol's prices; nil allows the full models.DecimalScale.
+func NewOrderHandler(repo order.OrderRepository, instruments instrument.InstrumentRepository, engine *matching.Engine, scales models.PriceScales) *OrderHandler {
+	return &OrderHandler{repo: repo, instruments: instruments, engine: engine, scales: scales}
 }
 
 // CreateOrder godoc
 // @Summary Create a new trade order
-// @Description Create a new trade order with the provided details
+// @Description Create a new trade order with the provided details. The order is matched against the book immediately; any unfilled quantity of a limit order rests. Market orders never rest: a market order that finds no liquidity is stored as REJECTED, and the unfilled remainder of one that exhausts the book is CANCELLED. time_in_force defaults to GTC (IOC for market orders, which only accept IOC or FOK): IOC cancels any unfilled remainder, FOK is cancelled unless it fills in full on arrival, DAY expires at the next midnight UTC and GTD expires at expires_at. STOP and STOP_LIMIT orders wait, off the book, until the last trade price reaches trigger_price (at or above for a buy, at or below for a sell); they then become MARKET or LIMIT orders and are matched, and the trigger is recorded. The symbol must be a listed, tradable instrument; prices must sit on its tick size and quantities must be whole lots within its limits. An optional client_order_id must be unique; reusing one returns 409 with the existing order. A 409 with an error body means a request with the same Idempotency-Key is in progress. The order belongs to the caller's account.
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the original response"
 // @Param order body models.OrderRequest true "Order details"
 // @Success 201 {object} models.Order
 // @Failure 400 {object} models.ValidationErrorResponse "Validation failed"
+// @Failure 403 {object} models.ErrorResponse "Caller's account is not registered"
+// @Failure 409 {object} models.Order "client_order_id already used; the body is the existing order"
+// @Failure 422 {object} models.ErrorResponse "Trading in the symbol is halted, or the Idempotency-Key was used with a different request"
 // @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /orders [post]
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	defer traceHandler(c, "OrderHandler.CreateOrder")()
+
 	var orderRequest models.OrderRequest
 	if err := c.ShouldBindJSON(&orderRequest); err != nil {
-		var validationErrors models.ValidationErrorResponse
-
-		// Check if this is a validation error
-		var validationErrs validator.ValidationErrors
-		if errors.As(err, &validationErrs) {
-			// Process validation errors
-			for _, e := range validationErrs {
-				field := strings.ToLower(e.Field())
-				var message string
-
-				// Create user-friendly error messages
-				switch e.Tag() {
-				case "required":
-					message = fmt.Sprintf("%s is required", field)
-				case "gt":
-					message = fmt.Sprintf("%s must be greater than %s", field, e.Param())
-				case "oneof":
-					message = fmt.Sprintf("%s must be one of: %s", field, e.Param())
-				default:
-					message = fmt.Sprintf("%s failed validation: %s", field, e.Tag())
-				}
-
-				validationErrors.Errors = append(validationErrors.Errors, models.ValidationError{
-					Field:   field,
-					Message: message,
-				})
-			}
-		}
-
-		c.JSON(http.StatusBadRequest, validationErrors)
+		writeValidationErrors(c, newValidationErrorResponse(err))
 		return
 	}
 
-	orderCreate := models.Order{
-		Symbol:    orderRequest.Symbol,
-		Price:     orderRequest.Price,
-		Quantity:  orderRequest.Quantity,
-		OrderType: orderRequest.OrderType,
+	orderCreate := orderFromRequest(orderRequest)
+	orderCreate.AccountID = accountOf(c)
+
+	listing, ok := h.tradableInstrument(c, orderCreate.Symbol, "Failed to create order")
+	if !ok {
+		return
+	}
+	if errs := h.prepareOrder(&orderCreate, listing); len(errs.Errors) > 0 {
+		writeValidationErrors(c, errs)
+		return
 	}
 
-	if err := h.repo.Create(&orderCreate); err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: "Failed to create order",
+	// The order is matched as it is stored, and the book is put back if the
+	// order or its fills cannot be stored
+	var results []matching.Result
+	err := h.engine.Sequence(func() error {
+		return h.engine.Atomic(func() error {
+			return h.repo.Create(c.Request.Context(), &orderCreate, matchWith(h.engine.Submit, &results))
 		})
+	})
+	if errors.Is(err, order.ErrDuplicateClientOrderID) {
+		h.writeDuplicateClientOrderID(c, orderRequest.ClientOrderID)
 		return
 	}
+	if errors.Is(err, order.ErrAccountNotFound) {
+		writeUnknownAccount(c)
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to create order", err)
+		return
+	}
+
+	metrics.OrdersCreated.WithLabelValues(orderCreate.Symbol, string(orderCreate.OrderType)).Inc()
+	recordFills(results)
+	orderCreate = results[0].Order
 
 	c.JSON(http.StatusCreated, &orderCreate)
 }
 
+// CreateOrderBatch godoc
+// @Summary Submit a batch of trade orders
+// @Description Create up to 500 orders in one request. Each order follows the same rules as POST /orders and is matched in request order. In all_or_nothing mode (the default) the orders are stored in a single transaction: if any order is invalid or reuses a client_order_id, none are created and the response lists what failed. In best_effort mode the valid orders are created and every order gets its own result with its status and, for rejected orders, its validation errors.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Key that makes retries of this request return the original response"
+// @Param mode query string false "Batch mode" Enums(all_or_nothing, best_effort) default(all_or_nothing)
+// @Param orders body []models.OrderRequest true "Orders"
+// @Success 200 {object} models.BatchOrderResponse "Per-order results (best_effort)"
+// @Success 201 {object} models.BatchOrderResponse "Every order created (all_or_nothing)"
+// @Failure 400 {object} models.BatchOrderResponse "Invalid orders (all_or_nothing), or an ErrorResponse for a malformed batch"
+// @Failure 403 {object} models.ErrorResponse "Caller's account is not registered"
+// @Failure 409 {object} models.BatchOrderResponse "A client_order_id is already in use (all_or_nothing)"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/batch [post]
+func (h *OrderHandler) CreateOrderBatch(c *gin.Context) {
+	defer traceHandler(c, "OrderHandler.CreateOrderBatch")()
+
+	mode := models.BatchMode(c.DefaultQuery("mode", string(models.AllOrNothing)))
+	if mode != models.AllOrNothing && mode != models.BestEffort {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "mode must be one of: all_or_nothing best_effort"))
+		return
+	}
+
+	// Decode each order separately so one malformed order is reported on its own
+	var items []json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&items); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "Request body must be a JSON array of orders"))
+		return
+	}
+	if len(items) == 0 || len(items) > models.MaxOrderBatchSize {
+		c.JSON(http.StatusBadRequest, errorResponse(c, fmt.Sprintf("A batch must contain between 1 and %d orders", models.MaxOrderBatchSize)))
+		return
+	}
+
+	results := make([]models.BatchOrderResult, len(items))
+	account := accountOf(c)
+	orders := make([]*models.Order, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	listings := make(map[string]*models.Instrument)
+	rejected := false
+	for i, item := range items {
+		results[i].Index = i
+
+		errs, o, err := h.prepareBatchOrder(c.Request.Context(), listings, item)
+		if err != nil {
+			writeServerError(c, "Failed to create orders", err)
+			return
+		}
+		if len(errs) > 0 {
+			results[i].Status = http.StatusBadRequest
+			results[i].Errors = errs
+			rejected = true
+			continue
+		}
+
+		o.AccountID = account
+		orders = append(orders, o)
+		indexes = append(indexes, i)
+	}
+
+	if mode == models.AllOrNothing && rejected {
+		c.JSON(http.StatusBadRequest, models.BatchOrderResponse{Results: results})
+		return
+	}
+
+	// Every created order is matched as the batch is stored; if any order or
+	// fill cannot be stored, none are and the books are put back
+	var matched []matching.Result
+	var duplicates []int
+	err := h.engine.Sequence(func() error {
+		return h.engine.Atomic(func() error {
+			var err error
+			duplicates, err = h.repo.CreateBatch(c.Request.Context(), orders, mode == models.AllOrNothing, matchWith(h.engine.Submit, &matched))
+			return err
+		})
+	})
+	skipped := make(map[int]bool, len(duplicates))
+	for _, j := range duplicates {
+		skipped[j] = true
+		results[indexes[j]].Status = http.StatusConflict
+		results[indexes[j]].Errors = []models.ValidationError{{
+			Field:   "clientorderid",
+			Message: "client_order_id is already in use",
+		}}
+	}
+	if errors.Is(err, order.ErrDuplicateClientOrderID) {
+		c.JSON(http.StatusConflict, models.BatchOrderResponse{Results: results})
+		return
+	}
+	if errors.Is(err, order.ErrAccountNotFound) {
+		writeUnknownAccount(c)
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to create orders", err)
+		return
+	}
+
+	recordFills(matched)
+	created := make(map[int64]models.Order, len(matched))
+	for _, result := range matched {
+		created[result.Order.ID] = result.Order
+	}
+	for j, o := range orders {
+		if skipped[j] {
+			continue
+		}
+		metrics.OrdersCreated.WithLabelValues(o.Symbol, string(o.OrderType)).Inc()
+
+		stored := created[o.ID]
+		results[indexes[j]].Status = http.StatusCreated
+		results[indexes[j]].Order = &stored
+	}
+
+	status := http.StatusOK
+	if mode == models.AllOrNothing {
+		status = http.StatusCreated
+	}
+	c.JSON(status, models.BatchOrderResponse{Results: results})
+}
+
+// prepareBatchOrder decodes, validates and prepares one order of a batch,
+// returning its field errors if it is rejected. Instruments are looked up
+// once per batch through listings, which also records unlisted symbols.
+func (h *OrderHandler) prepareBatchOrder(ctx context.Context, listings map[string]*models.Instrument, item json.RawMessage) ([]models.ValidationError, *models.Order, error) {
+	var orderRequest models.OrderRequest
+	if err := json.Unmarshal(item, &orderRequest); err != nil {
+		return []models.ValidationError{{
+			Field:   "order",
+			Message: fmt.Sprintf("order could not be decoded: %v", err),
+		}}, nil, nil
+	}
+	if err := binding.Validator.ValidateStruct(&orderRequest); err != nil {
+		return newValidationErrorResponse(err).Errors, nil, nil
+	}
+
+	o := orderFromRequest(orderRequest)
+
+	listing, seen := listings[o.Symbol]
+	if !seen {
+		var err error
+		listing, err = h.instruments.GetBySymbol(ctx, o.Symbol)
+		if err != nil && !errors.Is(err, instrument.ErrInstrumentNotFound) {
+			return nil, nil, err
+		}
+		listings[o.Symbol] = listing
+	}
+	switch {
+	case listing == nil:
+		return []models.ValidationError{{
+			Field:   "symbol",
+			Message: fmt.Sprintf("symbol %s is not a listed instrument", o.Symbol),
+		}}, nil, nil
+	case !listing.Tradable:
+		return []models.ValidationError{{
+			Field:   "symbol",
+			Message: fmt.Sprintf("trading in %s is halted", o.Symbol),
+		}}, nil, nil
+	}
+
+	if errs := h.prepareOrder(&o, listing); len(errs.Errors) > 0 {
+		return errs.Errors, nil, nil
+	}
+	return nil, &o, nil
+}
+
+// orderFromRequest builds a new order from the fields of a request, defaulting its kind to LIMIT
+func orderFromRequest(orderRequest models.OrderRequest) models.Order {
+	o := models.Order{
+		Symbol:       orderRequest.Symbol,
+		Price:        orderRequest.Price,
+		Quantity:     orderRequest.Quantity,
+		OrderType:    orderRequest.OrderType,
+		Kind:         orderRequest.Kind,
+		TimeInForce:  orderRequest.TimeInForce,
+		ExpiresAt:    orderRequest.ExpiresAt,
+		TriggerPrice: orderRequest.TriggerPrice,
+	}
+	if orderRequest.ClientOrderID != "" {
+		o.ClientOrderID = &orderRequest.ClientOrderID
+	}
+	if o.Kind == "" {
+		o.Kind = models.Limit
+	}
+	return o
+}
+
+// writeDuplicateClientOrderID answers a create that reused a client order ID
+// with 409 and the order that already holds it
+func (h *OrderHandler) writeDuplicateClientOrderID(c *gin.Context, clientOrderID string) {
+	existing, err := h.repo.GetByClientOrderID(c.Request.Context(), callerAccount(c), clientOrderID)
+	if err != nil {
+		c.JSON(http.StatusConflict, errorResponse(c, "client_order_id is already in use"))
+		return
+	}
+
+	c.JSON(http.StatusConflict, existing)
+}
+
+// prepareOrder checks the rules that span several request fields or depend on
+// the instrument, defaults the order's time in force and sets when DAY orders
+// expire. Market orders never rest, so they only accept IOC or FOK.
+func (h *OrderHandler) prepareOrder(o *models.Order, listing *models.Instrument) models.ValidationErrorResponse {
+	var errs models.ValidationErrorResponse
+
+	errs.Errors = append(errs.Errors, checkPriceScale(h.scales, o.Symbol, "price", &o.Price)...)
+	errs.Errors = append(errs.Errors, checkPriceScale(h.scales, o.Symbol, "triggerprice", o.TriggerPrice)...)
+	errs.Errors = append(errs.Errors, checkInstrumentRules(listing, o)...)
+
+	if o.TriggerPrice != nil && !o.Kind.IsStop() {
+		errs.Errors = append(errs.Errors, models.ValidationError{
+			Field:   "triggerprice",
+			Message: "triggerprice must not be set unless kind is STOP or STOP_LIMIT",
+		})
+	}
+
+	if o.Kind == models.Market {
+		switch o.TimeInForce {
+		case "":
+			o.TimeInForce = models.ImmediateOrCancel
+		case models.ImmediateOrCancel, models.FillOrKill:
+		default:
+			errs.Errors = append(errs.Errors, models.ValidationError{
+				Field:   "timeinforce",
+				Message: "timeinforce must be one of: IOC FOK for MARKET orders",
+			})
+		}
+		return errs
+	}
+
+	switch o.TimeInForce {
+	case "":
+		o.TimeInForce = models.GoodTillCancel
+	case models.Day:
+		expiresAt := expiry.EndOfDay(time.Now())
+		o.ExpiresAt = &expiresAt
+	}
+	return errs
+}
+
 // GetOrders godoc
-// @Summary Get all trade orders
-// @Description Retrieve a list of all submitted trade orders
+// @Summary Get trade orders
+// @Description Retrieve one page of the caller's account's trade orders, optionally filtered by symbol, order type, status and creation time range [from, to). Pass next_cursor back as cursor to fetch the following page.
 // @Tags orders
 // @Produce json
-// @Success 200 {array} models.Order
+// @Param symbol query string false "Symbol"
+// @Param order_type query string false "Order type" Enums(BUY, SELL)
+// @Param status query []string false "Status; repeat to match any of several" collectionFormat(multi)
+// @Param from query string false "Created at or after (RFC 3339)"
+// @Param to query string false "Created before (RFC 3339)"
+// @Param sort query string false "Sort order" Enums(created_at, -created_at) default(-created_at)
+// @Param limit query int false "Page size (max 500)" default(50)
+// @Param cursor query string false "Cursor from a previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Order]
+// @Failure 400 {object} models.ErrorResponse "Invalid filter or cursor"
 // @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /orders [get]
 func (h *OrderHandler) GetOrders(c *gin.Context) {
-	orders, err := h.repo.GetAll()
+	defer traceHandler(c, "OrderHandler.GetOrders")()
+
+	filter, ok := bindOrderFilter(c)
+	if !ok {
+		return
+	}
+	filter.AccountID = callerAccount(c)
+
+	h.writeOrderPage(c, filter)
+}
+
+// AdminGetOrders godoc
+// @Summary Get trade orders across accounts
+// @Description Retrieve one page of every account's trade orders, including orders placed before accounts existed, optionally narrowed to one account with account_id. The other filters and paging work as for GET /orders.
+// @Tags orders
+// @Produce json
+// @Param account_id query string false "Account ID"
+// @Param symbol query string false "Symbol"
+// @Param order_type query string false "Order type" Enums(BUY, SELL)
+// @Param status query []string false "Status; repeat to match any of several" collectionFormat(multi)
+// @Param from query string false "Created at or after (RFC 3339)"
+// @Param to query string false "Created before (RFC 3339)"
+// @Param sort query string false "Sort order" Enums(created_at, -created_at) default(-created_at)
+// @Param limit query int false "Page size (max 500)" default(50)
+// @Param cursor query string false "Cursor from a previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Order]
+// @Failure 400 {object} models.ErrorResponse "Invalid filter or cursor"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/orders [get]
+func (h *OrderHandler) AdminGetOrders(c *gin.Context) {
+	defer traceHandler(c, "OrderHandler.AdminGetOrders")()
+
+	filter, ok := bindOrderFilter(c)
+	if !ok {
+		return
+	}
+
+	h.writeOrderPage(c, filter)
+}
+
+// bindOrderFilter reads an order listing's filter from the query, writing a
+// 400 response if it is invalid
+func bindOrderFilter(c *gin.Context) (models.OrderFilter, bool) {
+	var filter models.OrderFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "Invalid filter: symbol, order_type, status, from and to (RFC 3339), sort (created_at or -created_at) and limit (1-500) are supported"))
+		return filter, false
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "from must be before to"))
+		return filter, false
+	}
+	return filter, true
+}
+
+// writeOrderPage answers with the page of orders matching filter
+func (h *OrderHandler) writeOrderPage(c *gin.Context, filter models.OrderFilter) {
+	page, err := h.repo.GetAll(c.Request.Context(), filter)
+	if errors.Is(err, order.ErrInvalidCursor) || errors.Is(err, order.ErrInvalidSort) {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "Invalid cursor"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: "Failed to fetch orders",
+		writeServerError(c, "Failed to fetch orders", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetOrder godoc
+// @Summary Get a trade order
+// @Description Retrieve a single trade order of the caller's account by its ID
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} models.ErrorResponse "Invalid order ID"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/{id} [get]
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	defer traceHandler(c, "OrderHandler.GetOrder")()
+
+	h.writeOrder(c, callerAccount(c))
+}
+
+// AdminGetOrder godoc
+// @Summary Get a trade order of any account
+// @Description Retrieve a single trade order by its ID, whichever account it belongs to
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} models.ErrorResponse "Invalid order ID"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/orders/{id} [get]
+func (h *OrderHandler) AdminGetOrder(c *gin.Context) {
+	defer traceHandler(c, "OrderHandler.AdminGetOrder")()
+
+	h.writeOrder(c, "")
+}
+
+// writeOrder answers with the order of account named by the :id path parameter
+func (h *OrderHandler) writeOrder(c *gin.Context, account string) {
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+
+	orderFound, err := h.repo.GetByID(c.Request.Context(), account, id)
+	if errors.Is(err, order.ErrOrderNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "Order not found"))
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to fetch order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderFound)
+}
+
+// GetOrderByClientID godoc
+// @Summary Get a trade order by client order ID
+// @Description Retrieve a single trade order of the caller's account by the client_order_id it was submitted with
+// @Tags orders
+// @Produce json
+// @Param clOrdId path string true "Client order ID"
+// @Success 200 {object} models.Order
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/by-client-id/{clOrdId} [get]
+func (h *OrderHandler) GetOrderByClientID(c *gin.Context) {
+	defer traceHandler(c, "OrderHandler.GetOrderByClientID")()
+
+	orderFound, err := h.repo.GetByClientOrderID(c.Request.Context(), callerAccount(c), c.Param("clOrdId"))
+	if errors.Is(err, order.ErrOrderNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "Order not found"))
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to fetch order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderFound)
+}
+
+// CancelOrder godoc
+// @Summary Cancel a trade order
+// @Description Mark a live order of the caller's account as cancelled. The version query parameter must match the order's current version.
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param version query int true "Expected order version"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} models.ErrorResponse "Invalid order ID or version"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 409 {object} models.ErrorResponse "Stale version or order no longer cancellable"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/{id} [delete]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	defer traceHandler(c, "OrderHandler.CancelOrder")()
+
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Query("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "version query parameter is required"))
+		return
+	}
+
+	var cancelled *models.Order
+	err = h.engine.Sequence(func() error {
+		var err error
+		cancelled, err = h.repo.Cancel(c.Request.Context(), callerAccount(c), id, version)
+		if err != nil {
+			return err
+		}
+
+		h.engine.Cancel(cancelled.Symbol, cancelled.ID)
+		return nil
+	})
+	if err != nil {
+		writeOrderUpdateError(c, err, "Failed to cancel order")
+		return
+	}
+
+	c.JSON(http.StatusOK, cancelled)
+}
+
+// AmendOrder godoc
+// @Summary Amend a trade order
+// @Description Replace the price and/or quantity of a live order of the caller's account (cancel/replace). The order keeps its ID and created_at, and the replaced terms are recorded as a revision. The new terms must follow the instrument's tick and lot rules, and orders in halted instruments cannot be amended.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param amendment body models.AmendOrderRequest true "New order terms"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} models.ValidationErrorResponse "Validation failed"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 409 {object} models.ErrorResponse "Stale version or order no longer live"
+// @Failure 422 {object} models.ErrorResponse "Quantity not above the filled quantity, a price on a stop order, or trading halted"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/{id} [patch]
+func (h *OrderHandler) AmendOrder(c *gin.Context) {
+	defer traceHandler(c, "OrderHandler.AmendOrder")()
+
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+
+	var amendRequest models.AmendOrderRequest
+	if err := c.ShouldBindJSON(&amendRequest); err != nil {
+		writeValidationErrors(c, newValidationErrorResponse(err))
+		return
+	}
+
+	// New terms must respect the scale and instrument rules of the order's symbol
+	if amendRequest.Price != nil || amendRequest.Quantity != nil {
+		current, err := h.repo.GetByID(c.Request.Context(), callerAccount(c), id)
+		if err != nil {
+			writeOrderUpdateError(c, err, "Failed to amend order")
+			return
+		}
+
+		listing, ok := h.tradableInstrument(c, current.Symbol, "Failed to amend order")
+		if !ok {
+			return
+		}
+
+		proposed := *current
+		if amendRequest.Price != nil {
+			proposed.Price = *amendRequest.Price
+		}
+		if amendRequest.Quantity != nil {
+			proposed.Quantity = *amendRequest.Quantity
+		}
+
+		errs := checkPriceScale(h.scales, current.Symbol, "price", amendRequest.Price)
+		errs = append(errs, checkInstrumentRules(listing, &proposed)...)
+		if len(errs) > 0 {
+			writeValidationErrors(c, models.ValidationErrorResponse{Errors: errs})
+			return
+		}
+	}
+
+	// A new price or larger quantity re-enters the book and may trade; the
+	// book is put back if the amendment or its fills cannot be stored
+	var results []matching.Result
+	err := h.engine.Sequence(func() error {
+		return h.engine.Atomic(func() error {
+			_, err := h.repo.Amend(c.Request.Context(), callerAccount(c), id, amendRequest.Version, amendRequest.Price, amendRequest.Quantity, matchWith(h.engine.Amend, &results))
+			return err
 		})
+	})
+	if err != nil {
+		writeOrderUpdateError(c, err, "Failed to amend order")
 		return
 	}
 
-	c.JSON(http.StatusOK, orders)
+	recordFills(results)
+	amended := results[0].Order
+
+	c.JSON(http.StatusOK, &amended)
+}
+
+// GetOrderRevisions godoc
+// @Summary Get the amendment history of a trade order
+// @Description Retrieve every cancel/replace revision of an order, oldest first
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} models.OrderRevision
+// @Failure 400 {object} models.ErrorResponse "Invalid order ID"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/{id}/revisions [get]
+func (h *OrderHandler) GetOrderRevisions(c *gin.Context) {
+	defer traceHandler(c, "OrderHandler.GetOrderRevisions")()
+
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.repo.GetRevisions(c.Request.Context(), callerAccount(c), id)
+	if errors.Is(err, order.ErrOrderNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "Order not found"))
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to fetch order revisions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetOrderTriggers godoc
+// @Summary Get the trigger audit of a stop order
+// @Description Retrieve when and why a STOP or STOP_LIMIT order triggered, including the last trade price that caused it. Untriggered and non-stop orders return an empty list.
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} models.OrderTrigger
+// @Failure 400 {object} models.ErrorResponse "Invalid order ID"
+// @Failure 404 {object} models.ErrorResponse "Order not found"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /orders/{id}/triggers [get]
+func (h *OrderHandler) GetOrderTriggers(c *gin.Context) {
+	defer traceHandler(c, "OrderHandler.GetOrderTriggers")()
+
+	id, ok := parseOrderID(c)
+	if !ok {
+		return
+	}
+
+	triggers, err := h.repo.GetTriggers(c.Request.Context(), callerAccount(c), id)
+	if errors.Is(err, order.ErrOrderNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "Order not found"))
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to fetch order triggers", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, triggers)
+}
+
+// tradableInstrument fetches the instrument for symbol. It writes a 400 response
+// if the symbol is not listed, a 422 response if trading in it is halted and a
+// 500 response with fallback if the lookup fails.
+func (h *OrderHandler) tradableInstrument(c *gin.Context, symbol, fallback string) (*models.Instrument, bool) {
+	listing, err := h.instruments.GetBySymbol(c.Request.Context(), symbol)
+	if errors.Is(err, instrument.ErrInstrumentNotFound) {
+		writeValidationErrors(c, models.ValidationErrorResponse{Errors: []models.ValidationError{{
+			Field:   "symbol",
+			Message: fmt.Sprintf("symbol %s is not a listed instrument", symbol),
+		}}})
+		return nil, false
+	}
+	if err != nil {
+		writeServerError(c, fallback, err)
+		return nil, false
+	}
+
+	if !listing.Tradable {
+		c.JSON(http.StatusUnprocessableEntity, errorResponse(c, fmt.Sprintf("Trading in %s is halted", symbol)))
+		return nil, false
+	}
+	return listing, true
+}
+
+// matchWith returns a MatchFunc that hands each order the repository writes
+// to match, the engine's Submit or Amend, and collects the engine's results
+func matchWith(match func(models.Order) matching.Result, results *[]matching.Result) order.MatchFunc {
+	return func(written models.Order) order.Fills {
+		*results = append(*results, match(written))
+		result := &(*results)[len(*results)-1]
+		return order.Fills{Orders: result.Updated, Trades: result.Trades, Triggers: result.Triggers}
+	}
+}
+
+// recordFills counts the trades of stored results and copies the version
+// stored for each result's order back onto it
+func recordFills(results []matching.Result) {
+	for i := range results {
+		result := &results[i]
+		for _, trade := range result.Trades {
+			metrics.TradesExecuted.WithLabelValues(trade.Symbol).Inc()
+		}
+
+		for _, updated := range result.Updated {
+			if updated.ID == result.Order.ID {
+				result.Order.Version = updated.Version
+				result.Order.UpdatedAt = updated.UpdatedAt
+			}
+		}
+	}
+}
+
+// callerAccount returns the account the caller acts for. The order routes
+// require one; without a principal calls are not scoped to any account.
+func callerAccount(c *gin.Context) string {
+	if principal := middleware.PrincipalFrom(c); principal != nil {
+		return principal.Account
+	}
+	return ""
+}
+
+// accountOf returns the account new orders of the caller belong to, or nil
+func accountOf(c *gin.Context) *string {
+	if account := callerAccount(c); account != "" {
+		return &account
+	}
+	return nil
+}
+
+// writeUnknownAccount answers 403 to a caller acting for an account that is not registered
+func writeUnknownAccount(c *gin.Context) {
+	c.JSON(http.StatusForbidden, errorResponse(c, fmt.Sprintf("Account %s is not registered", callerAccount(c))))
+}
+
+// writeOrderUpdateError maps repository errors from order mutations onto HTTP responses
+func writeOrderUpdateError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, order.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, errorResponse(c, "Order not found"))
+	case errors.Is(err, order.ErrVersionConflict):
+		c.JSON(http.StatusConflict, errorResponse(c, "Order was modified by another request"))
+	case errors.Is(err, lifecycle.ErrInvalidTransition), errors.Is(err, lifecycle.ErrOrderClosed):
+		c.JSON(http.StatusConflict, errorResponse(c, "Order is no longer live"))
+	case errors.Is(err, order.ErrQuantityBelowFilled):
+		c.JSON(http.StatusUnprocessableEntity, errorResponse(c, "Quantity must be greater than the filled quantity"))
+	case errors.Is(err, order.ErrPriceNotAmendable):
+		c.JSON(http.StatusUnprocessableEntity, errorResponse(c, "Stop orders carry no price"))
+	default:
+		writeServerError(c, fallback, err)
+	}
+}
+
+// parseOrderID reads the :id path parameter as an order ID
+func parseOrderID(c *gin.Context) (int64, bool) {
+	return parseID(c, "Invalid order ID")
+}
+
+// parseID reads the :id path parameter, writing a 400 response with message if it is not a positive integer
+func parseID(c *gin.Context, message string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(c, message))
+		return 0, false
+	}
+	return id, true
 }
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,7 +15,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/Javlopez/go-api/cmd/api/middleware"
+	"github.com/Javlopez/go-api/pkg/expiry"
+	"github.com/Javlopez/go-api/pkg/lifecycle"
+	"github.com/Javlopez/go-api/pkg/logging"
+	"github.com/Javlopez/go-api/pkg/matching"
 	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/instrument"
+	"github.com/Javlopez/go-api/pkg/repositories/order"
 )
 
 // MockOrderRepository is a mock implementation of OrderRepository interface
@@ -21,16 +30,120 @@ type MockOrderRepository struct {
 	mock.Mock
 }
 
-func (m *MockOrderRepository) Create(order *models.Order) error {
-	args := m.Called(order)
-	return args.Error(0)
+func (m *MockOrderRepository) Create(ctx context.Context, order *models.Order, match order.MatchFunc) error {
+	args := m.Called(ctx, order)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return m.storeMatch(ctx, match, *order)
 }
 
-func (m *MockOrderRepository) GetAll() ([]models.Order, error) {
-	args := m.Called()
+func (m *MockOrderRepository) CreateBatch(ctx context.Context, orders []*models.Order, atomic bool, match order.MatchFunc) ([]int, error) {
+	args := m.Called(ctx, orders, atomic)
+	duplicates, _ := args.Get(0).([]int)
+	if err := args.Error(1); err != nil {
+		return duplicates, err
+	}
+
+	skipped := make(map[int]bool, len(duplicates))
+	for _, j := range duplicates {
+		skipped[j] = true
+	}
+	for j, o := range orders {
+		if skipped[j] {
+			continue
+		}
+		if err := m.storeMatch(ctx, match, *o); err != nil {
+			return nil, err
+		}
+	}
+	return duplicates, nil
+}
+
+// storeMatch matches a written order and stores its fills through the
+// UpdateFills expectation, standing in for the repository doing so in the
+// order's transaction
+func (m *MockOrderRepository) storeMatch(ctx context.Context, match order.MatchFunc, written models.Order) error {
+	if match == nil {
+		return nil
+	}
+	fills := match(written)
+	if len(fills.Orders) == 0 {
+		return nil
+	}
+	return m.UpdateFills(ctx, fills.Orders, fills.Trades, fills.Triggers)
+}
+
+func (m *MockOrderRepository) GetAll(ctx context.Context, filter models.OrderFilter) (*models.Page[models.Order], error) {
+	args := m.Called(ctx, filter)
+	page, _ := args.Get(0).(*models.Page[models.Order])
+	return page, args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByID(ctx context.Context, account string, id int64) (*models.Order, error) {
+	args := m.Called(ctx, account, id)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByClientOrderID(ctx context.Context, account, clientOrderID string) (*models.Order, error) {
+	args := m.Called(ctx, account, clientOrderID)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrderRepository) GetOpen(ctx context.Context) ([]models.Order, error) {
+	args := m.Called(ctx)
 	return args.Get(0).([]models.Order), args.Error(1)
 }
 
+func (m *MockOrderRepository) Cancel(ctx context.Context, account string, id int64, expectedVersion int) (*models.Order, error) {
+	args := m.Called(ctx, account, id, expectedVersion)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockOrderRepository) Amend(ctx context.Context, account string, id int64, expectedVersion int, price *models.Decimal, quantity *int, match order.MatchFunc) (*models.Order, error) {
+	args := m.Called(ctx, account, id, expectedVersion, price, quantity)
+	amended, ok := args.Get(0).(*models.Order)
+	if !ok || args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	if err := m.storeMatch(ctx, match, *amended); err != nil {
+		return nil, err
+	}
+	return amended, nil
+}
+
+func (m *MockOrderRepository) GetRevisions(ctx context.Context, account string, id int64) ([]models.OrderRevision, error) {
+	args := m.Called(ctx, account, id)
+	return args.Get(0).([]models.OrderRevision), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetTriggers(ctx context.Context, account string, id int64) ([]models.OrderTrigger, error) {
+	args := m.Called(ctx, account, id)
+	triggers, _ := args.Get(0).([]models.OrderTrigger)
+	return triggers, args.Error(1)
+}
+
+// UpdateFills records fills stored by storeMatch, so tests can set expectations on them
+func (m *MockOrderRepository) UpdateFills(ctx context.Context, orders []models.Order, trades []models.Trade, triggers []models.OrderTrigger) error {
+	args := m.Called(ctx, orders, trades, triggers)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) Expire(ctx context.Context, now time.Time) ([]models.Order, error) {
+	args := m.Called(ctx, now)
+	orders, _ := args.Get(0).([]models.Order)
+	return orders, args.Error(1)
+}
+
 func (m *MockOrderRepository) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -44,18 +157,18 @@ func TestCreateOrderHandler(t *testing.T) {
 	mockRepo := new(MockOrderRepository)
 
 	// Create handler with mock repo
-	handler := NewOrderHandler(mockRepo)
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
 
 	// Create test order request
 	orderRequest := models.OrderRequest{
 		Symbol:    "AAPL",
-		Price:     150.5,
+		Price:     models.MustParseDecimal("150.5"),
 		Quantity:  10,
 		OrderType: models.Buy,
 	}
 
 	// Setup expectations
-	mockRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Order")).Return(nil)
 
 	// Prepare request
 	jsonData, _ := json.Marshal(orderRequest)
@@ -77,6 +190,188 @@ func TestCreateOrderHandler(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateOrderMatchesRestingOrder(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with an engine holding a resting sell order
+	engine := matching.NewEngine()
+	engine.Load([]models.Order{
+		{ID: 1, Symbol: "AAPL", Price: models.MustParseDecimal("150"), Quantity: 4, OrderType: models.Sell, Status: models.StatusNew},
+	}, nil)
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), engine, nil)
+
+	// Setup expectations: the new order gets ID 2 and both orders' fills are stored
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Order")).
+		Run(func(args mock.Arguments) {
+			created := args.Get(1).(*models.Order)
+			created.ID = 2
+			created.Status = models.StatusNew
+			created.Version = 1
+		}).
+		Return(nil)
+	mockRepo.On("UpdateFills", mock.Anything, mock.MatchedBy(func(orders []models.Order) bool {
+		return len(orders) == 2 &&
+			orders[0].ID == 2 && orders[0].Status == models.StatusPartiallyFilled && orders[0].FilledQuantity == 4 &&
+			orders[1].ID == 1 && orders[1].Status == models.StatusFilled
+	}), mock.MatchedBy(func(trades []models.Trade) bool {
+		return len(trades) == 1 && trades[0].BuyOrderID == 2 && trades[0].SellOrderID == 1 && trades[0].Quantity == 4
+	}), mock.Anything).
+		Run(func(args mock.Arguments) {
+			orders := args.Get(1).([]models.Order)
+			orders[0].Version = 2
+		}).
+		Return(nil)
+
+	// Prepare request
+	jsonData, _ := json.Marshal(models.OrderRequest{Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 10, OrderType: models.Buy})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders", handler.CreateOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.Order
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusPartiallyFilled, response.Status)
+	assert.Equal(t, 4, response.FilledQuantity)
+	assert.Equal(t, 2, response.Version)
+
+	// The unfilled remainder rests on the book
+	bids, asks := engine.Depth("AAPL")
+	assert.Empty(t, asks)
+	assert.Len(t, bids, 1)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateOrderFillsNotStored(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with an engine holding a resting sell order
+	engine := matching.NewEngine()
+	engine.Load([]models.Order{
+		{ID: 1, Symbol: "AAPL", Price: models.MustParseDecimal("150"), Quantity: 4, OrderType: models.Sell, Status: models.StatusNew},
+	}, nil)
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), engine, nil)
+
+	// Setup expectations: the new order trades, but its fills cannot be
+	// stored, so the repository rolls the order back with them
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Order")).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Order).ID = 2
+		}).
+		Return(nil)
+	mockRepo.On("UpdateFills", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("connection reset"))
+
+	// Prepare request
+	jsonData, _ := json.Marshal(models.OrderRequest{Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 10, OrderType: models.Buy})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders", handler.CreateOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// Like storage, the book is as it was: the sell order is unfilled and the
+	// new order does not rest
+	bids, asks := engine.Depth("AAPL")
+	assert.Empty(t, bids)
+	if assert.Len(t, asks, 1) {
+		assert.Equal(t, int64(1), asks[0].ID)
+		assert.Equal(t, 0, asks[0].FilledQuantity)
+		assert.Equal(t, models.StatusNew, asks[0].Status)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateOrderBatchFillsNotStored(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with an engine holding a resting sell order
+	engine := matching.NewEngine()
+	engine.Load([]models.Order{
+		{ID: 1, Symbol: "AAPL", Price: models.MustParseDecimal("150"), Quantity: 4, OrderType: models.Sell, Status: models.StatusNew},
+	}, nil)
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), engine, nil)
+
+	// Setup expectations: the first order rests in MSFT, the second trades in
+	// AAPL but its fills cannot be stored, so the whole batch is rolled back
+	mockRepo.On("CreateBatch", mock.Anything, mock.Anything, false).
+		Run(func(args mock.Arguments) {
+			for i, created := range args.Get(1).([]*models.Order) {
+				created.ID = int64(i + 2)
+			}
+		}).
+		Return(nil, nil)
+	mockRepo.On("UpdateFills", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("connection reset"))
+
+	// Prepare request
+	body := `[
+		{"symbol": "MSFT", "price": 250, "quantity": 5, "order_type": "BUY"},
+		{"symbol": "AAPL", "price": 150, "quantity": 4, "order_type": "BUY"}
+	]`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/batch?mode=best_effort", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders/batch", handler.CreateOrderBatch)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// Neither order is left on a book, and the sell order is unfilled
+	bids, asks := engine.Depth("MSFT")
+	assert.Empty(t, bids)
+	assert.Empty(t, asks)
+	bids, asks = engine.Depth("AAPL")
+	assert.Empty(t, bids)
+	if assert.Len(t, asks, 1) {
+		assert.Equal(t, 0, asks[0].FilledQuantity)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestCreateOrderInvalidJSON(t *testing.T) {
 	// Set Gin to test mode
 	gin.SetMode(gin.TestMode)
@@ -85,7 +380,7 @@ func TestCreateOrderInvalidJSON(t *testing.T) {
 	mockRepo := new(MockOrderRepository)
 
 	// Create handler with mock repo
-	handler := NewOrderHandler(mockRepo)
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
 
 	// Prepare invalid JSON request
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer([]byte("invalid json")))
@@ -113,12 +408,12 @@ func TestCreateOrderValidationFailed(t *testing.T) {
 	mockRepo := new(MockOrderRepository)
 
 	// Create handler with mock repo
-	handler := NewOrderHandler(mockRepo)
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
 
 	// Create invalid order request (missing required fields)
 	orderRequest := models.OrderRequest{
 		// Symbol is required but missing
-		Price:     150.5,
+		Price:     models.MustParseDecimal("150.5"),
 		Quantity:  10,
 		OrderType: models.Buy,
 	}
@@ -148,7 +443,113 @@ func TestCreateOrderValidationFailed(t *testing.T) {
 	assert.Greater(t, len(response.Errors), 0)
 }
 
-func TestCreateOrderDatabaseError(t *testing.T) {
+func TestCreateOrderKindValidation(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name          string
+		body          string
+		expectedField string
+	}{
+		{"Limit order without price", `{"symbol": "AAPL", "quantity": 10, "order_type": "BUY", "kind": "LIMIT"}`, "price"},
+		{"Default kind without price", `{"symbol": "AAPL", "quantity": 10, "order_type": "BUY"}`, "price"},
+		{"Market order with price", `{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY", "kind": "MARKET"}`, "price"},
+		{"Unknown kind", `{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY", "kind": "TRAILING_STOP"}`, "kind"},
+		{"Stop order without trigger", `{"symbol": "AAPL", "quantity": 10, "order_type": "SELL", "kind": "STOP"}`, "triggerprice"},
+		{"Stop order with price", `{"symbol": "AAPL", "price": 150.5, "trigger_price": 145, "quantity": 10, "order_type": "SELL", "kind": "STOP"}`, "price"},
+		{"Stop-limit order without price", `{"symbol": "AAPL", "trigger_price": 145, "quantity": 10, "order_type": "SELL", "kind": "STOP_LIMIT"}`, "price"},
+		{"Limit order with trigger", `{"symbol": "AAPL", "price": 150.5, "trigger_price": 145, "quantity": 10, "order_type": "SELL"}`, "triggerprice"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockOrderRepository)
+
+			// Create handler with mock repo
+			handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+			// Prepare request
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Prepare response recorder
+			w := httptest.NewRecorder()
+
+			// Setup Gin router
+			router := gin.Default()
+			router.POST("/api/v1/orders", handler.CreateOrder)
+
+			// Perform request
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ValidationErrorResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Len(t, response.Errors, 1)
+			assert.Equal(t, tc.expectedField, response.Errors[0].Field)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateOrderPriceScaleValidation(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name          string
+		body          string
+		expectedField string
+	}{
+		{"Price finer than symbol scale", `{"symbol": "AAPL", "price": 150.505, "quantity": 10, "order_type": "BUY"}`, "price"},
+		{"Trigger finer than symbol scale", `{"symbol": "AAPL", "trigger_price": 145.001, "quantity": 10, "order_type": "SELL", "kind": "STOP"}`, "triggerprice"},
+		{"Price finer than storage scale", `{"symbol": "EURUSD", "price": 1.08505, "quantity": 10, "order_type": "BUY"}`, ""},
+		{"Price not a number", `{"symbol": "AAPL", "price": "abc", "quantity": 10, "order_type": "BUY"}`, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockOrderRepository)
+
+			// Create handler with a two-decimal scale for AAPL
+			handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), models.PriceScales{"AAPL": 2})
+
+			// Prepare request
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Prepare response recorder
+			w := httptest.NewRecorder()
+
+			// Setup Gin router
+			router := gin.Default()
+			router.POST("/api/v1/orders", handler.CreateOrder)
+
+			// Perform request
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			if tc.expectedField != "" {
+				var response models.ValidationErrorResponse
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				if assert.Len(t, response.Errors, 1) {
+					assert.Equal(t, tc.expectedField, response.Errors[0].Field)
+				}
+			}
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateOrderKeepsExactPrice(t *testing.T) {
 	// Set Gin to test mode
 	gin.SetMode(gin.TestMode)
 
@@ -156,22 +557,19 @@ func TestCreateOrderDatabaseError(t *testing.T) {
 	mockRepo := new(MockOrderRepository)
 
 	// Create handler with mock repo
-	handler := NewOrderHandler(mockRepo)
-
-	// Create test order request
-	orderRequest := models.OrderRequest{
-		Symbol:    "AAPL",
-		Price:     150.5,
-		Quantity:  10,
-		OrderType: models.Buy,
-	}
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), models.PriceScales{"AAPL": 2})
 
-	// Setup expectations with an error
-	mockRepo.On("Create", mock.AnythingOfType("*models.Order")).Return(errors.New("database error"))
+	// Setup expectations: the price reaches the repository unrounded
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.Price.Equal(models.MustParseDecimal("0.3"))
+	})).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Order).ID = 1
+		}).
+		Return(nil)
 
 	// Prepare request
-	jsonData, _ := json.Marshal(orderRequest)
-	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(jsonData))
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(`{"symbol": "AAPL", "price": 0.30, "quantity": 10, "order_type": "BUY"}`))
 	req.Header.Set("Content-Type", "application/json")
 
 	// Prepare response recorder
@@ -185,58 +583,228 @@ func TestCreateOrderDatabaseError(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	// Assert
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"price":0.3,`)
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetOrdersHandler(t *testing.T) {
+func TestCreateOrderInstrumentRules(t *testing.T) {
 	// Set Gin to test mode
 	gin.SetMode(gin.TestMode)
 
-	// Create mock repository
+	testCases := []struct {
+		name          string
+		body          string
+		expectedCode  int
+		expectedField string
+	}{
+		{"Unknown symbol", `{"symbol": "APPL", "price": 150.5, "quantity": 200, "order_type": "BUY"}`, http.StatusBadRequest, "symbol"},
+		{"Halted symbol", `{"symbol": "HALT", "price": 150.5, "quantity": 100, "order_type": "BUY"}`, http.StatusUnprocessableEntity, ""},
+		{"Price off tick", `{"symbol": "AAPL", "price": 150.52, "quantity": 200, "order_type": "BUY"}`, http.StatusBadRequest, "price"},
+		{"Trigger off tick", `{"symbol": "AAPL", "trigger_price": 145.01, "quantity": 200, "order_type": "SELL", "kind": "STOP"}`, http.StatusBadRequest, "triggerprice"},
+		{"Quantity off lot", `{"symbol": "AAPL", "price": 150.5, "quantity": 250, "order_type": "BUY"}`, http.StatusBadRequest, "quantity"},
+		{"Quantity below minimum", `{"symbol": "AAPL", "kind": "MARKET", "quantity": 100, "order_type": "BUY"}`, http.StatusBadRequest, "quantity"},
+		{"Quantity above maximum", `{"symbol": "AAPL", "price": 150.5, "quantity": 10100, "order_type": "BUY"}`, http.StatusBadRequest, "quantity"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repositories: AAPL trades on a 0.05 tick in lots of 100, up to 10000
+			mockRepo := new(MockOrderRepository)
+			instruments := new(MockInstrumentRepository)
+			instruments.On("GetBySymbol", mock.Anything, "AAPL").Return(&models.Instrument{
+				Symbol:      "AAPL",
+				TickSize:    models.MustParseDecimal("0.05"),
+				LotSize:     100,
+				MinQuantity: 200,
+				MaxQuantity: 10000,
+				Tradable:    true,
+			}, nil).Maybe()
+			instruments.On("GetBySymbol", mock.Anything, "HALT").Return(&models.Instrument{
+				Symbol:   "HALT",
+				TickSize: models.MustParseDecimal("0.01"),
+				LotSize:  1,
+			}, nil).Maybe()
+			instruments.On("GetBySymbol", mock.Anything, "APPL").Return(nil, instrument.ErrInstrumentNotFound).Maybe()
+
+			// Create handler with mock repos
+			handler := NewOrderHandler(mockRepo, instruments, matching.NewEngine(), nil)
+
+			// Prepare request
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Prepare response recorder
+			w := httptest.NewRecorder()
+
+			// Setup Gin router
+			router := gin.Default()
+			router.POST("/api/v1/orders", handler.CreateOrder)
+
+			// Perform request
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedField != "" {
+				var response models.ValidationErrorResponse
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+				if assert.Len(t, response.Errors, 1) {
+					assert.Equal(t, tc.expectedField, response.Errors[0].Field)
+				}
+			}
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAmendOrderInstrumentRules(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repositories: AAPL trades in lots of 100
 	mockRepo := new(MockOrderRepository)
+	instruments := new(MockInstrumentRepository)
+	instruments.On("GetBySymbol", mock.Anything, "AAPL").Return(&models.Instrument{
+		Symbol:      "AAPL",
+		TickSize:    models.MustParseDecimal("0.01"),
+		LotSize:     100,
+		MinQuantity: 100,
+		Tradable:    true,
+	}, nil)
+
+	// Create handler with mock repos
+	handler := NewOrderHandler(mockRepo, instruments, matching.NewEngine(), nil)
+
+	// Setup expectations: the order is looked up, then rejected before amending
+	current := &models.Order{ID: 42, Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 100, Kind: models.Limit, Status: models.StatusNew, Version: 1}
+	mockRepo.On("GetByID", mock.Anything, "", int64(42)).Return(current, nil)
 
-	// Create handler with mock repo
-	handler := NewOrderHandler(mockRepo)
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/42", bytes.NewBufferString(`{"version": 1, "quantity": 250}`))
+	req.Header.Set("Content-Type", "application/json")
 
-	// Create test orders
-	now := time.Now()
-	orders := []models.Order{
-		{ID: 1, Symbol: "AAPL", Price: 150.5, Quantity: 10, OrderType: models.Buy, CreatedAt: now},
-		{ID: 2, Symbol: "MSFT", Price: 250.75, Quantity: 5, OrderType: models.Sell, CreatedAt: now},
-	}
+	// Prepare response recorder
+	w := httptest.NewRecorder()
 
-	// Setup expectations
-	mockRepo.On("GetAll").Return(orders, nil)
+	// Setup Gin router
+	router := gin.Default()
+	router.PATCH("/api/v1/orders/:id", handler.AmendOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "quantity must be a multiple of the lot size 100 for AAPL")
+	mockRepo.AssertNotCalled(t, "Amend", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateMarketOrderRejectedOnEmptyBook(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo and an empty book
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: the order is stored, then its rejection is persisted
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.Kind == models.Market && order.Price.IsZero()
+	})).
+		Run(func(args mock.Arguments) {
+			created := args.Get(1).(*models.Order)
+			created.ID = 1
+			created.Status = models.StatusNew
+		}).
+		Return(nil)
+	mockRepo.On("UpdateFills", mock.Anything, mock.MatchedBy(func(orders []models.Order) bool {
+		return len(orders) == 1 && orders[0].Status == models.StatusRejected
+	}), mock.Anything, mock.Anything).Return(nil)
 
 	// Prepare request
-	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(`{"symbol": "AAPL", "quantity": 10, "order_type": "BUY", "kind": "MARKET"}`))
+	req.Header.Set("Content-Type", "application/json")
 
 	// Prepare response recorder
 	w := httptest.NewRecorder()
 
 	// Setup Gin router
 	router := gin.Default()
-	router.GET("/api/v1/orders", handler.GetOrders)
+	router.POST("/api/v1/orders", handler.CreateOrder)
 
 	// Perform request
 	router.ServeHTTP(w, req)
 
 	// Assert
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusCreated, w.Code)
 
-	// Check response body contains orders
-	var response []models.Order
+	var response models.Order
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Len(t, response, 2)
-	assert.Equal(t, "AAPL", response[0].Symbol)
-	assert.Equal(t, "MSFT", response[1].Symbol)
+	assert.Equal(t, models.StatusRejected, response.Status)
 
 	mockRepo.AssertExpectations(t)
 }
 
-func TestGetOrdersDatabaseError(t *testing.T) {
+func TestCreateOrderTimeInForceValidation(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	testCases := []struct {
+		name          string
+		body          string
+		expectedField string
+	}{
+		{"Unknown time in force", `{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY", "time_in_force": "GTX"}`, "timeinforce"},
+		{"GTD without expiry", `{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY", "time_in_force": "GTD"}`, "expiresat"},
+		{"GTD expiring in the past", `{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY", "time_in_force": "GTD", "expires_at": "` + expired + `"}`, "expiresat"},
+		{"Expiry without GTD", `{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY", "time_in_force": "DAY", "expires_at": "` + expiresAt + `"}`, "expiresat"},
+		{"Market order resting", `{"symbol": "AAPL", "quantity": 10, "order_type": "BUY", "kind": "MARKET", "time_in_force": "GTC"}`, "timeinforce"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockOrderRepository)
+
+			// Create handler with mock repo
+			handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+			// Prepare request
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Prepare response recorder
+			w := httptest.NewRecorder()
+
+			// Setup Gin router
+			router := gin.Default()
+			router.POST("/api/v1/orders", handler.CreateOrder)
+
+			// Perform request
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ValidationErrorResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Len(t, response.Errors, 1)
+			assert.Equal(t, tc.expectedField, response.Errors[0].Field)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateDayOrderSetsExpiry(t *testing.T) {
 	// Set Gin to test mode
 	gin.SetMode(gin.TestMode)
 
@@ -244,25 +812,1305 @@ func TestGetOrdersDatabaseError(t *testing.T) {
 	mockRepo := new(MockOrderRepository)
 
 	// Create handler with mock repo
-	handler := NewOrderHandler(mockRepo)
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: DAY orders are stored expiring at the next midnight UTC
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.TimeInForce == models.Day &&
+			order.ExpiresAt != nil && order.ExpiresAt.Equal(expiry.EndOfDay(time.Now()))
+	})).
+		Run(func(args mock.Arguments) {
+			created := args.Get(1).(*models.Order)
+			created.ID = 1
+			created.Status = models.StatusNew
+		}).
+		Return(nil)
 
-	// Setup expectations with an error
-	mockRepo.On("GetAll").Return([]models.Order{}, errors.New("database error"))
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(`{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY", "time_in_force": "DAY"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders", handler.CreateOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.Order
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Day, response.TimeInForce)
+	assert.NotNil(t, response.ExpiresAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateIOCOrderCancelledWithoutLiquidity(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo and an empty book
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: the order is stored, then its cancellation is persisted
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.TimeInForce == models.ImmediateOrCancel && order.ExpiresAt == nil
+	})).
+		Run(func(args mock.Arguments) {
+			created := args.Get(1).(*models.Order)
+			created.ID = 1
+			created.Status = models.StatusNew
+		}).
+		Return(nil)
+	mockRepo.On("UpdateFills", mock.Anything, mock.MatchedBy(func(orders []models.Order) bool {
+		return len(orders) == 1 && orders[0].Status == models.StatusCancelled
+	}), mock.Anything, mock.Anything).Return(nil)
 
 	// Prepare request
-	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(`{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY", "time_in_force": "IOC"}`))
+	req.Header.Set("Content-Type", "application/json")
 
 	// Prepare response recorder
 	w := httptest.NewRecorder()
 
 	// Setup Gin router
 	router := gin.Default()
-	router.GET("/api/v1/orders", handler.GetOrders)
+	router.POST("/api/v1/orders", handler.CreateOrder)
 
 	// Perform request
 	router.ServeHTTP(w, req)
 
 	// Assert
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.Order
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, response.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateStopOrderWaitsForTrigger(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo and an empty book
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: the stop is stored and nothing else is persisted
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.Kind == models.Stop && order.TriggerPrice != nil && order.TriggerPrice.Equal(models.NewDecimal(145, 0))
+	})).
+		Run(func(args mock.Arguments) {
+			created := args.Get(1).(*models.Order)
+			created.ID = 1
+			created.Status = models.StatusNew
+		}).
+		Return(nil)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(`{"symbol": "AAPL", "trigger_price": 145, "quantity": 10, "order_type": "SELL", "kind": "STOP"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders", handler.CreateOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.Order
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Stop, response.Kind)
+	assert.Equal(t, models.StatusNew, response.Status)
+	assert.Nil(t, response.TriggeredAt)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpdateFills", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateOrderDatabaseError(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Create test order request
+	orderRequest := models.OrderRequest{
+		Symbol:    "AAPL",
+		Price:     models.MustParseDecimal("150.5"),
+		Quantity:  10,
+		OrderType: models.Buy,
+	}
+
+	// Setup expectations with an error
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Order")).Return(errors.New("database error"))
+
+	// Prepare request
+	jsonData, _ := json.Marshal(orderRequest)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders", handler.CreateOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrdersHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Create test orders
+	now := time.Now()
+	orders := []models.Order{
+		{ID: 1, Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 10, OrderType: models.Buy, CreatedAt: now},
+		{ID: 2, Symbol: "MSFT", Price: models.MustParseDecimal("250.75"), Quantity: 5, OrderType: models.Sell, CreatedAt: now},
+	}
+
+	// Setup expectations: the query string is bound onto the filter
+	filter := models.OrderFilter{
+		Symbol: "AAPL",
+		Status: []models.OrderStatus{models.StatusNew, models.StatusPartiallyFilled},
+		Sort:   "created_at",
+		Limit:  2,
+		Cursor: "abc",
+	}
+	mockRepo.On("GetAll", mock.Anything, filter).Return(&models.Page[models.Order]{Data: orders, NextCursor: "next"}, nil)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders?symbol=AAPL&status=NEW&status=PARTIALLY_FILLED&sort=created_at&limit=2&cursor=abc", nil)
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/orders", handler.GetOrders)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Check response body contains the page of orders
+	var response models.Page[models.Order]
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Data, 2)
+	assert.Equal(t, "AAPL", response.Data[0].Symbol)
+	assert.Equal(t, "MSFT", response.Data[1].Symbol)
+	assert.Equal(t, "next", response.NextCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrdersDatabaseError(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations with an error
+	mockRepo.On("GetAll", mock.Anything, models.OrderFilter{}).Return(nil, errors.New("database error"))
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/orders", handler.GetOrders)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrdersDatabaseErrorCarriesRequestID(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations with an error the caller must not see
+	mockRepo.On("GetAll", mock.Anything, models.OrderFilter{}).Return(nil, errors.New("pq: connection refused"))
+
+	// Prepare request tagged with a request ID
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+	req = req.WithContext(logging.WithRequestID(req.Context(), "req-500"))
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/orders", handler.GetOrders)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert: the body quotes the request ID but not the database error
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var response models.ErrorResponse
+	if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response)) {
+		assert.Equal(t, "req-500", response.RequestID)
+		assert.NotContains(t, response.Error, "connection refused")
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrdersInvalidFilter(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name  string
+		query string
+	}{
+		{"Unknown status", "status=OPEN"},
+		{"Unknown order type", "order_type=SHORT"},
+		{"Sort not whitelisted", "sort=price"},
+		{"Limit too large", "limit=501"},
+		{"Bad time", "from=yesterday"},
+		{"Empty range", "from=2025-01-03T00:00:00Z&to=2025-01-02T00:00:00Z"},
+		{"Invalid cursor", "cursor=not-a-cursor"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockOrderRepository)
+			mockRepo.On("GetAll", mock.Anything, mock.Anything).Return(nil, order.ErrInvalidCursor)
+
+			// Create handler with mock repo
+			handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+			// Prepare request
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders?"+tc.query, nil)
+
+			// Prepare response recorder
+			w := httptest.NewRecorder()
+
+			// Setup Gin router
+			router := gin.Default()
+			router.GET("/api/v1/orders", handler.GetOrders)
+
+			// Perform request
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestGetOrderHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations
+	found := &models.Order{ID: 42, Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 10, OrderType: models.Buy, Status: models.StatusNew}
+	mockRepo.On("GetByID", mock.Anything, "", int64(42)).Return(found, nil)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/42", nil)
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/orders/:id", handler.GetOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Order
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), response.ID)
+	assert.Equal(t, "AAPL", response.Symbol)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateOrderDuplicateClientOrderID(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: the client order ID is taken, so the holder is returned
+	clientOrderID := "oms-1"
+	existing := &models.Order{ID: 7, ClientOrderID: &clientOrderID, Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 10, OrderType: models.Buy, Status: models.StatusNew}
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.ClientOrderID != nil && *order.ClientOrderID == clientOrderID
+	})).Return(order.ErrDuplicateClientOrderID)
+	mockRepo.On("GetByClientOrderID", mock.Anything, "", clientOrderID).Return(existing, nil)
+
+	// Prepare request
+	body := `{"client_order_id": "oms-1", "symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"}`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders", handler.CreateOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response models.Order
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), response.ID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateOrderClientOrderIDValidation(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Prepare request with a client order ID longer than 64 characters
+	body := `{"client_order_id": "` + strings.Repeat("x", 65) + `", "symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"}`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders", handler.CreateOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response models.ValidationErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, "clientorderid", response.Errors[0].Field)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateOrderBatchHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: the batch is stored in one transaction and given IDs
+	mockRepo.On("CreateBatch", mock.Anything, mock.AnythingOfType("[]*models.Order"), true).Run(func(args mock.Arguments) {
+		for i, o := range args.Get(1).([]*models.Order) {
+			o.ID = int64(i + 1)
+		}
+	}).Return(nil, nil)
+
+	// Prepare request
+	body := `[
+		{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"},
+		{"symbol": "MSFT", "price": 310, "quantity": 5, "order_type": "SELL"}
+	]`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders/batch", handler.CreateOrderBatch)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.BatchOrderResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Results, 2) {
+		for i, result := range response.Results {
+			assert.Equal(t, i, result.Index)
+			assert.Equal(t, http.StatusCreated, result.Status)
+			if assert.NotNil(t, result.Order) {
+				assert.Equal(t, int64(i+1), result.Order.ID)
+			}
+		}
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateOrderBatchAllOrNothingRejectsInvalid(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create instrument master that does not list TSLA
+	instruments := newListedInstruments()
+	instruments.On("GetBySymbol", mock.Anything, "TSLA").Return(nil, instrument.ErrInstrumentNotFound)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, instruments, matching.NewEngine(), nil)
+
+	// Prepare request with an unlisted symbol and a missing quantity
+	body := `[
+		{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"},
+		{"symbol": "TSLA", "price": 200, "quantity": 10, "order_type": "BUY"},
+		{"symbol": "AAPL", "price": 150.5, "order_type": "BUY"}
+	]`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders/batch", handler.CreateOrderBatch)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert: nothing is stored and every order reports its outcome
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response models.BatchOrderResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Results, 3) {
+		assert.Equal(t, 0, response.Results[0].Status)
+		assert.Equal(t, http.StatusBadRequest, response.Results[1].Status)
+		assert.Equal(t, "symbol", response.Results[1].Errors[0].Field)
+		assert.Equal(t, http.StatusBadRequest, response.Results[2].Status)
+		assert.Equal(t, "quantity", response.Results[2].Errors[0].Field)
+	}
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateOrderBatchBestEffort(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: of the two valid orders, the second reuses a client order ID
+	mockRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(orders []*models.Order) bool {
+		return len(orders) == 2
+	}), false).Run(func(args mock.Arguments) {
+		args.Get(1).([]*models.Order)[0].ID = 1
+	}).Return([]int{1}, nil)
+
+	// Prepare request
+	body := `[
+		{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"},
+		{"symbol": "AAPL", "price": "abc", "quantity": 10, "order_type": "BUY"},
+		{"client_order_id": "oms-1", "symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"}
+	]`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/batch?mode=best_effort", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders/batch", handler.CreateOrderBatch)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.BatchOrderResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Results, 3) {
+		assert.Equal(t, http.StatusCreated, response.Results[0].Status)
+		assert.Equal(t, http.StatusBadRequest, response.Results[1].Status)
+		assert.Equal(t, "order", response.Results[1].Errors[0].Field)
+		assert.Equal(t, http.StatusConflict, response.Results[2].Status)
+		assert.Equal(t, "clientorderid", response.Results[2].Errors[0].Field)
+		assert.Nil(t, response.Results[2].Order)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateOrderBatchAllOrNothingDuplicate(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: the repository rolls the batch back on a duplicate
+	mockRepo.On("CreateBatch", mock.Anything, mock.AnythingOfType("[]*models.Order"), true).Return([]int{0}, order.ErrDuplicateClientOrderID)
+
+	// Prepare request
+	body := `[
+		{"client_order_id": "oms-1", "symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"},
+		{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"}
+	]`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.POST("/api/v1/orders/batch", handler.CreateOrderBatch)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response models.BatchOrderResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Results, 2) {
+		assert.Equal(t, http.StatusConflict, response.Results[0].Status)
+		assert.Equal(t, 0, response.Results[1].Status)
+		assert.Nil(t, response.Results[1].Order)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateOrderBatchInvalidRequest(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		url  string
+		body string
+	}{
+		{name: "Not an array", url: "/api/v1/orders/batch", body: `{"symbol": "AAPL"}`},
+		{name: "Empty batch", url: "/api/v1/orders/batch", body: `[]`},
+		{name: "Too many orders", url: "/api/v1/orders/batch", body: "[" + strings.TrimSuffix(strings.Repeat(`{"symbol": "AAPL"},`, models.MaxOrderBatchSize+1), ",") + "]"},
+		{name: "Unknown mode", url: "/api/v1/orders/batch?mode=some", body: `[{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockOrderRepository)
+
+			// Create handler with mock repo
+			handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+			// Prepare request
+			req, _ := http.NewRequest(http.MethodPost, tt.url, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Prepare response recorder
+			w := httptest.NewRecorder()
+
+			// Setup Gin router
+			router := gin.Default()
+			router.POST("/api/v1/orders/batch", handler.CreateOrderBatch)
+
+			// Perform request
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetOrderByClientIDHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations
+	clientOrderID := "oms-1"
+	found := &models.Order{ID: 42, ClientOrderID: &clientOrderID, Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 10, OrderType: models.Buy, Status: models.StatusNew}
+	mockRepo.On("GetByClientOrderID", mock.Anything, "", "oms-1").Return(found, nil)
+	mockRepo.On("GetByClientOrderID", mock.Anything, "", "oms-2").Return(nil, order.ErrOrderNotFound)
+
+	// Setup Gin router alongside the ID route it shares a prefix with
+	router := gin.Default()
+	router.GET("/api/v1/orders/:id", handler.GetOrder)
+	router.GET("/api/v1/orders/by-client-id/:clOrdId", handler.GetOrderByClientID)
+
+	// Perform requests
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/by-client-id/oms-1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Order
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), response.ID)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/orders/by-client-id/oms-2", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrderNotFound(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations
+	mockRepo.On("GetByID", mock.Anything, "", int64(42)).Return(nil, order.ErrOrderNotFound)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/42", nil)
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/orders/:id", handler.GetOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response models.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Order not found", response.Error)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrderInvalidID(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/abc", nil)
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/orders/:id", handler.GetOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCancelOrderHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations
+	cancelled := &models.Order{ID: 42, Symbol: "AAPL", Status: models.StatusCancelled, Version: 2}
+	mockRepo.On("Cancel", mock.Anything, "", int64(42), 1).Return(cancelled, nil)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/orders/42?version=1", nil)
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.DELETE("/api/v1/orders/:id", handler.CancelOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Order
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, response.Status)
+	assert.Equal(t, 2, response.Version)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelOrderErrors(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{"Not found", order.ErrOrderNotFound, http.StatusNotFound},
+		{"Stale version", order.ErrVersionConflict, http.StatusConflict},
+		{"Already filled", lifecycle.ErrInvalidTransition, http.StatusConflict},
+		{"Quantity below filled", order.ErrQuantityBelowFilled, http.StatusUnprocessableEntity},
+		{"Database error", errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockOrderRepository)
+
+			// Create handler with mock repo
+			handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+			// Setup expectations
+			mockRepo.On("Cancel", mock.Anything, "", int64(42), 1).Return(nil, tc.err)
+
+			// Prepare request
+			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/orders/42?version=1", nil)
+
+			// Prepare response recorder
+			w := httptest.NewRecorder()
+
+			// Setup Gin router
+			router := gin.Default()
+			router.DELETE("/api/v1/orders/:id", handler.CancelOrder)
+
+			// Perform request
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCancelOrderMissingVersion(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/orders/42", nil)
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.DELETE("/api/v1/orders/:id", handler.CancelOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockRepo.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAmendOrderHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: the order is looked up for its symbol, then only the price is replaced
+	current := &models.Order{ID: 42, Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 10, Status: models.StatusNew, Version: 1}
+	mockRepo.On("GetByID", mock.Anything, "", int64(42)).Return(current, nil)
+	amended := &models.Order{ID: 42, Symbol: "AAPL", Price: models.MustParseDecimal("151.25"), Quantity: 10, Status: models.StatusNew, Version: 2}
+	priceMatcher := mock.MatchedBy(func(price *models.Decimal) bool {
+		return price != nil && price.Equal(models.MustParseDecimal("151.25"))
+	})
+	mockRepo.On("Amend", mock.Anything, "", int64(42), 1, priceMatcher, (*int)(nil)).Return(amended, nil)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/42", bytes.NewBufferString(`{"version": 1, "price": 151.25}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.PATCH("/api/v1/orders/:id", handler.AmendOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Order
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.MustParseDecimal("151.25"), response.Price)
+	assert.Equal(t, 2, response.Version)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAmendOrderValidationFailed(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name          string
+		body          string
+		expectedField string
+	}{
+		{"Missing version", `{"price": 151.25}`, "version"},
+		{"Nothing to amend", `{"version": 1}`, "price"},
+		{"Invalid price", `{"version": 1, "price": -1}`, "price"},
+		{"Invalid quantity", `{"version": 1, "quantity": 0}`, "quantity"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockOrderRepository)
+
+			// Create handler with mock repo
+			handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+			// Prepare request
+			req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/42", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Prepare response recorder
+			w := httptest.NewRecorder()
+
+			// Setup Gin router
+			router := gin.Default()
+			router.PATCH("/api/v1/orders/:id", handler.AmendOrder)
+
+			// Perform request
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ValidationErrorResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+
+			fields := []string{}
+			for _, validationErr := range response.Errors {
+				fields = append(fields, validationErr.Field)
+			}
+			assert.Contains(t, fields, tc.expectedField)
+			mockRepo.AssertNotCalled(t, "Amend", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAmendOrderClosed(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: the order is looked up for its symbol, then found closed
+	filled := &models.Order{ID: 42, Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 10, Status: models.StatusFilled, Version: 3}
+	mockRepo.On("GetByID", mock.Anything, "", int64(42)).Return(filled, nil)
+	mockRepo.On("Amend", mock.Anything, "", int64(42), 3, (*models.Decimal)(nil), mock.Anything).Return(nil, lifecycle.ErrOrderClosed)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/orders/42", bytes.NewBufferString(`{"version": 3, "quantity": 20}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.PATCH("/api/v1/orders/:id", handler.AmendOrder)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrderRevisionsHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations
+	revisions := []models.OrderRevision{
+		{ID: 1, OrderID: 42, Version: 2, PreviousPrice: models.MustParseDecimal("150.5"), PreviousQuantity: 10, Price: models.MustParseDecimal("151.25"), Quantity: 10},
+	}
+	mockRepo.On("GetRevisions", mock.Anything, "", int64(42)).Return(revisions, nil)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/42/revisions", nil)
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/orders/:id/revisions", handler.GetOrderRevisions)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []models.OrderRevision
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response, 1)
+	assert.Equal(t, models.MustParseDecimal("150.5"), response[0].PreviousPrice)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrderTriggersHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations
+	triggers := []models.OrderTrigger{{
+		ID:            1,
+		OrderID:       1,
+		Kind:          models.Stop,
+		ActivatedKind: models.Market,
+		TriggerPrice:  models.MustParseDecimal("145"),
+		LastPrice:     models.MustParseDecimal("144.5"),
+		Reason:        "last trade price 144.5 is at or below trigger price 145",
+		TriggeredAt:   time.Now(),
+	}}
+	mockRepo.On("GetTriggers", mock.Anything, "", int64(1)).Return(triggers, nil)
+	mockRepo.On("GetTriggers", mock.Anything, "", int64(2)).Return(nil, order.ErrOrderNotFound)
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/orders/:id/triggers", handler.GetOrderTriggers)
+
+	// Perform requests
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/1/triggers", nil)
+	router.ServeHTTP(w, req)
+
+	notFound := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/orders/2/triggers", nil)
+	router.ServeHTTP(notFound, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []models.OrderTrigger
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response, 1)
+	assert.Equal(t, models.Market, response[0].ActivatedKind)
+	assert.Equal(t, models.MustParseDecimal("144.5"), response[0].LastPrice)
+
+	assert.Equal(t, http.StatusNotFound, notFound.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// newAccountRouter returns a router whose requests act for the given account
+func newAccountRouter(account string) *gin.Engine {
+	router := gin.Default()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.PrincipalKey, &models.Principal{Subject: "api-key:7", Account: account, Scopes: models.Scopes{models.ScopeOrdersWrite}})
+	})
+	return router
+}
+
+func TestCreateOrderInCallerAccount(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: the order belongs to the caller's account, and an
+	// account that is not registered is refused
+	inAccount := func(account string) interface{} {
+		return mock.MatchedBy(func(created *models.Order) bool {
+			return created.AccountID != nil && *created.AccountID == account
+		})
+	}
+	mockRepo.On("Create", mock.Anything, inAccount("acme")).Return(nil)
+	mockRepo.On("Create", mock.Anything, inAccount("ghost")).Return(order.ErrAccountNotFound)
+
+	jsonData, _ := json.Marshal(models.OrderRequest{
+		Symbol:    "AAPL",
+		Price:     models.MustParseDecimal("150.5"),
+		Quantity:  10,
+		OrderType: models.Buy,
+	})
+
+	// Perform requests
+	w := httptest.NewRecorder()
+	router := newAccountRouter("acme")
+	router.POST("/api/v1/orders", handler.CreateOrder)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	unknown := httptest.NewRecorder()
+	router = newAccountRouter("ghost")
+	router.POST("/api/v1/orders", handler.CreateOrder)
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(unknown, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.Order
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.NotNil(t, response.AccountID) {
+		assert.Equal(t, "acme", *response.AccountID)
+	}
+
+	assert.Equal(t, http.StatusForbidden, unknown.Code)
+	assert.Contains(t, unknown.Body.String(), "Account ghost is not registered")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrdersInCallerAccount(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: the caller's account wins over the query's, while
+	// the admin listing takes the account from the query
+	mockRepo.On("GetAll", mock.Anything, models.OrderFilter{AccountID: "acme", Limit: 10}).
+		Return(&models.Page[models.Order]{Data: []models.Order{}}, nil)
+	mockRepo.On("GetAll", mock.Anything, models.OrderFilter{AccountID: "globex", Limit: 10}).
+		Return(&models.Page[models.Order]{Data: []models.Order{}}, nil)
+
+	// Setup Gin router
+	router := newAccountRouter("acme")
+	router.GET("/api/v1/orders", handler.GetOrders)
+	router.GET("/api/v1/admin/orders", handler.AdminGetOrders)
+
+	// Perform requests
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders?account_id=globex&limit=10", nil)
+	router.ServeHTTP(w, req)
+
+	admin := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/orders?account_id=globex&limit=10", nil)
+	router.ServeHTTP(admin, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusOK, admin.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrderInCallerAccount(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup expectations: another account's order is not found for the
+	// caller, but the admin route looks it up in every account
+	globex := "globex"
+	mockRepo.On("GetByID", mock.Anything, "acme", int64(1)).Return(nil, order.ErrOrderNotFound)
+	mockRepo.On("GetByID", mock.Anything, "", int64(1)).Return(&models.Order{ID: 1, AccountID: &globex, Symbol: "AAPL"}, nil)
+
+	// Setup Gin router
+	router := newAccountRouter("acme")
+	router.GET("/api/v1/orders/:id", handler.GetOrder)
+	router.GET("/api/v1/admin/orders/:id", handler.AdminGetOrder)
+
+	// Perform requests
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/1", nil)
+	router.ServeHTTP(w, req)
+
+	admin := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/orders/1", nil)
+	router.ServeHTTP(admin, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, http.StatusOK, admin.Code)
+
+	var response models.Order
+	err := json.Unmarshal(admin.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.NotNil(t, response.AccountID) {
+		assert.Equal(t, "globex", *response.AccountID)
+	}
+
 	mockRepo.AssertExpectations(t)
 }
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Javlopez/go-api/cmd/api/middleware"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// tracer starts the spans of the API's handlers
+var tracer = otel.Tracer("github.com/Javlopez/go-api/cmd/api/handlers")
+
+// traceHandler starts a span named name for the rest of the handler and
+// makes it the parent of the queries the handler runs, recording the
+// authenticated principal as enduser.id. The returned function
+// ends the span, marking it failed if the handler answered with a 5xx.
+func traceHandler(c *gin.Context, name string) func() {
+	ctx, span := tracer.Start(c.Request.Context(), name)
+	c.Request = c.Request.WithContext(ctx)
+	if principal := middleware.PrincipalFrom(c); principal != nil {
+		span.SetAttributes(attribute.String("enduser.id", principal.Subject))
+	}
+
+	return func() {
+		if status := c.Writer.Status(); status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Javlopez/go-api/cmd/api/middleware"
+	"github.com/Javlopez/go-api/pkg/matching"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spansOnce sync.Once
+	spans     *tracetest.InMemoryExporter
+)
+
+// recordSpans installs, once per test binary, a global tracer provider that
+// keeps finished spans in memory, and returns its exporter emptied
+func recordSpans() *tracetest.InMemoryExporter {
+	spansOnce.Do(func() {
+		spans = tracetest.NewInMemoryExporter()
+		otel.SetTracerProvider(tracing.NewProvider(sdktrace.WithSyncer(spans)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	spans.Reset()
+	return spans
+}
+
+func TestOrderHandlerSpansContinueIncomingTrace(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+	exporter := recordSpans()
+
+	// Create mock repository that sees the handler's span in its context
+	mockRepo := new(MockOrderRepository)
+	var queried trace.SpanContext
+	found := &models.Order{ID: 42, Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 10, OrderType: models.Buy, Status: models.StatusNew}
+	mockRepo.On("GetByID", mock.Anything, "", int64(42)).Run(func(args mock.Arguments) {
+		queried = trace.SpanContextFromContext(args.Get(0).(context.Context))
+	}).Return(found, nil)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Prepare request carrying the caller's trace context
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Setup Gin router
+	router := gin.New()
+	router.Use(otelgin.Middleware(tracing.ServiceName))
+	router.GET("/api/v1/orders/:id", handler.GetOrder)
+
+	// Perform request
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert: the request span continues the caller's trace and parents the handler span
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	recorded := exporter.GetSpans()
+	if assert.Len(t, recorded, 2) {
+		handlerSpan, requestSpan := recorded[0], recorded[1]
+		assert.Equal(t, "OrderHandler.GetOrder", handlerSpan.Name)
+		assert.Equal(t, "/api/v1/orders/:id", requestSpan.Name)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", requestSpan.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", requestSpan.Parent.SpanID().String())
+		assert.Equal(t, requestSpan.SpanContext.SpanID(), handlerSpan.Parent.SpanID())
+		assert.Equal(t, handlerSpan.SpanContext.SpanID(), queried.SpanID())
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderHandlerSpanMarksServerErrors(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+	exporter := recordSpans()
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+	mockRepo.On("GetAll", mock.Anything, models.OrderFilter{}).Return(nil, assert.AnError)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup Gin router
+	router := gin.New()
+	router.GET("/api/v1/orders", handler.GetOrders)
+
+	// Perform request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	if recorded := exporter.GetSpans(); assert.Len(t, recorded, 1) {
+		assert.Equal(t, codes.Error, recorded[0].Status.Code)
+	}
+}
+
+func TestOrderHandlerSpanRecordsPrincipal(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+	exporter := recordSpans()
+
+	// Create mock repository
+	mockRepo := new(MockOrderRepository)
+	mockRepo.On("GetAll", mock.Anything, models.OrderFilter{}).Return(&models.Page[models.Order]{Data: []models.Order{}}, nil)
+
+	// Create handler with mock repo
+	handler := NewOrderHandler(mockRepo, newListedInstruments(), matching.NewEngine(), nil)
+
+	// Setup Gin router with an authenticated caller
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.PrincipalKey, &models.Principal{Subject: "api-key:7", Name: "trading-bot", Scopes: models.Scopes{models.ScopeOrdersRead}})
+	})
+	router.GET("/api/v1/orders", handler.GetOrders)
+
+	// Perform request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	if recorded := exporter.GetSpans(); assert.Len(t, recorded, 1) {
+		assert.Contains(t, recorded[0].Attributes, attribute.String("enduser.id", "api-key:7"))
+	}
+}
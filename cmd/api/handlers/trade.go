@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/trade"
+	"github.com/gin-gonic/gin"
+)
+
+// TradeHandler handles trade-related requests
+type TradeHandler struct {
+	repo trade.TradeRepository
+}
+
+// NewTradeHandler creates a new trade handler
+func NewTradeHandler(repo trade.TradeRepository) *TradeHandler {
+	return &TradeHandler{repo: repo}
+}
+
+// GetTrades godoc
+// @Summary Get executed trades
+// @Description Retrieve the executions in which one of the caller's account's orders took part, most recent first, optionally filtered by symbol, order and execution time range [from, to)
+// @Tags trades
+// @Produce json
+// @Param symbol query string false "Symbol"
+// @Param order_id query int false "Buy or sell order ID"
+// @Param from query string false "Executed at or after (RFC 3339)"
+// @Param to query string false "Executed before (RFC 3339)"
+// @Success 200 {array} models.Trade
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /trades [get]
+func (h *TradeHandler) GetTrades(c *gin.Context) {
+	filter, ok := bindTradeFilter(c)
+	if !ok {
+		return
+	}
+	filter.AccountID = callerAccount(c)
+
+	h.writeTrades(c, filter)
+}
+
+// AdminGetTrades godoc
+// @Summary Get executed trades across accounts
+// @Description Retrieve every account's executions, including those of orders placed before accounts existed, optionally narrowed to one account's with account_id. The other filters work as for GET /trades.
+// @Tags trades
+// @Produce json
+// @Param account_id query string false "Account ID"
+// @Param symbol query string false "Symbol"
+// @Param order_id query int false "Buy or sell order ID"
+// @Param from query string false "Executed at or after (RFC 3339)"
+// @Param to query string false "Executed before (RFC 3339)"
+// @Success 200 {array} models.Trade
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/trades [get]
+func (h *TradeHandler) AdminGetTrades(c *gin.Context) {
+	filter, ok := bindTradeFilter(c)
+	if !ok {
+		return
+	}
+
+	h.writeTrades(c, filter)
+}
+
+// bindTradeFilter reads a trade listing's filter from the query, writing a
+// 400 response if it is invalid
+func bindTradeFilter(c *gin.Context) (models.TradeFilter, bool) {
+	var filter models.TradeFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "Invalid filter: symbol, order_id, from and to (RFC 3339) are supported"))
+		return filter, false
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "from must be before to"))
+		return filter, false
+	}
+	return filter, true
+}
+
+// writeTrades answers with the trades matching filter
+func (h *TradeHandler) writeTrades(c *gin.Context, filter models.TradeFilter) {
+	trades, err := h.repo.GetAll(c.Request.Context(), filter)
+	if err != nil {
+		writeServerError(c, "Failed to fetch trades", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, trades)
+}
+
+// GetTrade godoc
+// @Summary Get an executed trade
+// @Description Retrieve a single execution by its ID, if one of the caller's account's orders took part in it
+// @Tags trades
+// @Produce json
+// @Param id path int true "Trade ID"
+// @Success 200 {object} models.Trade
+// @Failure 400 {object} models.ErrorResponse "Invalid trade ID"
+// @Failure 404 {object} models.ErrorResponse "Trade not found"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /trades/{id} [get]
+func (h *TradeHandler) GetTrade(c *gin.Context) {
+	h.writeTrade(c, callerAccount(c))
+}
+
+// AdminGetTrade godoc
+// @Summary Get an executed trade of any account
+// @Description Retrieve a single execution by its ID, whichever accounts took part in it
+// @Tags trades
+// @Produce json
+// @Param id path int true "Trade ID"
+// @Success 200 {object} models.Trade
+// @Failure 400 {object} models.ErrorResponse "Invalid trade ID"
+// @Failure 404 {object} models.ErrorResponse "Trade not found"
+// @Failure 500 {object} models.ErrorResponse "Server error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/trades/{id} [get]
+func (h *TradeHandler) AdminGetTrade(c *gin.Context) {
+	h.writeTrade(c, "")
+}
+
+// writeTrade answers with the trade of account named by the :id path parameter
+func (h *TradeHandler) writeTrade(c *gin.Context, account string) {
+	id, ok := parseID(c, "Invalid trade ID")
+	if !ok {
+		return
+	}
+
+	tradeFound, err := h.repo.GetByID(c.Request.Context(), account, id)
+	if errors.Is(err, trade.ErrTradeNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "Trade not found"))
+		return
+	}
+	if err != nil {
+		writeServerError(c, "Failed to fetch trade", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tradeFound)
+}
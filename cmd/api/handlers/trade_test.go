@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/trade"
+)
+
+// MockTradeRepository is a mock implementation of TradeRepository interface
+type MockTradeRepository struct {
+	mock.Mock
+}
+
+func (m *MockTradeRepository) GetAll(ctx context.Context, filter models.TradeFilter) ([]models.Trade, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.Trade), args.Error(1)
+}
+
+func (m *MockTradeRepository) GetByID(ctx context.Context, account string, id int64) (*models.Trade, error) {
+	args := m.Called(ctx, account, id)
+	if trade, ok := args.Get(0).(*models.Trade); ok {
+		return trade, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTradeRepository) GetLatest(ctx context.Context) ([]models.Trade, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Trade), args.Error(1)
+}
+
+func TestGetTradesHandler(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockTradeRepository)
+
+	// Create handler with mock repo
+	handler := NewTradeHandler(mockRepo)
+
+	// Setup expectations
+	from := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	trades := []models.Trade{
+		{ID: 1, BuyOrderID: 2, SellOrderID: 1, Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 4, ExecutedAt: from.Add(time.Hour)},
+	}
+	mockRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(filter models.TradeFilter) bool {
+		return filter.Symbol == "AAPL" && filter.From != nil && filter.From.Equal(from) && filter.To == nil
+	})).Return(trades, nil)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/trades?symbol=AAPL&from=2025-01-02T00:00:00Z", nil)
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/trades", handler.GetTrades)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []models.Trade
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response, 1)
+	assert.Equal(t, int64(2), response[0].BuyOrderID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTradesInvalidFilter(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name  string
+		query string
+	}{
+		{"Malformed time", "?from=yesterday"},
+		{"Empty range", "?from=2025-01-03T00:00:00Z&to=2025-01-02T00:00:00Z"},
+		{"Invalid order ID", "?order_id=-1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create mock repository
+			mockRepo := new(MockTradeRepository)
+
+			// Create handler with mock repo
+			handler := NewTradeHandler(mockRepo)
+
+			// Prepare request
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/trades"+tc.query, nil)
+
+			// Prepare response recorder
+			w := httptest.NewRecorder()
+
+			// Setup Gin router
+			router := gin.Default()
+			router.GET("/api/v1/trades", handler.GetTrades)
+
+			// Perform request
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockRepo.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetTradeNotFound(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockTradeRepository)
+
+	// Create handler with mock repo
+	handler := NewTradeHandler(mockRepo)
+
+	// Setup expectations
+	mockRepo.On("GetByID", mock.Anything, "", int64(42)).Return(nil, trade.ErrTradeNotFound)
+
+	// Prepare request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/trades/42", nil)
+
+	// Prepare response recorder
+	w := httptest.NewRecorder()
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/trades/:id", handler.GetTrade)
+
+	// Perform request
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTradesInCallerAccount(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockTradeRepository)
+
+	// Create handler with mock repo
+	handler := NewTradeHandler(mockRepo)
+
+	// Setup expectations: acme lists only its trades, whatever account_id
+	// says, and another account's trade is not found
+	mockRepo.On("GetAll", mock.Anything, mock.MatchedBy(func(filter models.TradeFilter) bool {
+		return filter.AccountID == "acme"
+	})).Return([]models.Trade{{ID: 1}}, nil)
+	mockRepo.On("GetByID", mock.Anything, "acme", int64(1)).Return(&models.Trade{ID: 1}, nil)
+	mockRepo.On("GetByID", mock.Anything, "acme", int64(2)).Return(nil, trade.ErrTradeNotFound)
+
+	// Setup Gin router
+	router := newAccountRouter("acme")
+	router.GET("/api/v1/trades", handler.GetTrades)
+	router.GET("/api/v1/trades/:id", handler.GetTrade)
+
+	// Perform request
+	list := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/trades?account_id=globex", nil)
+	router.ServeHTTP(list, req)
+
+	own := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/trades/1", nil)
+	router.ServeHTTP(own, req)
+
+	other := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/trades/2", nil)
+	router.ServeHTTP(other, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, list.Code)
+	assert.Equal(t, http.StatusOK, own.Code)
+	assert.Equal(t, http.StatusNotFound, other.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAdminGetTrades(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create mock repository
+	mockRepo := new(MockTradeRepository)
+
+	// Create handler with mock repo
+	handler := NewTradeHandler(mockRepo)
+
+	// Setup expectations: an administrator without an account sees every
+	// account's trades, or one account's when it asks for them
+	mockRepo.On("GetAll", mock.Anything, models.TradeFilter{}).Return([]models.Trade{{ID: 1}, {ID: 2}}, nil)
+	mockRepo.On("GetAll", mock.Anything, models.TradeFilter{AccountID: "globex"}).Return([]models.Trade{{ID: 2}}, nil)
+	mockRepo.On("GetByID", mock.Anything, "", int64(2)).Return(&models.Trade{ID: 2}, nil)
+
+	// Setup Gin router
+	router := gin.Default()
+	router.GET("/api/v1/admin/trades", handler.AdminGetTrades)
+	router.GET("/api/v1/admin/trades/:id", handler.AdminGetTrade)
+
+	// Perform request
+	all := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/trades", nil)
+	router.ServeHTTP(all, req)
+
+	globex := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/trades?account_id=globex", nil)
+	router.ServeHTTP(globex, req)
+
+	one := httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/admin/trades/2", nil)
+	router.ServeHTTP(one, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, all.Code)
+	var trades []models.Trade
+	assert.NoError(t, json.Unmarshal(all.Body.Bytes(), &trades))
+	assert.Len(t, trades, 2)
+	assert.Equal(t, http.StatusOK, globex.Code)
+	assert.NoError(t, json.Unmarshal(globex.Body.Bytes(), &trades))
+	assert.Len(t, trades, 1)
+	assert.Equal(t, http.StatusOK, one.Code)
+	mockRepo.AssertExpectations(t)
+}
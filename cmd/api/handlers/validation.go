@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Validate decimals by their exact units, so tags such as gt=0 apply to prices
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+			return field.Interface().(models.Decimal).Units()
+		}, models.Decimal{})
+	}
+}
+
+// newValidationErrorResponse converts a binding error into user-friendly field errors
+func newValidationErrorResponse(err error) models.ValidationErrorResponse {
+	var validationErrors models.ValidationErrorResponse
+
+	// Check if this is a validation error
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		// Process validation errors
+		for _, e := range validationErrs {
+			field := strings.ToLower(e.Field())
+			var message string
+
+			// Create user-friendly error messages
+			switch e.Tag() {
+			case "required":
+				message = fmt.Sprintf("%s is required", field)
+			case "required_without":
+				message = fmt.Sprintf("%s is required when %s is not provided", field, strings.ToLower(e.Param()))
+			case "required_unless":
+				message = fmt.Sprintf("%s is required unless %s", field, describeCondition(e.Param()))
+			case "required_if":
+				message = fmt.Sprintf("%s is required when %s", field, describeCondition(e.Param()))
+			case "excluded_if":
+				message = fmt.Sprintf("%s must not be set when %s", field, describeCondition(e.Param()))
+			case "excluded_unless":
+				message = fmt.Sprintf("%s must not be set unless %s", field, describeCondition(e.Param()))
+			case "gt":
+				if e.Param() == "" {
+					// A bare gt on a timestamp means it must be in the future
+					message = fmt.Sprintf("%s must be in the future", field)
+					break
+				}
+				message = fmt.Sprintf("%s must be greater than %s", field, e.Param())
+			case "oneof":
+				message = fmt.Sprintf("%s must be one of: %s", field, e.Param())
+			default:
+				message = fmt.Sprintf("%s failed validation: %s", field, e.Tag())
+			}
+
+			validationErrors.Errors = append(validationErrors.Errors, models.ValidationError{
+				Field:   field,
+				Message: message,
+			})
+		}
+	}
+
+	return validationErrors
+}
+
+// describeCondition renders a "Field value" validator parameter as "field is value".
+// Several pairs, as taken by required_unless, are joined with "or".
+func describeCondition(param string) string {
+	parts := strings.Fields(param)
+	if len(parts) == 0 || len(parts)%2 != 0 {
+		return param
+	}
+
+	conditions := make([]string, 0, len(parts)/2)
+	for i := 0; i < len(parts); i += 2 {
+		conditions = append(conditions, fmt.Sprintf("%s is %s", strings.ToLower(parts[i]), parts[i+1]))
+	}
+	return strings.Join(conditions, " or ")
+}
+
+// checkPriceScale returns a field error if price has more decimal places than symbol allows
+func checkPriceScale(scales models.PriceScales, symbol, field string, price *models.Decimal) []models.ValidationError {
+	if price == nil {
+		return nil
+	}
+
+	scale := scales.For(symbol)
+	if price.Scale() <= scale {
+		return nil
+	}
+	return []models.ValidationError{{
+		Field:   field,
+		Message: fmt.Sprintf("%s must have at most %d decimal places for %s", field, scale, symbol),
+	}}
+}
+
+// checkQuantityLimits returns a field error if listing's maximum quantity is
+// below its minimum. The minimum may only be known once it has defaulted to
+// one lot, so binding cannot catch this on its own.
+func checkQuantityLimits(listing *models.Instrument) []models.ValidationError {
+	if listing.MaxQuantity == 0 || listing.MaxQuantity >= listing.MinQuantity {
+		return nil
+	}
+	return []models.ValidationError{{
+		Field:   "maxquantity",
+		Message: fmt.Sprintf("maxquantity must be at least the minimum quantity %d", listing.MinQuantity),
+	}}
+}
+
+// checkInstrumentRules returns field errors for an order whose prices are off
+// the instrument's tick size or whose quantity is not a whole number of lots
+// within its limits. Market and stop orders carry no price to check.
+func checkInstrumentRules(listing *models.Instrument, o *models.Order) []models.ValidationError {
+	var errs []models.ValidationError
+
+	checkTick := func(field string, price *models.Decimal) {
+		if price != nil && !price.IsMultipleOf(listing.TickSize) {
+			errs = append(errs, models.ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("%s must be a multiple of the tick size %s for %s", field, listing.TickSize, listing.Symbol),
+			})
+		}
+	}
+	if o.Kind != models.Market && o.Kind != models.Stop {
+		checkTick("price", &o.Price)
+	}
+	checkTick("triggerprice", o.TriggerPrice)
+
+	var message string
+	switch {
+	case o.Quantity%listing.LotSize != 0:
+		message = fmt.Sprintf("quantity must be a multiple of the lot size %d for %s", listing.LotSize, listing.Symbol)
+	case o.Quantity < listing.MinQuantity:
+		message = fmt.Sprintf("quantity must be at least %d for %s", listing.MinQuantity, listing.Symbol)
+	case listing.MaxQuantity > 0 && o.Quantity > listing.MaxQuantity:
+		message = fmt.Sprintf("quantity must be at most %d for %s", listing.MaxQuantity, listing.Symbol)
+	}
+	if message != "" {
+		errs = append(errs, models.ValidationError{Field: "quantity", Message: message})
+	}
+	return errs
+}
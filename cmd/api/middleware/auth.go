@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Javlopez/go-api/pkg/auth"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyHeader carries an API key for clients that cannot set Authorization
+	APIKeyHeader = "X-API-Key"
+	// PrincipalKey is the Gin context key of the authenticated *models.Principal
+	PrincipalKey = "principal"
+)
+
+// Authenticate returns middleware that identifies the caller from the
+// credential in its Authorization: Bearer or X-API-Key header, rejecting
+// requests without an acceptable one with 401. The caller's principal is
+// stored under PrincipalKey.
+func Authenticate(authenticator auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credential := credential(c.Request)
+		if credential == "" {
+			unauthorized(c, "Missing bearer token or API key")
+			return
+		}
+
+		principal, err := authenticator.Authenticate(c.Request.Context(), credential)
+		if errors.Is(err, auth.ErrInvalidCredential) {
+			slog.DebugContext(c.Request.Context(), "Rejected credential", slog.Any("error", err))
+			unauthorized(c, "Invalid credentials")
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to check credentials", slog.Any("error", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(c, "Failed to check credentials"))
+			return
+		}
+
+		c.Set(PrincipalKey, principal)
+		c.Next()
+	}
+}
+
+// RequireScope returns middleware that answers 403 unless the authenticated
+// principal holds scope
+func RequireScope(scope models.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalFrom(c)
+		if principal == nil {
+			unauthorized(c, "Missing bearer token or API key")
+			return
+		}
+		if !principal.Scopes.Has(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(c, fmt.Sprintf("Caller lacks the %s scope", scope)))
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAccount returns middleware that answers 403 unless the authenticated
+// principal acts for an account, which the order routes are scoped to
+func RequireAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalFrom(c)
+		if principal == nil {
+			unauthorized(c, "Missing bearer token or API key")
+			return
+		}
+		if principal.Account == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(c, "Caller does not act for an account"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// PrincipalFrom returns the principal authenticated for the request, or nil
+func PrincipalFrom(c *gin.Context) *models.Principal {
+	value, _ := c.Get(PrincipalKey)
+	principal, _ := value.(*models.Principal)
+	return principal
+}
+
+// credential returns the token from Authorization: Bearer, or else X-API-Key
+func credential(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return r.Header.Get(APIKeyHeader)
+}
+
+// unauthorized answers 401, pointing the client at bearer authentication
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(c, message))
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Javlopez/go-api/pkg/auth"
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// stubAuthenticator accepts the credentials it maps to principals. The
+// credential "broken" cannot be checked.
+type stubAuthenticator map[string]*models.Principal
+
+func (s stubAuthenticator) Authenticate(ctx context.Context, credential string) (*models.Principal, error) {
+	if credential == "broken" {
+		return nil, assert.AnError
+	}
+	if principal, ok := s[credential]; ok {
+		return principal, nil
+	}
+	return nil, auth.ErrInvalidCredential
+}
+
+// newAuthRouter serves GET /orders to orders:read and POST /orders to
+// orders:write, echoing the authenticated principal's subject
+func newAuthRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	authenticator := stubAuthenticator{
+		"ak_reader": {Subject: "api-key:7", Name: "reader", Scopes: models.Scopes{models.ScopeOrdersRead}},
+		"ak_admin":  {Subject: "api-key:admin", Name: "admin", Scopes: models.Scopes{models.ScopeAdmin}},
+	}
+	echo := func(c *gin.Context) {
+		c.String(http.StatusOK, PrincipalFrom(c).Subject)
+	}
+
+	router := gin.New()
+	api := router.Group("/", Authenticate(authenticator))
+	api.GET("/orders", RequireScope(models.ScopeOrdersRead), echo)
+	api.POST("/orders", RequireScope(models.ScopeOrdersWrite), echo)
+	return router
+}
+
+func TestAuthenticateAcceptsCredentialHeaders(t *testing.T) {
+	router := newAuthRouter()
+
+	for name, header := range map[string][2]string{
+		"Bearer":    {"Authorization", "Bearer ak_reader"},
+		"Lowercase": {"Authorization", "bearer ak_reader"},
+		"X-API-Key": {APIKeyHeader, "ak_reader"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Perform request
+			req, _ := http.NewRequest(http.MethodGet, "/orders", nil)
+			req.Header.Set(header[0], header[1])
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "api-key:7", w.Body.String())
+		})
+	}
+}
+
+func TestAuthenticateRejectsRequests(t *testing.T) {
+	router := newAuthRouter()
+
+	testCases := []struct {
+		name         string
+		method       string
+		credential   string
+		expectedCode int
+	}{
+		{name: "No credential", method: http.MethodGet, credential: "", expectedCode: http.StatusUnauthorized},
+		{name: "Invalid credential", method: http.MethodGet, credential: "ak_revoked", expectedCode: http.StatusUnauthorized},
+		{name: "Missing scope", method: http.MethodPost, credential: "ak_reader", expectedCode: http.StatusForbidden},
+		{name: "Admin grants every scope", method: http.MethodPost, credential: "ak_admin", expectedCode: http.StatusOK},
+		{name: "Check failure", method: http.MethodGet, credential: "broken", expectedCode: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Perform request
+			req, _ := http.NewRequest(tc.method, "/orders", nil)
+			if tc.credential != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.credential)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
+
+func TestRequireAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authenticator := stubAuthenticator{
+		"ak_acme":  {Subject: "api-key:7", Name: "acme", Account: "acme", Scopes: models.Scopes{models.ScopeOrdersRead}},
+		"ak_admin": {Subject: "api-key:admin", Name: "admin", Scopes: models.Scopes{models.ScopeAdmin}},
+	}
+
+	// Setup Gin router
+	router := gin.New()
+	router.GET("/orders", Authenticate(authenticator), RequireAccount(), func(c *gin.Context) {
+		c.String(http.StatusOK, PrincipalFrom(c).Account)
+	})
+
+	testCases := []struct {
+		name         string
+		credential   string
+		expectedCode int
+	}{
+		{name: "Account", credential: "ak_acme", expectedCode: http.StatusOK},
+		{name: "No account", credential: "ak_admin", expectedCode: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Perform request
+			req, _ := http.NewRequest(http.MethodGet, "/orders", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.credential)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedCode == http.StatusOK {
+				assert.Equal(t, "acme", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Package middleware holds Gin middleware shared by the API routes.
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's key for a retryable request
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a stored key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength matches the idempotency_keys.key column
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency makes requests carrying an Idempotency-Key safe to retry. The
+// first request with a key runs normally and a successful response is stored;
+// retries with the same body get that response back instead of running again.
+type Idempotency struct {
+	repo               idempotency.KeyRepository
+	retention          time.Duration
+	reservationTimeout time.Duration
+	now                func() time.Time
+}
+
+// NewIdempotency creates idempotency middleware that remembers keys for
+// retention. A key whose first request has not answered within
+// reservationTimeout is presumed abandoned, e.g. by a crash, and may be
+// taken over by a retry; it should be well above the longest request.
+func NewIdempotency(repo idempotency.KeyRepository, retention, reservationTimeout time.Duration) *Idempotency {
+	return &Idempotency{
+		repo:               repo,
+		retention:          retention,
+		reservationTimeout: reservationTimeout,
+		now:                time.Now,
+	}
+}
+
+// Handler returns the Gin middleware. Requests without the header pass
+// through. Keys are scoped to the calling principal, so callers cannot see or
+// block each other's keys. A key reused with a different request returns 422 and a key whose
+// first request is still running returns 409, until reservationTimeout has
+// passed and the retry takes the key over. Only 2xx responses are stored;
+// any other outcome releases the key so the client can retry.
+func (m *Idempotency) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(c, "Idempotency-Key must be at most 255 characters"))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(c, "Failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var subject string
+		if principal := PrincipalFrom(c); principal != nil {
+			subject = principal.Subject
+		}
+		hash := requestHash(c.Request, subject, body)
+		now := m.now().UTC()
+		existing, err := m.repo.Reserve(c.Request.Context(), subject, key, hash, now, now.Add(-m.retention), now.Add(-m.reservationTimeout))
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to check Idempotency-Key", slog.Any("error", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(c, "Failed to check Idempotency-Key"))
+			return
+		}
+		if existing != nil {
+			m.replay(c, existing, hash)
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Record the outcome even if the client has gone away
+		ctx := context.WithoutCancel(c.Request.Context())
+		status := recorder.Status()
+		if status >= 200 && status < 300 {
+			if err := m.repo.Complete(ctx, subject, key, status, recorder.body.Bytes()); err != nil {
+				slog.ErrorContext(ctx, "Failed to store response for idempotency key", slog.String("idempotency_key", key), slog.Any("error", err))
+			}
+			return
+		}
+		if err := m.repo.Release(ctx, subject, key); err != nil {
+			slog.ErrorContext(ctx, "Failed to release idempotency key", slog.String("idempotency_key", key), slog.Any("error", err))
+		}
+	}
+}
+
+// replay answers a request whose key is already held
+func (m *Idempotency) replay(c *gin.Context, existing *models.IdempotencyKey, hash string) {
+	switch {
+	case existing.RequestHash != hash:
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse(c, "Idempotency-Key was already used with a different request"))
+	case existing.StatusCode == nil:
+		c.AbortWithStatusJSON(http.StatusConflict, errorResponse(c, "A request with this Idempotency-Key is still in progress"))
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(*existing.StatusCode, "application/json; charset=utf-8", existing.ResponseBody)
+		c.Abort()
+	}
+}
+
+// Run purges expired keys immediately and then every interval, until ctx is done
+func (m *Idempotency) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.repo.Purge(ctx, m.now().UTC().Add(-m.retention)); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to purge idempotency keys", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// requestHash fingerprints a request by method, path, caller and body
+func requestHash(r *http.Request, subject string, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.Path+"\n"+subject+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// bodyRecorder copies everything written to the response so it can be stored
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *bodyRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
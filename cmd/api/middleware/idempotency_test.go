@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// MockKeyRepository is a mock implementation of KeyRepository interface
+type MockKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockKeyRepository) Reserve(ctx context.Context, subject, key, requestHash string, now, expiredBefore, abandonedBefore time.Time) (*models.IdempotencyKey, error) {
+	args := m.Called(ctx, subject, key, requestHash, now, expiredBefore, abandonedBefore)
+	existing, _ := args.Get(0).(*models.IdempotencyKey)
+	return existing, args.Error(1)
+}
+
+func (m *MockKeyRepository) Complete(ctx context.Context, subject, key string, statusCode int, body []byte) error {
+	args := m.Called(ctx, subject, key, statusCode, body)
+	return args.Error(0)
+}
+
+func (m *MockKeyRepository) Release(ctx context.Context, subject, key string) error {
+	args := m.Called(ctx, subject, key)
+	return args.Error(0)
+}
+
+func (m *MockKeyRepository) Purge(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	args := m.Called(ctx, expiredBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+var requestedAt = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+// callerHeader names the test principal's subject in requests to newIdempotentRouter
+const callerHeader = "X-Test-Caller"
+
+// newIdempotentRouter serves POST /orders through the middleware, answering
+// with status and counting how often the handler runs. Requests carrying
+// callerHeader are made by a principal with that subject.
+func newIdempotentRouter(repo *MockKeyRepository, status int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	idempotent := NewIdempotency(repo, 24*time.Hour, time.Minute)
+	idempotent.now = func() time.Time { return requestedAt }
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if subject := c.GetHeader(callerHeader); subject != "" {
+			c.Set(PrincipalKey, &models.Principal{Subject: subject})
+		}
+	})
+	router.POST("/orders", idempotent.Handler(), func(c *gin.Context) {
+		*calls++
+		c.JSON(status, gin.H{"id": *calls})
+	})
+	return router
+}
+
+func postOrder(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	return postOrderAs(router, "", key, body)
+}
+
+// postOrderAs posts an order as the principal with subject, or anonymously if it is empty
+func postOrderAs(router *gin.Engine, subject, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if subject != "" {
+		req.Header.Set(callerHeader, subject)
+	}
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	repo := new(MockKeyRepository)
+	calls := 0
+	router := newIdempotentRouter(repo, http.StatusCreated, &calls)
+
+	w := postOrder(router, "", `{"symbol": "AAPL"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 1, calls)
+	repo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyStoresFirstResponse(t *testing.T) {
+	repo := new(MockKeyRepository)
+	calls := 0
+	router := newIdempotentRouter(repo, http.StatusCreated, &calls)
+
+	// Setup expectations: the key is reserved, then the response is stored
+	repo.On("Reserve", mock.Anything, "", "key-1", mock.AnythingOfType("string"), requestedAt, requestedAt.Add(-24*time.Hour), requestedAt.Add(-time.Minute)).Return(nil, nil)
+	repo.On("Complete", mock.Anything, "", "key-1", http.StatusCreated, []byte(`{"id":1}`)).Return(nil)
+
+	w := postOrder(router, "key-1", `{"symbol": "AAPL"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	repo := new(MockKeyRepository)
+	calls := 0
+	router := newIdempotentRouter(repo, http.StatusCreated, &calls)
+	body := `{"symbol": "AAPL"}`
+
+	// Setup expectations: the key already holds a response to the same request
+	req, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+	status := http.StatusCreated
+	repo.On("Reserve", mock.Anything, "", "key-1", mock.AnythingOfType("string"), mock.Anything, mock.Anything, mock.Anything).Return(&models.IdempotencyKey{
+		Key:          "key-1",
+		RequestHash:  requestHash(req, "", []byte(body)),
+		StatusCode:   &status,
+		ResponseBody: []byte(`{"id":41}`),
+	}, nil)
+
+	w := postOrder(router, "key-1", body)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id":41}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 0, calls)
+	repo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyRejectsHeldKeys(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+	status := http.StatusCreated
+
+	testCases := []struct {
+		name         string
+		existing     *models.IdempotencyKey
+		expectedCode int
+	}{
+		{
+			name:         "Different request",
+			existing:     &models.IdempotencyKey{Key: "key-1", RequestHash: requestHash(req, "", []byte(`{"symbol": "MSFT"}`)), StatusCode: &status},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "Still in progress",
+			existing:     &models.IdempotencyKey{Key: "key-1", RequestHash: requestHash(req, "", []byte(`{"symbol": "AAPL"}`))},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockKeyRepository)
+			calls := 0
+			router := newIdempotentRouter(repo, http.StatusCreated, &calls)
+
+			repo.On("Reserve", mock.Anything, "", "key-1", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tc.existing, nil)
+
+			w := postOrder(router, "key-1", `{"symbol": "AAPL"}`)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Equal(t, 0, calls)
+		})
+	}
+}
+
+func TestIdempotencyReleasesFailedRequests(t *testing.T) {
+	repo := new(MockKeyRepository)
+	calls := 0
+	router := newIdempotentRouter(repo, http.StatusBadRequest, &calls)
+
+	// Setup expectations: a rejected request frees the key for a corrected retry
+	repo.On("Reserve", mock.Anything, "", "key-1", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	repo.On("Release", mock.Anything, "", "key-1").Return(nil)
+
+	w := postOrder(router, "key-1", `{"symbol": ""}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 1, calls)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyKeysAreScopedToCaller(t *testing.T) {
+	repo := new(MockKeyRepository)
+	calls := 0
+	router := newIdempotentRouter(repo, http.StatusCreated, &calls)
+
+	// Setup expectations: each caller reserves and completes its own key-1
+	for _, subject := range []string{"api-key:1", "api-key:2"} {
+		repo.On("Reserve", mock.Anything, subject, "key-1", mock.AnythingOfType("string"), mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Once()
+		repo.On("Complete", mock.Anything, subject, "key-1", http.StatusCreated, mock.Anything).Return(nil).Once()
+	}
+
+	first := postOrderAs(router, "api-key:1", "key-1", `{"symbol": "AAPL"}`)
+	second := postOrderAs(router, "api-key:2", "key-1", `{"symbol": "AAPL"}`)
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Empty(t, second.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 2, calls)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotencyKeyTooLong(t *testing.T) {
+	repo := new(MockKeyRepository)
+	calls := 0
+	router := newIdempotentRouter(repo, http.StatusCreated, &calls)
+
+	w := postOrder(router, strings.Repeat("k", maxIdempotencyKeyLength+1), `{"symbol": "AAPL"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotencyRequestHash(t *testing.T) {
+	post, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+	batch, _ := http.NewRequest(http.MethodPost, "/orders/batch", nil)
+
+	assert.Equal(t, requestHash(post, "", []byte(`{"a":1}`)), requestHash(post, "", []byte(`{"a":1}`)))
+	assert.NotEqual(t, requestHash(post, "", []byte(`{"a":1}`)), requestHash(post, "", []byte(`{"a":2}`)))
+	assert.NotEqual(t, requestHash(post, "", []byte(`{"a":1}`)), requestHash(batch, "", []byte(`{"a":1}`)))
+	assert.NotEqual(t, requestHash(post, "api-key:1", []byte(`{"a":1}`)), requestHash(post, "api-key:2", []byte(`{"a":1}`)))
+	assert.Len(t, requestHash(post, "", nil), 64)
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/logging"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/gin-gonic/gin"
+)
+
+// quietRoutes are polled constantly, so their requests are logged at debug level
+var quietRoutes = map[string]bool{
+	"/metrics": true,
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+// Logger returns middleware that writes one structured line per request,
+// at error level for 5xx responses and warn level for 4xx
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case quietRoutes[c.FullPath()]:
+			level = slog.LevelDebug
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if principal := PrincipalFrom(c); principal != nil {
+			attrs = append(attrs, slog.String("principal", principal.Subject))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "Request handled", attrs...)
+	}
+}
+
+// Recovery returns middleware that logs a panicking handler and answers 500
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "Handler panicked", slog.Any("panic", err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(c, "Internal server error"))
+	})
+}
+
+// errorResponse builds an error body tagged with the ID of the request it answers
+func errorResponse(c *gin.Context, message string) models.ErrorResponse {
+	return models.ErrorResponse{
+		Error:     message,
+		RequestID: logging.RequestID(c.Request.Context()),
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Javlopez/go-api/pkg/logging"
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// captureLogs sends the default logger to a buffer for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var out bytes.Buffer
+	logger, err := logging.New(&out, "debug", logging.FormatJSON)
+	if err != nil {
+		t.Fatalf("Error creating logger: %v", err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &out
+}
+
+func TestLoggerWritesRequestLine(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+	out := captureLogs(t)
+
+	// Setup Gin router
+	router := gin.New()
+	router.Use(RequestID(), Logger())
+	router.GET("/api/v1/orders/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	// Perform request
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/7", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert
+	var line map[string]any
+	if assert.NoError(t, json.Unmarshal(out.Bytes(), &line)) {
+		assert.Equal(t, "WARN", line["level"])
+		assert.Equal(t, "req-42", line["request_id"])
+		assert.Equal(t, "/api/v1/orders/7", line["path"])
+		assert.Equal(t, "/api/v1/orders/:id", line["route"])
+		assert.Equal(t, float64(http.StatusNotFound), line["status"])
+	}
+}
+
+func TestRecoveryAnswersInternalError(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+	out := captureLogs(t)
+
+	// Setup Gin router
+	router := gin.New()
+	router.Use(RequestID(), Recovery())
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	// Perform request
+	req, _ := http.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-43")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert: the caller gets a 500 it can quote and the panic is logged
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var response models.ErrorResponse
+	if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response)) {
+		assert.Equal(t, "req-43", response.RequestID)
+	}
+	assert.Contains(t, out.String(), `"panic":"boom"`)
+	assert.Contains(t, out.String(), `"request_id":"req-43"`)
+}
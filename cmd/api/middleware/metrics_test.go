@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Javlopez/go-api/pkg/metrics"
+)
+
+func TestMetricsLabelsRouteTemplate(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Setup Gin router
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/api/v1/orders/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	matched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/api/v1/orders/:id", "404")
+	unmatched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")
+	matchedBefore, unmatchedBefore := testutil.ToFloat64(matched), testutil.ToFloat64(unmatched)
+
+	// Perform requests to two orders and an unknown path
+	for _, path := range []string{"/api/v1/orders/1", "/api/v1/orders/2", "/unknown/path"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Assert: orders share the route template and the unknown path is not a label of its own
+	assert.Equal(t, matchedBefore+2, testutil.ToFloat64(matched))
+	assert.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Javlopez/go-api/pkg/logging"
+)
+
+func TestRequestID(t *testing.T) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		header  string
+		keepsID bool
+	}{
+		{name: "Caller ID", header: "client-req-1", keepsID: true},
+		{name: "Missing ID", header: "", keepsID: false},
+		{name: "ID with spaces", header: "bad id", keepsID: false},
+		{name: "ID too long", header: strings.Repeat("a", maxRequestIDLength+1), keepsID: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup Gin router
+			var seen string
+			router := gin.New()
+			router.Use(RequestID())
+			router.GET("/ping", func(c *gin.Context) {
+				seen = logging.RequestID(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			// Perform request
+			req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert: the handler sees the ID echoed on the response
+			id := w.Header().Get(RequestIDHeader)
+			assert.Equal(t, id, seen)
+			if tt.keepsID {
+				assert.Equal(t, tt.header, id)
+			} else {
+				assert.Len(t, id, 32)
+				assert.NotEqual(t, tt.header, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/apikey"
+	"github.com/Javlopez/go-api/pkg/repositories/nonce"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// SignatureKeyIDHeader names the API key whose signing secret signed the request
+	SignatureKeyIDHeader = "X-Signature-Key-Id"
+	// SignatureTimestampHeader carries the Unix time, in seconds, the request was signed at
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	// SignatureNonceHeader carries a value the client never reuses with the same key
+	SignatureNonceHeader = "X-Signature-Nonce"
+	// SignatureHeader carries the hex HMAC-SHA256 of the request
+	SignatureHeader = "X-Signature"
+
+	// maxNonceLength matches the used_nonces.nonce column
+	maxNonceLength = 64
+	// minNonceLength keeps nonces from being guessed or exhausted
+	minNonceLength = 8
+)
+
+// SigningMode says which requests must be signed
+type SigningMode string
+
+const (
+	// SigningOff ignores signature headers
+	SigningOff SigningMode = "off"
+	// SigningOptional verifies signed requests and lets unsigned ones through
+	SigningOptional SigningMode = "optional"
+	// SigningRequired also rejects unsigned requests made with a stored API
+	// key or, unless explicitly allowed, with ADMIN_API_KEY
+	SigningRequired SigningMode = "required"
+)
+
+// errSignature is the reason a signed request is rejected
+type errSignature string
+
+func (e errSignature) Error() string { return string(e) }
+
+// Signatures protects requests against tampering and replay. A signed
+// request carries the ID of an API key, a timestamp, a nonce and an
+// HMAC-SHA256, keyed with the API key's signing secret, of its timestamp,
+// nonce, method, path, query and body. Requests signed outside the allowed
+// clock skew, or with a nonce the key has already used, are rejected.
+//
+// ADMIN_API_KEY has no signing secret and cannot sign, so in SigningRequired
+// mode its requests are refused unless allowUnsignedAdmin exempts it. JWT
+// callers cannot sign either; their tokens are short-lived and signed by the
+// identity provider, so they are always exempt.
+type Signatures struct {
+	keys               apikey.APIKeyRepository
+	nonces             nonce.NonceRepository
+	mode               SigningMode
+	skew               time.Duration
+	allowUnsignedAdmin bool
+	now                func() time.Time
+}
+
+// NewSignatures creates signature middleware accepting timestamps up to skew
+// away from the server's clock. allowUnsignedAdmin lets unsigned
+// ADMIN_API_KEY requests through in SigningRequired mode.
+func NewSignatures(keys apikey.APIKeyRepository, nonces nonce.NonceRepository, mode SigningMode, skew time.Duration, allowUnsignedAdmin bool) *Signatures {
+	return &Signatures{
+		keys:               keys,
+		nonces:             nonces,
+		mode:               mode,
+		skew:               skew,
+		allowUnsignedAdmin: allowUnsignedAdmin,
+		now:                time.Now,
+	}
+}
+
+// Handler returns the Gin middleware. It runs after authentication: a
+// request authenticated with a stored API key must be signed with that key.
+// Rejected requests get 401.
+func (m *Signatures) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.mode == SigningOff {
+			c.Next()
+			return
+		}
+
+		if c.GetHeader(SignatureHeader) == "" {
+			if m.mode == SigningRequired && m.mustSign(PrincipalFrom(c)) {
+				unauthorized(c, "Request must be signed")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		err := m.verify(c)
+		var rejected errSignature
+		if errors.As(err, &rejected) {
+			unauthorized(c, rejected.Error())
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to verify signature", slog.Any("error", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(c, "Failed to verify signature"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// mustSign reports whether an unsigned request by principal is refused in
+// SigningRequired mode
+func (m *Signatures) mustSign(principal *models.Principal) bool {
+	if principal == nil {
+		return false
+	}
+	if principal.AdminKey {
+		return !m.allowUnsignedAdmin
+	}
+	return principal.APIKeyID != 0
+}
+
+// verify checks the request's signature and uses up its nonce, returning an
+// errSignature for requests to reject
+func (m *Signatures) verify(c *gin.Context) error {
+	keyID, err := strconv.ParseInt(c.GetHeader(SignatureKeyIDHeader), 10, 64)
+	if err != nil {
+		return errSignature(SignatureKeyIDHeader + " must be an API key ID")
+	}
+	if principal := PrincipalFrom(c); principal != nil && principal.APIKeyID != 0 && principal.APIKeyID != keyID {
+		return errSignature("Request must be signed with the API key it is authenticated with")
+	}
+
+	timestamp := c.GetHeader(SignatureTimestampHeader)
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errSignature(SignatureTimestampHeader + " must be a Unix time in seconds")
+	}
+	now := m.now()
+	signed := time.Unix(signedAt, 0)
+	if signed.Before(now.Add(-m.skew)) || signed.After(now.Add(m.skew)) {
+		return errSignature(fmt.Sprintf("Request was signed more than %s away from the server's time", m.skew))
+	}
+
+	nonceValue := c.GetHeader(SignatureNonceHeader)
+	if len(nonceValue) < minNonceLength || len(nonceValue) > maxNonceLength || !validRequestID(nonceValue) {
+		return errSignature(fmt.Sprintf("%s must be %d to %d printable characters", SignatureNonceHeader, minNonceLength, maxNonceLength))
+	}
+
+	secret, err := m.keys.GetSigningSecret(c.Request.Context(), keyID)
+	if errors.Is(err, apikey.ErrAPIKeyNotFound) {
+		return errSignature("Unknown signing key")
+	}
+	if err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return errSignature("Failed to read request body")
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	expected := Sign(secret, timestamp, nonceValue, c.Request, body)
+	provided, err := hex.DecodeString(c.GetHeader(SignatureHeader))
+	if err != nil || !hmac.Equal(provided, expected) {
+		return errSignature("Invalid signature")
+	}
+
+	// Only a genuine signature uses up its nonce, until it could no longer be accepted
+	err = m.nonces.Use(c.Request.Context(), keyID, nonceValue, now.UTC(), signed.Add(m.skew).UTC())
+	if errors.Is(err, nonce.ErrNonceUsed) {
+		return errSignature("Nonce was already used")
+	}
+	return err
+}
+
+// Run purges expired nonces immediately and then every interval, until ctx is done
+func (m *Signatures) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.nonces.Purge(ctx, m.now().UTC()); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to purge nonces", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sign returns the HMAC-SHA256, keyed with secret, of the lines
+//
+//	timestamp
+//	nonce
+//	method
+//	path
+//	query, as sent
+//	hex SHA-256 of the body
+func Sign(secret, timestamp, nonce string, r *http.Request, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	io.WriteString(mac, timestamp+"\n"+nonce+"\n"+r.Method+"\n"+r.URL.Path+"\n"+r.URL.RawQuery+"\n"+hex.EncodeToString(bodyHash[:]))
+	return mac.Sum(nil)
+}
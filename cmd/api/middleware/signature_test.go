@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/apikey"
+	"github.com/Javlopez/go-api/pkg/repositories/nonce"
+)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository interface
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetAll(ctx context.Context) ([]models.APIKey, error) {
+	args := m.Called(ctx)
+	keys, _ := args.Get(0).([]models.APIKey)
+	return keys, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	key, _ := args.Get(0).(*models.APIKey)
+	return key, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetSigningSecret(ctx context.Context, id int64) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Rotate(ctx context.Context, id int64, prefix, keyHash, signingSecret string) (*models.APIKey, error) {
+	args := m.Called(ctx, id, prefix, keyHash, signingSecret)
+	key, _ := args.Get(0).(*models.APIKey)
+	return key, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+const testSigningSecret = "signing-secret-7"
+
+// newSignedRouter serves POST /orders to the caller principal through the
+// signature middleware in mode, echoing the body the handler reads
+func newSignedRouter(mode SigningMode, principal *models.Principal) *gin.Engine {
+	return newSignedRouterAllowingAdmin(mode, principal, false)
+}
+
+// newSignedRouterAllowingAdmin is newSignedRouter with unsigned ADMIN_API_KEY
+// requests allowed as allowUnsignedAdmin says
+func newSignedRouterAllowingAdmin(mode SigningMode, principal *models.Principal, allowUnsignedAdmin bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	keys := new(MockAPIKeyRepository)
+	keys.On("GetSigningSecret", mock.Anything, int64(7)).Return(testSigningSecret, nil)
+	keys.On("GetSigningSecret", mock.Anything, mock.Anything).Return("", apikey.ErrAPIKeyNotFound)
+
+	signatures := NewSignatures(keys, nonce.NewMemoryNonceRepository(), mode, 5*time.Minute, allowUnsignedAdmin)
+	signatures.now = func() time.Time { return requestedAt }
+
+	router := gin.New()
+	router.POST("/orders", func(c *gin.Context) {
+		if principal != nil {
+			c.Set(PrincipalKey, principal)
+		}
+	}, signatures.Handler(), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	return router
+}
+
+// signedRequest builds POST /orders?mode=best_effort signed by keyID at signedAt
+func signedRequest(keyID, secret string, signedAt time.Time, nonceValue, body string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, "/orders?mode=best_effort", bytes.NewBufferString(body))
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	req.Header.Set(SignatureKeyIDHeader, keyID)
+	req.Header.Set(SignatureTimestampHeader, timestamp)
+	req.Header.Set(SignatureNonceHeader, nonceValue)
+	req.Header.Set(SignatureHeader, hex.EncodeToString(Sign(secret, timestamp, nonceValue, req, []byte(body))))
+	return req
+}
+
+func TestSignaturesAcceptSignedRequestOnce(t *testing.T) {
+	router := newSignedRouter(SigningRequired, &models.Principal{Subject: "api-key:7", APIKeyID: 7})
+
+	// Perform request
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest("7", testSigningSecret, requestedAt.Add(-time.Minute), "nonce-0001", `{"symbol": "AAPL"}`))
+
+	// Assert: the handler still reads the body
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"symbol": "AAPL"}`, w.Body.String())
+
+	// Perform the same request again
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest("7", testSigningSecret, requestedAt.Add(-time.Minute), "nonce-0001", `{"symbol": "AAPL"}`))
+
+	// Assert: the replay is rejected
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Nonce was already used")
+}
+
+func TestSignaturesRejectRequests(t *testing.T) {
+	tampered := signedRequest("7", testSigningSecret, requestedAt, "nonce-0002", `{"quantity": 10}`)
+	tampered.Body = io.NopCloser(bytes.NewBufferString(`{"quantity": 1000}`))
+
+	requery := signedRequest("7", testSigningSecret, requestedAt, "nonce-0003", `{}`)
+	requery.URL.RawQuery = "mode=all_or_nothing"
+
+	testCases := []struct {
+		name      string
+		principal *models.Principal
+		req       *http.Request
+	}{
+		{name: "Tampered body", req: tampered},
+		{name: "Tampered query", req: requery},
+		{name: "Wrong secret", req: signedRequest("7", "guessed", requestedAt, "nonce-0004", `{}`)},
+		{name: "Unknown key", req: signedRequest("8", testSigningSecret, requestedAt, "nonce-0005", `{}`)},
+		{name: "Stale timestamp", req: signedRequest("7", testSigningSecret, requestedAt.Add(-6*time.Minute), "nonce-0006", `{}`)},
+		{name: "Future timestamp", req: signedRequest("7", testSigningSecret, requestedAt.Add(6*time.Minute), "nonce-0007", `{}`)},
+		{name: "Short nonce", req: signedRequest("7", testSigningSecret, requestedAt, "n1", `{}`)},
+		{name: "Another caller's key", principal: &models.Principal{Subject: "api-key:9", APIKeyID: 9}, req: signedRequest("7", testSigningSecret, requestedAt, "nonce-0008", `{}`)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := newSignedRouter(SigningOptional, tc.principal)
+
+			// Perform request
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tc.req)
+
+			// Assert
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
+
+func TestSignaturesModes(t *testing.T) {
+	apiKeyCaller := &models.Principal{Subject: "api-key:7", APIKeyID: 7}
+	userCaller := &models.Principal{Subject: "user:jane"}
+	adminCaller := &models.Principal{Subject: "api-key:admin", AdminKey: true, Scopes: models.Scopes{models.ScopeAdmin}}
+
+	testCases := []struct {
+		name         string
+		mode         SigningMode
+		principal    *models.Principal
+		allowAdmin   bool
+		signature    string
+		expectedCode int
+	}{
+		{name: "Optional lets unsigned requests through", mode: SigningOptional, principal: apiKeyCaller, expectedCode: http.StatusOK},
+		{name: "Required rejects unsigned API key requests", mode: SigningRequired, principal: apiKeyCaller, expectedCode: http.StatusUnauthorized},
+		{name: "Required lets unsigned token requests through", mode: SigningRequired, principal: userCaller, expectedCode: http.StatusOK},
+		{name: "Off ignores signatures", mode: SigningOff, principal: apiKeyCaller, signature: "00", expectedCode: http.StatusOK},
+		{name: "Required rejects unsigned admin key requests", mode: SigningRequired, principal: adminCaller, expectedCode: http.StatusUnauthorized},
+		{name: "Required lets unsigned admin key requests through when allowed", mode: SigningRequired, principal: adminCaller, allowAdmin: true, expectedCode: http.StatusOK},
+		{name: "Optional lets unsigned admin key requests through", mode: SigningOptional, principal: adminCaller, expectedCode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := newSignedRouterAllowingAdmin(tc.mode, tc.principal, tc.allowAdmin)
+
+			// Perform request
+			req, _ := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(`{}`))
+			if tc.signature != "" {
+				req.Header.Set(SignatureHeader, tc.signature)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
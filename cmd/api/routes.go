@@ -1,24 +1,57 @@
 package api
 
 import (
+	"net/http"
+
 	"github.com/Javlopez/go-api/cmd/api/handlers"
+	"github.com/Javlopez/go-api/cmd/api/middleware"
 	_ "github.com/Javlopez/go-api/docs"
+	"github.com/Javlopez/go-api/pkg/auth"
+	"github.com/Javlopez/go-api/pkg/matching"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/account"
+	"github.com/Javlopez/go-api/pkg/repositories/apikey"
+	"github.com/Javlopez/go-api/pkg/repositories/instrument"
 	"github.com/Javlopez/go-api/pkg/repositories/order"
+	"github.com/Javlopez/go-api/pkg/repositories/trade"
+	"github.com/Javlopez/go-api/pkg/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // SetupRouter configures the Gin router
-func SetupRouter(orderRepo order.OrderRepository) *gin.Engine {
-	router := gin.Default()
+func SetupRouter(orderRepo order.OrderRepository, tradeRepo trade.TradeRepository, instrumentRepo instrument.InstrumentRepository, accountRepo account.AccountRepository, apiKeyRepo apikey.APIKeyRepository, engine *matching.Engine, scales models.PriceScales, authenticator auth.Authenticator, signatures *middleware.Signatures, idempotent *middleware.Idempotency, health *handlers.HealthHandler) *gin.Engine {
+	router := gin.New()
+
+	// Tag every request with an ID, echoed in X-Request-ID
+	router.Use(middleware.RequestID())
+
+	// Trace every request except scrapes and probes, continuing the caller's
+	// trace from its traceparent header
+	router.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		switch r.URL.Path {
+		case "/metrics", "/healthz", "/readyz":
+			return false
+		}
+		return true
+	})))
+
+	// Log every request as a structured line and turn panics into 500s
+	router.Use(middleware.Logger(), middleware.Recovery())
+
+	// Count and time every request
+	router.Use(middleware.Metrics())
 
 	// Set up CORS
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, X-Signature-Key-Id, X-Signature-Timestamp, X-Signature-Nonce, X-Signature, accept, origin, Cache-Control, X-Requested-With, Idempotency-Key, X-Request-ID, traceparent, tracestate")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Idempotent-Replayed, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -28,15 +61,69 @@ func SetupRouter(orderRepo order.OrderRepository) *gin.Engine {
 		c.Next()
 	})
 
-	// Initialize API group
-	api := router.Group("/api/v1")
+	// Prometheus scrape endpoint
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// Health probes
+	router.GET("/healthz", health.Liveness)
+	router.GET("/readyz", health.Readiness)
+
+	// Initialize API group; every route needs a bearer token or API key, and
+	// signed requests are checked for tampering and replay
+	api := router.Group("/api/v1", middleware.Authenticate(authenticator), signatures.Handler())
 	{
 		// Initialize handlers
-		orderHandler := handlers.NewOrderHandler(orderRepo)
+		orderHandler := handlers.NewOrderHandler(orderRepo, instrumentRepo, engine, scales)
+		tradeHandler := handlers.NewTradeHandler(tradeRepo)
+		instrumentHandler := handlers.NewInstrumentHandler(instrumentRepo)
+		accountHandler := handlers.NewAccountHandler(accountRepo)
+		apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyRepo)
+
+		// Scopes, checked before Idempotency-Keys are reserved
+		read := middleware.RequireScope(models.ScopeOrdersRead)
+		write := middleware.RequireScope(models.ScopeOrdersWrite)
+
+		// Order routes only see the caller's account
+		orders := api.Group("/orders", middleware.RequireAccount())
+		orders.POST("", write, idempotent.Handler(), orderHandler.CreateOrder)
+		orders.POST("/batch", write, idempotent.Handler(), orderHandler.CreateOrderBatch)
+		orders.GET("", read, orderHandler.GetOrders)
+		orders.GET("/:id", read, orderHandler.GetOrder)
+		orders.GET("/by-client-id/:clOrdId", read, orderHandler.GetOrderByClientID)
+		orders.PATCH("/:id", write, orderHandler.AmendOrder)
+		orders.DELETE("/:id", write, orderHandler.CancelOrder)
+		orders.GET("/:id/revisions", read, orderHandler.GetOrderRevisions)
+		orders.GET("/:id/triggers", read, orderHandler.GetOrderTriggers)
+
+		// Trade routes only see trades of the caller's account's orders
+		trades := api.Group("/trades", middleware.RequireAccount())
+		trades.GET("", read, tradeHandler.GetTrades)
+		trades.GET("/:id", read, tradeHandler.GetTrade)
+
+		// Administration routes
+		admin := api.Group("/admin", middleware.RequireScope(models.ScopeAdmin))
+
+		// Instrument master routes
+		admin.POST("/instruments", instrumentHandler.CreateInstrument)
+		admin.GET("/instruments", instrumentHandler.GetInstruments)
+		admin.GET("/instruments/:symbol", instrumentHandler.GetInstrument)
+		admin.PUT("/instruments/:symbol", instrumentHandler.UpdateInstrument)
+		admin.DELETE("/instruments/:symbol", instrumentHandler.DeleteInstrument)
+
+		// Account routes, and orders and trades across accounts
+		admin.POST("/accounts", accountHandler.CreateAccount)
+		admin.GET("/accounts", accountHandler.GetAccounts)
+		admin.GET("/accounts/:id", accountHandler.GetAccount)
+		admin.GET("/orders", orderHandler.AdminGetOrders)
+		admin.GET("/orders/:id", orderHandler.AdminGetOrder)
+		admin.GET("/trades", tradeHandler.AdminGetTrades)
+		admin.GET("/trades/:id", tradeHandler.AdminGetTrade)
 
-		// Order routes
-		api.POST("/orders", orderHandler.CreateOrder)
-		api.GET("/orders", orderHandler.GetOrders)
+		// API key routes
+		admin.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+		admin.GET("/api-keys", apiKeyHandler.GetAPIKeys)
+		admin.POST("/api-keys/:id/rotate", apiKeyHandler.RotateAPIKey)
+		admin.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
 	}
 
 	url := ginSwagger.URL("/docs/doc.json") // The URL pointing to API definition
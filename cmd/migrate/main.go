@@ -7,31 +7,43 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/jmoiron/sqlx"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
 
 	"github.com/Javlopez/go-api/pkg/database"
+	"github.com/Javlopez/go-api/pkg/logging"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/joho/godotenv"
 )
 
 func main() {
 	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: No .env file found")
+	envErr := godotenv.Load()
+
+	// Log at the same level and in the same format as the API
+	logger, err := logging.FromEnv(os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid logging configuration: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+	if envErr != nil {
+		slog.Warn("No .env file found")
 	}
 
 	// Initialize DB
 	db := database.New(database.NewConfig())
 	dbConn, err := db.Connect()
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		slog.Error("Failed to connect to database", slog.Any("error", err))
+		os.Exit(1)
 	}
 	// Run migrations
 	if err := migrateDB(dbConn); err != nil {
-		log.Fatalf("Migration failed: %v", err)
+		slog.Error("Migration failed", slog.Any("error", err))
+		os.Exit(1)
 	}
 
 	os.Exit(0)
@@ -65,6 +77,6 @@ func migrateDB(dbConn *sqlx.DB) error {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	log.Println("Migrations completed successfully")
+	slog.Info("Migrations completed successfully")
 	return nil
 }
@@ -0,0 +1,5 @@
+-- migrations/000003_add_order_status.down.sql
+-- Down: Remove lifecycle status from orders
+DROP INDEX IF EXISTS idx_orders_status;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_status;
+ALTER TABLE orders DROP COLUMN IF EXISTS status;
@@ -0,0 +1,6 @@
+-- migrations/000003_add_order_status.up.sql
+-- Up: Add lifecycle status to orders
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'NEW';
+ALTER TABLE orders ADD CONSTRAINT chk_orders_status
+    CHECK (status IN ('NEW', 'PARTIALLY_FILLED', 'FILLED', 'CANCELLED', 'REJECTED', 'EXPIRED'));
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status);
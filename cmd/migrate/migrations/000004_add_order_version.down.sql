@@ -0,0 +1,3 @@
+-- migrations/000004_add_order_version.down.sql
+-- Down: Remove optimistic concurrency version from orders
+ALTER TABLE orders DROP COLUMN IF EXISTS version;
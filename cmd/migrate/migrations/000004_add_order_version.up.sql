@@ -0,0 +1,3 @@
+-- migrations/000004_add_order_version.up.sql
+-- Up: Add optimistic concurrency version to orders
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+-- migrations/000005_add_order_revisions.down.sql
+-- Down: Remove order amendment tracking
+DROP TABLE IF EXISTS order_revisions;
+ALTER TABLE orders DROP COLUMN IF EXISTS updated_at;
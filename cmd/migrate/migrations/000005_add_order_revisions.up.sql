@@ -0,0 +1,18 @@
+-- migrations/000005_add_order_revisions.up.sql
+-- Up: Track order amendments (cancel/replace)
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+UPDATE orders SET updated_at = created_at WHERE updated_at IS NULL;
+ALTER TABLE orders ALTER COLUMN updated_at SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN updated_at SET DEFAULT NOW();
+
+CREATE TABLE IF NOT EXISTS order_revisions (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    previous_price DECIMAL(12, 4) NOT NULL,
+    previous_quantity INTEGER NOT NULL,
+    price DECIMAL(12, 4) NOT NULL,
+    quantity INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (order_id, version)
+    );
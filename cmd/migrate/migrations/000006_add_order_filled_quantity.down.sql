@@ -0,0 +1,4 @@
+-- migrations/000006_add_order_filled_quantity.down.sql
+-- Down: Remove order fill tracking
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_filled_quantity;
+ALTER TABLE orders DROP COLUMN IF EXISTS filled_quantity;
@@ -0,0 +1,5 @@
+-- migrations/000006_add_order_filled_quantity.up.sql
+-- Up: Track how much of each order has executed
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS filled_quantity INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD CONSTRAINT chk_orders_filled_quantity
+    CHECK (filled_quantity >= 0 AND filled_quantity <= quantity);
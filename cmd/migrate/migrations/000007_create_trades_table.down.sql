@@ -0,0 +1,3 @@
+-- migrations/000007_create_trades_table.down.sql
+-- Down: Drop trades table
+DROP TABLE IF EXISTS trades;
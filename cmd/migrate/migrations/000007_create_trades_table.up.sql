@@ -0,0 +1,16 @@
+-- migrations/000007_create_trades_table.up.sql
+-- Up: Create trades table for executions
+CREATE TABLE IF NOT EXISTS trades (
+    id SERIAL PRIMARY KEY,
+    buy_order_id INTEGER NOT NULL REFERENCES orders(id),
+    sell_order_id INTEGER NOT NULL REFERENCES orders(id),
+    symbol VARCHAR(20) NOT NULL,
+    price DECIMAL(12, 4) NOT NULL,
+    quantity INTEGER NOT NULL,
+    executed_at TIMESTAMP NOT NULL DEFAULT NOW()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_trades_symbol_executed_at ON trades(symbol, executed_at);
+CREATE INDEX IF NOT EXISTS idx_trades_executed_at ON trades(executed_at);
+CREATE INDEX IF NOT EXISTS idx_trades_buy_order_id ON trades(buy_order_id);
+CREATE INDEX IF NOT EXISTS idx_trades_sell_order_id ON trades(sell_order_id);
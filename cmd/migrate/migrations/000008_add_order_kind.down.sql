@@ -0,0 +1,4 @@
+-- migrations/000008_add_order_kind.down.sql
+-- Down: Remove order kind
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_kind_price;
+ALTER TABLE orders DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,5 @@
+-- migrations/000008_add_order_kind.up.sql
+-- Up: Distinguish market and limit orders; market orders carry no price
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS kind VARCHAR(10) NOT NULL DEFAULT 'LIMIT';
+ALTER TABLE orders ADD CONSTRAINT chk_orders_kind_price
+    CHECK ((kind = 'LIMIT' AND price > 0) OR (kind = 'MARKET' AND price = 0));
@@ -0,0 +1,7 @@
+-- migrations/000009_add_order_time_in_force.down.sql
+-- Down: Remove time-in-force and expiry
+DROP INDEX IF EXISTS idx_orders_expires_at;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_expires_at;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_time_in_force;
+ALTER TABLE orders DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS time_in_force;
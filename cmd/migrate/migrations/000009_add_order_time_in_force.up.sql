@@ -0,0 +1,12 @@
+-- migrations/000009_add_order_time_in_force.up.sql
+-- Up: Add time-in-force; DAY and GTD orders carry the time they expire
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS time_in_force VARCHAR(3) NOT NULL DEFAULT 'GTC';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+ALTER TABLE orders ADD CONSTRAINT chk_orders_time_in_force
+    CHECK (time_in_force IN ('DAY', 'GTC', 'IOC', 'FOK', 'GTD'));
+ALTER TABLE orders ADD CONSTRAINT chk_orders_expires_at
+    CHECK ((time_in_force IN ('DAY', 'GTD')) = (expires_at IS NOT NULL));
+
+-- The expiry worker only looks at live orders with an expiry
+CREATE INDEX IF NOT EXISTS idx_orders_expires_at ON orders(expires_at)
+    WHERE status IN ('NEW', 'PARTIALLY_FILLED') AND expires_at IS NOT NULL;
@@ -0,0 +1,9 @@
+-- migrations/000010_add_stop_orders.down.sql
+-- Down: Remove stop orders and their trigger audit
+DROP TABLE IF EXISTS order_triggers;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_trigger_price;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_kind_price;
+ALTER TABLE orders ADD CONSTRAINT chk_orders_kind_price
+    CHECK ((kind = 'LIMIT' AND price > 0) OR (kind = 'MARKET' AND price = 0));
+ALTER TABLE orders DROP COLUMN IF EXISTS triggered_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS trigger_price;
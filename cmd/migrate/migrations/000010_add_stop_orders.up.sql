@@ -0,0 +1,24 @@
+-- migrations/000010_add_stop_orders.up.sql
+-- Up: Add stop and stop-limit orders and an audit of their triggers
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS trigger_price DECIMAL(12, 4);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS triggered_at TIMESTAMP;
+
+-- Stops carry no price; stop-limits keep the limit they convert to
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_kind_price;
+ALTER TABLE orders ADD CONSTRAINT chk_orders_kind_price
+    CHECK ((kind IN ('LIMIT', 'STOP_LIMIT') AND price > 0) OR (kind IN ('MARKET', 'STOP') AND price = 0));
+ALTER TABLE orders ADD CONSTRAINT chk_orders_trigger_price
+    CHECK (kind NOT IN ('STOP', 'STOP_LIMIT') OR trigger_price > 0);
+
+CREATE TABLE IF NOT EXISTS order_triggers (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL,
+    activated_kind VARCHAR(10) NOT NULL,
+    trigger_price DECIMAL(12, 4) NOT NULL,
+    last_price DECIMAL(12, 4) NOT NULL,
+    reason TEXT NOT NULL,
+    triggered_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_triggers_order_id ON order_triggers(order_id);
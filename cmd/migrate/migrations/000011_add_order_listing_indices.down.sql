@@ -0,0 +1,6 @@
+-- migrations/000011_add_order_listing_indices.down.sql
+-- Down: Restore the single-column listing indices
+CREATE INDEX IF NOT EXISTS idx_orders_symbol ON orders(symbol);
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at);
+DROP INDEX IF EXISTS idx_orders_symbol_created_at_id;
+DROP INDEX IF EXISTS idx_orders_created_at_id;
@@ -0,0 +1,8 @@
+-- migrations/000011_add_order_listing_indices.up.sql
+-- Up: Support keyset pagination of order listings on (created_at, id)
+CREATE INDEX IF NOT EXISTS idx_orders_created_at_id ON orders(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_symbol_created_at_id ON orders(symbol, created_at, id);
+
+-- Superseded by the composite indices above
+DROP INDEX IF EXISTS idx_orders_created_at;
+DROP INDEX IF EXISTS idx_orders_symbol;
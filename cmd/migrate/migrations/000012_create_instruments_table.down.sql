@@ -0,0 +1,4 @@
+-- migrations/000012_create_instruments_table.down.sql
+-- Down: Drop the instrument master
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS fk_orders_instrument;
+DROP TABLE IF EXISTS instruments;
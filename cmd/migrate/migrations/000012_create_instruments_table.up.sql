@@ -0,0 +1,22 @@
+-- migrations/000012_create_instruments_table.up.sql
+-- Up: Create the instrument master and require orders to reference it
+CREATE TABLE IF NOT EXISTS instruments (
+    symbol VARCHAR(20) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    currency CHAR(3) NOT NULL,
+    tick_size DECIMAL(12, 4) NOT NULL CHECK (tick_size > 0),
+    lot_size INTEGER NOT NULL CHECK (lot_size > 0),
+    min_quantity INTEGER NOT NULL CHECK (min_quantity > 0),
+    max_quantity INTEGER NOT NULL DEFAULT 0 CHECK (max_quantity = 0 OR max_quantity >= min_quantity),
+    tradable BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Existing symbols are listed with permissive rules so their orders stay valid
+INSERT INTO instruments (symbol, currency, tick_size, lot_size, min_quantity)
+SELECT DISTINCT symbol, 'USD', 0.0001, 1, 1 FROM orders
+ON CONFLICT (symbol) DO NOTHING;
+
+ALTER TABLE orders ADD CONSTRAINT fk_orders_instrument
+    FOREIGN KEY (symbol) REFERENCES instruments(symbol);
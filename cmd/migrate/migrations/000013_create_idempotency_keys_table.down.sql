@@ -0,0 +1,3 @@
+-- migrations/000013_create_idempotency_keys_table.down.sql
+-- Down: Drop idempotency keys
+DROP TABLE IF EXISTS idempotency_keys;
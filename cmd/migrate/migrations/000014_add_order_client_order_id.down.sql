@@ -0,0 +1,4 @@
+-- migrations/000014_add_order_client_order_id.down.sql
+-- Down: Remove client order IDs
+DROP INDEX IF EXISTS uq_orders_client_order_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS client_order_id;
@@ -0,0 +1,6 @@
+-- migrations/000014_add_order_client_order_id.up.sql
+-- Up: Add the client's own order ID, unique when present
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS client_order_id VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_orders_client_order_id ON orders(client_order_id)
+    WHERE client_order_id IS NOT NULL;
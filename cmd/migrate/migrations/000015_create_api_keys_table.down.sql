@@ -0,0 +1,3 @@
+-- migrations/000015_create_api_keys_table.down.sql
+-- Down: Drop API keys
+DROP TABLE IF EXISTS api_keys;
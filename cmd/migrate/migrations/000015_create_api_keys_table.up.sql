@@ -0,0 +1,12 @@
+-- migrations/000015_create_api_keys_table.up.sql
+-- Up: Create API keys, stored as SHA-256 hashes with their scopes
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL CHECK (cardinality(scopes) > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
@@ -0,0 +1,4 @@
+-- migrations/000016_add_request_signing.down.sql
+-- Down: Remove request signing
+DROP TABLE IF EXISTS used_nonces;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS signing_secret;
@@ -0,0 +1,12 @@
+-- migrations/000016_add_request_signing.up.sql
+-- Up: Give API keys a secret for signing requests and remember used nonces
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS signing_secret CHAR(64);
+
+CREATE TABLE IF NOT EXISTS used_nonces (
+    key_id INTEGER NOT NULL REFERENCES api_keys(id),
+    nonce VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (key_id, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS idx_used_nonces_expires_at ON used_nonces(expires_at);
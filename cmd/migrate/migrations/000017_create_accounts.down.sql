@@ -0,0 +1,10 @@
+-- migrations/000017_create_accounts.down.sql
+-- Down: Drop accounts; fails if two accounts share a client order ID
+DROP INDEX IF EXISTS idx_orders_account_id_created_at;
+DROP INDEX IF EXISTS uq_orders_client_order_id;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_orders_client_order_id ON orders(client_order_id)
+    WHERE client_order_id IS NOT NULL;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS account_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS account_id;
+DROP TABLE IF EXISTS accounts;
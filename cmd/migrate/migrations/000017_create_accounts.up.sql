@@ -0,0 +1,20 @@
+-- migrations/000017_create_accounts.up.sql
+-- Up: Create accounts and record the account that owns each order and API key.
+-- Orders placed before accounts existed belong to none and only admins see them.
+CREATE TABLE IF NOT EXISTS accounts (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS account_id VARCHAR(64)
+    CONSTRAINT fk_orders_account_id REFERENCES accounts(id);
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS account_id VARCHAR(64)
+    CONSTRAINT fk_api_keys_account_id REFERENCES accounts(id);
+
+-- Client order IDs only need to be unique within an account
+DROP INDEX IF EXISTS uq_orders_client_order_id;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_orders_client_order_id ON orders(account_id, client_order_id)
+    WHERE client_order_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_account_id_created_at ON orders(account_id, created_at);
@@ -0,0 +1,6 @@
+-- migrations/000018_scope_idempotency_keys.down.sql
+-- Down: Make idempotency keys global again; fails if two callers share a key
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (key);
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS subject;
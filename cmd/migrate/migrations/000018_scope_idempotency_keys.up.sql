@@ -0,0 +1,7 @@
+-- migrations/000018_scope_idempotency_keys.up.sql
+-- Up: Scope idempotency keys to the caller that sent them, so two callers
+-- can use the same key. Keys stored before this belong to no caller.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS subject VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (subject, key);
@@ -2,13 +2,39 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/Javlopez/go-api/cmd/api"
+	"github.com/Javlopez/go-api/cmd/api/handlers"
+	"github.com/Javlopez/go-api/cmd/api/middleware"
+	"github.com/Javlopez/go-api/pkg/auth"
 	"github.com/Javlopez/go-api/pkg/database"
+	"github.com/Javlopez/go-api/pkg/expiry"
+	"github.com/Javlopez/go-api/pkg/logging"
+	"github.com/Javlopez/go-api/pkg/matching"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/account"
+	"github.com/Javlopez/go-api/pkg/repositories/apikey"
+	"github.com/Javlopez/go-api/pkg/repositories/idempotency"
+	"github.com/Javlopez/go-api/pkg/repositories/instrument"
+	"github.com/Javlopez/go-api/pkg/repositories/nonce"
 	"github.com/Javlopez/go-api/pkg/repositories/order"
+	"github.com/Javlopez/go-api/pkg/repositories/trade"
+	"github.com/Javlopez/go-api/pkg/tracing"
+	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	"log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 // @title Trade Orders API
@@ -16,10 +42,35 @@ import (
 // @description A simple API for managing trade orders
 // @host localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description API key sent as "Bearer <key>"
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: No .env file found")
+	envErr := godotenv.Load()
+
+	// Log structured lines at LOG_LEVEL in LOG_FORMAT (json or text)
+	logger, err := logging.FromEnv(os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid logging configuration: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+	gin.DebugPrintFunc = func(format string, values ...interface{}) {
+		slog.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)))
+	}
+	if envErr != nil {
+		slog.Warn("No .env file found")
+	}
+
+	// Export traces as OTEL_TRACES_EXPORTER says: otlp, console or none
+	shutdownTracing, err := tracing.Setup(context.Background(), getEnv("OTEL_TRACES_EXPORTER", tracing.ExporterNone))
+	if err != nil {
+		fatal("Failed to set up tracing", slog.Any("error", err))
 	}
 
 	// Initialize config
@@ -31,28 +82,266 @@ func main() {
 	// Connect to database
 	dbConnection, err := db.Connect()
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", slog.Any("error", err))
+	}
+
+	// Bound every order query so a slow statement cannot hold a request open
+	queryTimeout, err := time.ParseDuration(getEnv("DB_QUERY_TIMEOUT", "5s"))
+	if err != nil || queryTimeout < 0 {
+		fatal("Invalid DB_QUERY_TIMEOUT", slog.String("value", os.Getenv("DB_QUERY_TIMEOUT")))
 	}
-	//defer db.Close()
 
 	// Initialize repository
-	orderRepo, err := order.NewOrderRepository(dbConnection)
+	orderRepo, err := order.NewOrderRepository(dbConnection, queryTimeout)
+	if err != nil {
+		fatal("Failed to connect to database", slog.Any("error", err))
+	}
+	orderRepo = order.NewInstrumentedRepository(orderRepo)
+
+	// Export connection pool statistics on /metrics
+	prometheus.MustRegister(collectors.NewDBStatsCollector(dbConnection.DB, cfg.DBName))
+
+	tradeRepo, err := trade.NewTradeRepository(dbConnection)
+	if err != nil {
+		fatal("Failed to connect to database", slog.Any("error", err))
+	}
+
+	instrumentRepo, err := instrument.NewInstrumentRepository(dbConnection)
+	if err != nil {
+		fatal("Failed to connect to database", slog.Any("error", err))
+	}
+
+	keyRepo, err := idempotency.NewKeyRepository(dbConnection)
+	if err != nil {
+		fatal("Failed to connect to database", slog.Any("error", err))
+	}
+
+	accountRepo, err := account.NewAccountRepository(dbConnection)
+	if err != nil {
+		fatal("Failed to connect to database", slog.Any("error", err))
+	}
+
+	apiKeyRepo, err := apikey.NewAPIKeyRepository(dbConnection)
+	if err != nil {
+		fatal("Failed to connect to database", slog.Any("error", err))
+	}
+
+	// Shut down on SIGINT or SIGTERM; background workers stop with workerCtx
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+
+	// Rebuild the order book from live orders
+	engine := matching.NewEngine()
+	openOrders, err := orderRepo.GetOpen(ctx)
+	if err != nil {
+		fatal("Failed to load open orders", slog.Any("error", err))
+	}
+	lastTrades, err := tradeRepo.GetLatest(ctx)
+	if err != nil {
+		fatal("Failed to load last trades", slog.Any("error", err))
+	}
+	engine.Load(openOrders, lastTrades)
+
+	// Expire DAY and GTD orders in the background
+	expiryInterval, err := time.ParseDuration(getEnv("ORDER_EXPIRY_INTERVAL", "10s"))
+	if err != nil {
+		fatal("Invalid ORDER_EXPIRY_INTERVAL", slog.Any("error", err))
+	}
+	expiryWorker := expiry.NewWorker(orderRepo, engine, expiryInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		expiryWorker.Run(workerCtx)
+	}()
+
+	// Per-symbol price precision, e.g. PRICE_SCALES=AAPL=2,EURUSD=4
+	scales, err := models.ParsePriceScales(os.Getenv("PRICE_SCALES"))
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Invalid PRICE_SCALES", slog.Any("error", err))
 	}
-	defer orderRepo.Close()
+
+	// Remember Idempotency-Keys for the retention window, purging expired ones hourly
+	keyRetention, err := time.ParseDuration(getEnv("IDEMPOTENCY_KEY_RETENTION", "24h"))
+	if err != nil || keyRetention <= 0 {
+		fatal("Invalid IDEMPOTENCY_KEY_RETENTION", slog.String("value", os.Getenv("IDEMPOTENCY_KEY_RETENTION")))
+	}
+	reservationTimeout, err := time.ParseDuration(getEnv("IDEMPOTENCY_RESERVATION_TIMEOUT", "1m"))
+	if err != nil || reservationTimeout <= 0 {
+		fatal("Invalid IDEMPOTENCY_RESERVATION_TIMEOUT", slog.String("value", os.Getenv("IDEMPOTENCY_RESERVATION_TIMEOUT")))
+	}
+	idempotent := middleware.NewIdempotency(keyRepo, keyRetention, reservationTimeout)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		idempotent.Run(workerCtx, time.Hour)
+	}()
+
+	// Give in-flight requests this long to finish once a shutdown starts
+	shutdownTimeout, err := time.ParseDuration(getEnv("SHUTDOWN_TIMEOUT", "10s"))
+	if err != nil || shutdownTimeout <= 0 {
+		fatal("Invalid SHUTDOWN_TIMEOUT", slog.String("value", os.Getenv("SHUTDOWN_TIMEOUT")))
+	}
+
+	// Authenticate callers as AUTH_MODE says: api_key, jwt or both, e.g. api_key,jwt
+	authenticator, err := newAuthenticator(getEnv("AUTH_MODE", authModeAPIKey), apiKeyRepo)
+	if err != nil {
+		fatal("Invalid authentication configuration", slog.Any("error", err))
+	}
+
+	// Verify signed requests as SIGNING_MODE says: off, optional or required
+	signingMode := middleware.SigningMode(getEnv("SIGNING_MODE", string(middleware.SigningOff)))
+	switch signingMode {
+	case middleware.SigningOff, middleware.SigningOptional, middleware.SigningRequired:
+	default:
+		fatal("Invalid SIGNING_MODE", slog.String("value", string(signingMode)))
+	}
+	signatureSkew, err := time.ParseDuration(getEnv("SIGNATURE_MAX_SKEW", "5m"))
+	if err != nil || signatureSkew <= 0 {
+		fatal("Invalid SIGNATURE_MAX_SKEW", slog.String("value", os.Getenv("SIGNATURE_MAX_SKEW")))
+	}
+	// ADMIN_API_KEY cannot sign, so required signing refuses it unless exempted
+	allowUnsignedAdmin, err := strconv.ParseBool(getEnv("SIGNING_ALLOW_UNSIGNED_ADMIN", "false"))
+	if err != nil {
+		fatal("Invalid SIGNING_ALLOW_UNSIGNED_ADMIN", slog.String("value", os.Getenv("SIGNING_ALLOW_UNSIGNED_ADMIN")))
+	}
+
+	// Remember nonces in Postgres, shared by every instance, or in memory
+	var nonceRepo nonce.NonceRepository
+	switch store := getEnv("NONCE_STORE", "postgres"); store {
+	case "postgres":
+		nonceRepo, err = nonce.NewNonceRepository(dbConnection)
+		if err != nil {
+			fatal("Failed to connect to database", slog.Any("error", err))
+		}
+	case "memory":
+		nonceRepo = nonce.NewMemoryNonceRepository()
+	default:
+		fatal("Invalid NONCE_STORE", slog.String("value", store))
+	}
+	signatures := middleware.NewSignatures(apiKeyRepo, nonceRepo, signingMode, signatureSkew, allowUnsignedAdmin)
+	if signingMode != middleware.SigningOff {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			signatures.Run(workerCtx, time.Minute)
+		}()
+	}
+
+	// Report readiness from the database and its migration version
+	health := handlers.NewHealthHandler(db, database.SchemaVersion)
 
 	// Initialize router
-	router := api.SetupRouter(orderRepo)
+	router := api.SetupRouter(orderRepo, tradeRepo, instrumentRepo, accountRepo, apiKeyRepo, engine, scales, authenticator, signatures, idempotent, health)
 
 	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	port := getEnv("PORT", "8080")
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server running", slog.String("port", port))
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	var failed bool
+	select {
+	case err := <-serveErr:
+		slog.Error("Failed to start server", slog.Any("error", err))
+		failed = true
+	case <-ctx.Done():
+		slog.Info("Shutting down")
+	}
+	// A second signal terminates immediately
+	stop()
+	health.Drain()
+
+	// Stop accepting connections and drain in-flight requests
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to drain connections", slog.Duration("timeout", shutdownTimeout), slog.Any("error", err))
+		failed = true
+	}
+
+	// Stop background workers, then close the connection pool they share
+	stopWorkers()
+	workers.Wait()
+	if err := orderRepo.Close(); err != nil {
+		slog.Error("Failed to close database", slog.Any("error", err))
+		failed = true
+	}
+
+	// Flush the spans still buffered for export
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Failed to flush traces", slog.Any("error", err))
+	}
+
+	if failed {
+		os.Exit(1)
 	}
+	slog.Info("Server stopped")
+}
+
+// Authentication modes accepted in AUTH_MODE
+const (
+	authModeAPIKey = "api_key"
+	authModeJWT    = "jwt"
+)
+
+// newAuthenticator builds the authenticators listed in mode. API keys are
+// looked up in apiKeyRepo, with ADMIN_API_KEY, if set, as an admin key for
+// creating the first keys. JWTs are verified against the JWKS at
+// JWT_JWKS_URL, a URL or file path, and must be issued by JWT_ISSUER for
+// JWT_AUDIENCE.
+func newAuthenticator(mode string, apiKeyRepo apikey.APIKeyRepository) (auth.Authenticator, error) {
+	var chain auth.Chain
+	for _, name := range strings.Split(mode, ",") {
+		switch strings.TrimSpace(name) {
+		case authModeAPIKey:
+			chain = append(chain, auth.NewAPIKeyAuthenticator(apiKeyRepo, os.Getenv("ADMIN_API_KEY")))
+		case authModeJWT:
+			source, issuer, audience := os.Getenv("JWT_JWKS_URL"), os.Getenv("JWT_ISSUER"), os.Getenv("JWT_AUDIENCE")
+			if source == "" || issuer == "" || audience == "" {
+				return nil, errors.New("jwt mode needs JWT_JWKS_URL, JWT_ISSUER and JWT_AUDIENCE")
+			}
+			refresh, err := time.ParseDuration(getEnv("JWT_JWKS_REFRESH", "1h"))
+			if err != nil || refresh <= 0 {
+				return nil, fmt.Errorf("invalid JWT_JWKS_REFRESH %q", os.Getenv("JWT_JWKS_REFRESH"))
+			}
+			chain = append(chain, auth.NewJWTAuthenticator(auth.NewJWKS(source, refresh), auth.JWTConfig{
+				Issuer:       issuer,
+				Audience:     audience,
+				AccountClaim: getEnv("JWT_ACCOUNT_CLAIM", "account_id"),
+				RolesClaim:   getEnv("JWT_ROLES_CLAIM", "roles"),
+				Leeway:       30 * time.Second,
+			}))
+		default:
+			return nil, fmt.Errorf("unknown AUTH_MODE %q, expected api_key, jwt or both", mode)
+		}
+	}
+	return chain, nil
+}
+
+// fatal logs msg with its attributes and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
 
-	fmt.Printf("Server running on port %s...\n", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+// getEnv returns the value of an environment variable, or defaultValue if unset
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
 	}
+	return defaultValue
 }
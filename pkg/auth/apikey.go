@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strconv"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/apikey"
+)
+
+// APIKeyAuthenticator identifies callers by the API keys stored in a repository
+type APIKeyAuthenticator struct {
+	repo     apikey.APIKeyRepository
+	adminKey string
+}
+
+// NewAPIKeyAuthenticator creates an authenticator that looks keys up in repo.
+// A non-empty adminKey is accepted as well, with the admin scope, so that the
+// first keys can be created.
+func NewAPIKeyAuthenticator(repo apikey.APIKeyRepository, adminKey string) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{repo: repo, adminKey: adminKey}
+}
+
+// Authenticate returns the principal holding key
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, key string) (*models.Principal, error) {
+	keyHash := apikey.Hash(key)
+	if a.adminKey != "" && subtle.ConstantTimeCompare([]byte(keyHash), []byte(apikey.Hash(a.adminKey))) == 1 {
+		return &models.Principal{Subject: "api-key:admin", Name: "ADMIN_API_KEY", AdminKey: true, Scopes: models.Scopes{models.ScopeAdmin}}, nil
+	}
+
+	stored, err := a.repo.GetByHash(ctx, keyHash)
+	if errors.Is(err, apikey.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidCredential
+	}
+	if err != nil {
+		return nil, err
+	}
+	principal := &models.Principal{
+		Subject:  "api-key:" + strconv.FormatInt(stored.ID, 10),
+		Name:     stored.Name,
+		APIKeyID: stored.ID,
+		Scopes:   stored.Scopes,
+	}
+	if stored.AccountID != nil {
+		principal.Account = *stored.AccountID
+	}
+	return principal, nil
+}
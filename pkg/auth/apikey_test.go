@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/apikey"
+)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository interface
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetAll(ctx context.Context) ([]models.APIKey, error) {
+	args := m.Called(ctx)
+	keys, _ := args.Get(0).([]models.APIKey)
+	return keys, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	key, _ := args.Get(0).(*models.APIKey)
+	return key, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetSigningSecret(ctx context.Context, id int64) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Rotate(ctx context.Context, id int64, prefix, keyHash, signingSecret string) (*models.APIKey, error) {
+	args := m.Called(ctx, id, prefix, keyHash, signingSecret)
+	key, _ := args.Get(0).(*models.APIKey)
+	return key, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	// Create mock repository holding a read-only key
+	repo := new(MockAPIKeyRepository)
+	account := "acme"
+	repo.On("GetByHash", mock.Anything, apikey.Hash("ak_reader")).
+		Return(&models.APIKey{ID: 7, Name: "reader", AccountID: &account, Scopes: models.Scopes{models.ScopeOrdersRead}}, nil)
+	repo.On("GetByHash", mock.Anything, apikey.Hash("ak_revoked")).Return(nil, apikey.ErrAPIKeyNotFound)
+	repo.On("GetByHash", mock.Anything, apikey.Hash("ak_broken")).Return(nil, assert.AnError)
+	authenticator := NewAPIKeyAuthenticator(repo, "")
+
+	// A stored key maps to its principal
+	principal, err := authenticator.Authenticate(context.Background(), "ak_reader")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "api-key:7", principal.Subject)
+		assert.Equal(t, models.Scopes{models.ScopeOrdersRead}, principal.Scopes)
+		assert.Equal(t, "acme", principal.Account)
+	}
+
+	// Unknown keys are invalid, lookup failures are not
+	_, err = authenticator.Authenticate(context.Background(), "ak_revoked")
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+	_, err = authenticator.Authenticate(context.Background(), "ak_broken")
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestAPIKeyAuthenticatorAdminKey(t *testing.T) {
+	// Create mock repository; the admin key never reaches it
+	repo := new(MockAPIKeyRepository)
+	authenticator := NewAPIKeyAuthenticator(repo, "bootstrap-secret")
+
+	principal, err := authenticator.Authenticate(context.Background(), "bootstrap-secret")
+
+	// Assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, "api-key:admin", principal.Subject)
+		assert.True(t, principal.AdminKey)
+		assert.True(t, principal.Scopes.Has(models.ScopeOrdersWrite))
+	}
+	repo.AssertNotCalled(t, "GetByHash", mock.Anything, mock.Anything)
+}
+
+func TestChain(t *testing.T) {
+	// Create mock repositories for two authenticators
+	first, second := new(MockAPIKeyRepository), new(MockAPIKeyRepository)
+	first.On("GetByHash", mock.Anything, mock.Anything).Return(nil, apikey.ErrAPIKeyNotFound)
+	second.On("GetByHash", mock.Anything, apikey.Hash("ak_second")).Return(&models.APIKey{ID: 2}, nil)
+	second.On("GetByHash", mock.Anything, mock.Anything).Return(nil, apikey.ErrAPIKeyNotFound)
+	chain := Chain{NewAPIKeyAuthenticator(first, ""), NewAPIKeyAuthenticator(second, "")}
+
+	// Assert: a credential only the second knows passes, one neither knows is invalid
+	principal, err := chain.Authenticate(context.Background(), "ak_second")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "api-key:2", principal.Subject)
+	}
+	_, err = chain.Authenticate(context.Background(), "ak_unknown")
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+}
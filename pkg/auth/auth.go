@@ -0,0 +1,37 @@
+// Package auth identifies the callers of the API. An Authenticator turns the
+// credential a request carries, an API key or a JWT, into a models.Principal.
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// ErrInvalidCredential is returned for credentials that are unknown, revoked,
+// expired or otherwise not acceptable. Other errors mean the credential could
+// not be checked.
+var ErrInvalidCredential = errors.New("invalid credential")
+
+// Authenticator identifies the caller presenting a credential
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*models.Principal, error)
+}
+
+// Chain tries each authenticator in turn, returning the first principal found.
+// A credential rejected by all of them is invalid; an error checking it with
+// any of them is returned as is.
+type Chain []Authenticator
+
+// Authenticate implements Authenticator
+func (c Chain) Authenticate(ctx context.Context, credential string) (*models.Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, credential)
+		if errors.Is(err, ErrInvalidCredential) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrInvalidCredential
+}
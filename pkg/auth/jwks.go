@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// errKeyNotFound is returned when the key set has no key with the requested ID
+var errKeyNotFound = errors.New("signing key not found")
+
+// maxJWKSSize bounds the key set documents read from a file or URL
+const maxJWKSSize = 1 << 20
+
+// JWKS caches the JSON Web Key Set published by an identity provider. Keys
+// are reloaded once they are older than the refresh interval, and early when
+// a token names an unknown key, so rotated keys are picked up, at most once
+// per minRefresh. If a reload fails, the cached keys keep being used and the
+// source is not tried again for minRefresh.
+//
+// Reloads run in the background with their own timeout and are shared by
+// every caller that needs one, so a slow source never blocks callers that can
+// use the cached keys, and a caller giving up does not abort the reload.
+type JWKS struct {
+	source       string
+	refresh      time.Duration
+	minRefresh   time.Duration
+	fetchTimeout time.Duration
+	client       *http.Client
+	now          func() time.Time
+
+	mu          sync.Mutex
+	keys        *jose.JSONWebKeySet
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	loadErr     error
+	loading     chan struct{}
+}
+
+// NewJWKS creates a key set loaded from source, an http(s) URL or a file
+// path, and reloaded every refresh
+func NewJWKS(source string, refresh time.Duration) *JWKS {
+	return &JWKS{
+		source:       source,
+		refresh:      refresh,
+		minRefresh:   time.Minute,
+		fetchTimeout: 10 * time.Second,
+		client:       &http.Client{},
+		now:          time.Now,
+	}
+}
+
+// Key returns the key with the given ID. An empty ID matches the only key of
+// a single-key set.
+func (s *JWKS) Key(ctx context.Context, keyID string) (*jose.JSONWebKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	stale := s.keys == nil || now.Sub(s.fetchedAt) >= s.refresh
+	if stale && s.mayLoad(now) {
+		if err := s.load(ctx, now); err != nil && s.keys == nil {
+			return nil, err
+		}
+	}
+	if s.keys == nil {
+		return nil, s.loadErr
+	}
+
+	key := s.find(keyID)
+	if key == nil && !stale && s.mayLoad(now) {
+		// The provider may have rotated to a key we have not seen yet
+		if err := s.load(ctx, now); err == nil {
+			key = s.find(keyID)
+		}
+	}
+	if key == nil {
+		return nil, errKeyNotFound
+	}
+	return key, nil
+}
+
+// find looks keyID up in the cached set
+func (s *JWKS) find(keyID string) *jose.JSONWebKey {
+	if keyID == "" {
+		if len(s.keys.Keys) == 1 {
+			return &s.keys.Keys[0]
+		}
+		return nil
+	}
+	if keys := s.keys.Key(keyID); len(keys) > 0 {
+		return &keys[0]
+	}
+	return nil
+}
+
+// mayLoad reports whether the last load attempt was at least minRefresh ago
+func (s *JWKS) mayLoad(now time.Time) bool {
+	return s.attemptedAt.IsZero() || now.Sub(s.attemptedAt) >= s.minRefresh
+}
+
+// load starts a reload unless one is already running and waits for it to
+// finish or for ctx to be done. s.mu must be held; it is released while
+// waiting.
+func (s *JWKS) load(ctx context.Context, now time.Time) error {
+	done := s.loading
+	if done == nil {
+		done = make(chan struct{})
+		s.loading = done
+		go s.reload(context.WithoutCancel(ctx), now, done)
+	}
+
+	s.mu.Unlock()
+	select {
+	case <-done:
+		s.mu.Lock()
+		return s.loadErr
+	case <-ctx.Done():
+		s.mu.Lock()
+		return ctx.Err()
+	}
+}
+
+// reload reads the key set from its source, keeping the cached one on
+// failure, and closes done when finished. It is detached from the caller
+// that started it, so only the source's own answer or timeout is recorded.
+func (s *JWKS) reload(ctx context.Context, now time.Time, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(ctx, s.fetchTimeout)
+	defer cancel()
+	keys, err := s.fetch(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load JWKS", slog.String("source", s.source), slog.Any("error", err))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attemptedAt = now
+	if err != nil {
+		s.loadErr = err
+	} else {
+		s.keys = keys
+		s.fetchedAt = now
+		s.loadErr = nil
+	}
+	s.loading = nil
+	close(done)
+}
+
+// fetch reads and parses the key set
+func (s *JWKS) fetch(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	var body io.Reader
+	if strings.HasPrefix(s.source, "http://") || strings.HasPrefix(s.source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching JWKS: %s", resp.Status)
+		}
+		body = resp.Body
+	} else {
+		file, err := os.Open(strings.TrimPrefix(s.source, "file://"))
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		body = file
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := json.NewDecoder(io.LimitReader(body, maxJWKSSize)).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("parsing JWKS: %w", err)
+	}
+	return &keys, nil
+}
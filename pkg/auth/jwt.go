@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// signatureAlgorithms are the asymmetric algorithms accepted on tokens.
+// Symmetric ones are refused: a JWKS holds public keys only.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// JWTConfig says which tokens a JWTAuthenticator accepts and how their
+// claims map onto a principal
+type JWTConfig struct {
+	// Issuer must match the iss claim
+	Issuer string
+	// Audience must be one of the aud claim's values
+	Audience string
+	// AccountClaim names the claim holding the caller's account
+	AccountClaim string
+	// RolesClaim names the claim listing the caller's roles, as an array or a
+	// space-separated string. Roles named after a scope grant it; others are ignored.
+	RolesClaim string
+	// Leeway allows for clock skew when checking exp, nbf and iat
+	Leeway time.Duration
+}
+
+// JWTAuthenticator identifies callers by bearer JWTs signed with a key from
+// the identity provider's JWKS
+type JWTAuthenticator struct {
+	keys   *JWKS
+	config JWTConfig
+	now    func() time.Time
+}
+
+// NewJWTAuthenticator creates an authenticator verifying tokens against keys
+func NewJWTAuthenticator(keys *JWKS, config JWTConfig) *JWTAuthenticator {
+	return &JWTAuthenticator{keys: keys, config: config, now: time.Now}
+}
+
+// Authenticate verifies token's signature, issuer, audience and lifetime and
+// returns the principal its claims describe
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*models.Principal, error) {
+	parsed, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return nil, invalid(err)
+	}
+
+	header := parsed.Headers[0]
+	key, err := a.keys.Key(ctx, header.KeyID)
+	if errors.Is(err, errKeyNotFound) {
+		return nil, invalid(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+		return nil, invalid(fmt.Errorf("token signed with %s, key is for %s", header.Algorithm, key.Algorithm))
+	}
+
+	var registered jwt.Claims
+	var claims map[string]any
+	if err := parsed.Claims(key.Key, &registered, &claims); err != nil {
+		return nil, invalid(err)
+	}
+
+	if registered.Expiry == nil {
+		return nil, invalid(errors.New("token has no expiry"))
+	}
+	if registered.Subject == "" {
+		return nil, invalid(errors.New("token has no subject"))
+	}
+	expected := jwt.Expected{
+		Issuer:      a.config.Issuer,
+		AnyAudience: jwt.Audience{a.config.Audience},
+		Time:        a.now(),
+	}
+	if err := registered.ValidateWithLeeway(expected, a.config.Leeway); err != nil {
+		return nil, invalid(err)
+	}
+
+	account, _ := claims[a.config.AccountClaim].(string)
+	return &models.Principal{
+		Subject: "user:" + registered.Subject,
+		Name:    displayName(claims, registered.Subject),
+		Account: account,
+		Scopes:  roleScopes(claims[a.config.RolesClaim]),
+	}, nil
+}
+
+// invalid marks err as a rejected credential
+func invalid(err error) error {
+	return fmt.Errorf("%w: %v", ErrInvalidCredential, err)
+}
+
+// displayName picks a readable name from the standard OIDC claims
+func displayName(claims map[string]any, subject string) string {
+	for _, claim := range []string{"preferred_username", "email"} {
+		if name, ok := claims[claim].(string); ok && name != "" {
+			return name
+		}
+	}
+	return subject
+}
+
+// roleScopes returns the scopes named by a roles claim
+func roleScopes(roles any) models.Scopes {
+	var names []string
+	switch v := roles.(type) {
+	case string:
+		names = strings.Fields(v)
+	case []any:
+		for _, role := range v {
+			if name, ok := role.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	scopes := models.Scopes{}
+	for _, name := range names {
+		switch scope := models.Scope(name); scope {
+		case models.ScopeOrdersRead, models.ScopeOrdersWrite, models.ScopeAdmin:
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,390 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+const (
+	testIssuer   = "https://idp.example.com/"
+	testAudience = "trade-orders-api"
+)
+
+// testNow is the time tokens are checked at
+var testNow = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+// signingKey is a locally generated private key published under kid
+type signingKey struct {
+	kid       string
+	algorithm jose.SignatureAlgorithm
+	private   crypto.Signer
+}
+
+func newECKey(t *testing.T, kid string) signingKey {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+	return signingKey{kid: kid, algorithm: jose.ES256, private: private}
+}
+
+func newRSAKey(t *testing.T, kid string) signingKey {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error generating key: %v", err)
+	}
+	return signingKey{kid: kid, algorithm: jose.RS256, private: private}
+}
+
+// jwks returns the key set publishing the public halves of keys
+func jwks(keys ...signingKey) []byte {
+	set := jose.JSONWebKeySet{}
+	for _, key := range keys {
+		set.Keys = append(set.Keys, jose.JSONWebKey{Key: key.private.Public(), KeyID: key.kid, Algorithm: string(key.algorithm), Use: "sig"})
+	}
+	body, _ := json.Marshal(set)
+	return body
+}
+
+// sign issues a token signed with key, carrying the standard claims of a
+// valid token merged with extra
+func (key signingKey) sign(t *testing.T, registered jwt.Claims, extra map[string]any) string {
+	options := (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", key.kid)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: key.algorithm, Key: key.private}, options)
+	if err != nil {
+		t.Fatalf("Error creating signer: %v", err)
+	}
+	token, err := jwt.Signed(signer).Claims(registered).Claims(extra).Serialize()
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+	return token
+}
+
+// validClaims are the standard claims of a token accepted at testNow
+func validClaims() jwt.Claims {
+	return jwt.Claims{
+		Issuer:   testIssuer,
+		Subject:  "user-42",
+		Audience: jwt.Audience{testAudience},
+		IssuedAt: jwt.NewNumericDate(testNow.Add(-time.Minute)),
+		Expiry:   jwt.NewNumericDate(testNow.Add(time.Hour)),
+	}
+}
+
+// jwksServer publishes the key set returned by current and counts its fetches
+func jwksServer(t *testing.T, current func() []byte) (*httptest.Server, *int32) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.Write(current())
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+// newTestAuthenticator verifies tokens against source at testNow
+func newTestAuthenticator(source string) (*JWTAuthenticator, *JWKS) {
+	keys := NewJWKS(source, time.Hour)
+	keys.now = func() time.Time { return testNow }
+	authenticator := NewJWTAuthenticator(keys, JWTConfig{
+		Issuer:       testIssuer,
+		Audience:     testAudience,
+		AccountClaim: "account_id",
+		RolesClaim:   "roles",
+		Leeway:       30 * time.Second,
+	})
+	authenticator.now = func() time.Time { return testNow }
+	return authenticator, keys
+}
+
+func TestJWTAuthenticatorMapsClaims(t *testing.T) {
+	key := newECKey(t, "key-1")
+	server, _ := jwksServer(t, func() []byte { return jwks(key) })
+	authenticator, _ := newTestAuthenticator(server.URL)
+
+	// Issue a token with an account and a mix of known and unknown roles
+	token := key.sign(t, validClaims(), map[string]any{
+		"account_id":         "ACC-1",
+		"roles":              []string{"orders:read", "orders:write", "billing"},
+		"preferred_username": "jane",
+	})
+
+	principal, err := authenticator.Authenticate(context.Background(), token)
+
+	// Assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, "user:user-42", principal.Subject)
+		assert.Equal(t, "jane", principal.Name)
+		assert.Equal(t, "ACC-1", principal.Account)
+		assert.Equal(t, models.Scopes{models.ScopeOrdersRead, models.ScopeOrdersWrite}, principal.Scopes)
+	}
+}
+
+func TestJWTAuthenticatorReadsJWKSFile(t *testing.T) {
+	key := newRSAKey(t, "rsa-1")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks(key), 0o600); err != nil {
+		t.Fatalf("Error writing JWKS: %v", err)
+	}
+	authenticator, _ := newTestAuthenticator(path)
+
+	// Roles may also be a space-separated string
+	token := key.sign(t, validClaims(), map[string]any{"roles": "admin"})
+	principal, err := authenticator.Authenticate(context.Background(), token)
+
+	// Assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.Scopes{models.ScopeAdmin}, principal.Scopes)
+		assert.Equal(t, "user-42", principal.Name)
+	}
+}
+
+func TestJWTAuthenticatorRejectsTokens(t *testing.T) {
+	key, stranger := newECKey(t, "key-1"), newECKey(t, "key-1")
+	server, _ := jwksServer(t, func() []byte { return jwks(key) })
+	authenticator, _ := newTestAuthenticator(server.URL)
+
+	with := func(change func(*jwt.Claims)) jwt.Claims {
+		claims := validClaims()
+		change(&claims)
+		return claims
+	}
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{name: "Not a JWT", token: "ak_3f9c1b2d"},
+		{name: "Expired", token: key.sign(t, with(func(c *jwt.Claims) { c.Expiry = jwt.NewNumericDate(testNow.Add(-time.Minute)) }), nil)},
+		{name: "No expiry", token: key.sign(t, with(func(c *jwt.Claims) { c.Expiry = nil }), nil)},
+		{name: "Not yet valid", token: key.sign(t, with(func(c *jwt.Claims) { c.NotBefore = jwt.NewNumericDate(testNow.Add(time.Hour)) }), nil)},
+		{name: "Wrong issuer", token: key.sign(t, with(func(c *jwt.Claims) { c.Issuer = "https://evil.example.com/" }), nil)},
+		{name: "Wrong audience", token: key.sign(t, with(func(c *jwt.Claims) { c.Audience = jwt.Audience{"another-api"} }), nil)},
+		{name: "No subject", token: key.sign(t, with(func(c *jwt.Claims) { c.Subject = "" }), nil)},
+		{name: "Signed by another key", token: stranger.sign(t, validClaims(), nil)},
+		{name: "Unknown key ID", token: newECKey(t, "key-9").sign(t, validClaims(), nil)},
+		{name: "Symmetric algorithm", token: signHS256(t, validClaims())},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := authenticator.Authenticate(context.Background(), tc.token)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrInvalidCredential)
+			assert.Nil(t, principal)
+		})
+	}
+}
+
+// signHS256 issues a token signed with a shared secret
+func signHS256(t *testing.T, claims jwt.Claims) string {
+	options := (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "key-1")
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte("0123456789abcdef0123456789abcdef")}, options)
+	if err != nil {
+		t.Fatalf("Error creating signer: %v", err)
+	}
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+	return token
+}
+
+func TestJWKSPicksUpRotatedKeys(t *testing.T) {
+	oldKey, newKey := newECKey(t, "key-1"), newECKey(t, "key-2")
+	var published atomic.Value
+	published.Store(jwks(oldKey))
+	server, fetches := jwksServer(t, func() []byte { return published.Load().([]byte) })
+	authenticator, keys := newTestAuthenticator(server.URL)
+	now := testNow
+	keys.now = func() time.Time { return now }
+
+	// The first token loads the key set; later ones use the cache
+	_, err := authenticator.Authenticate(context.Background(), oldKey.sign(t, validClaims(), nil))
+	assert.NoError(t, err)
+	_, err = authenticator.Authenticate(context.Background(), oldKey.sign(t, validClaims(), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(fetches))
+
+	// The provider rotates; a token with the new key is only retried once the
+	// minimum refresh interval has passed
+	published.Store(jwks(newKey))
+	_, err = authenticator.Authenticate(context.Background(), newKey.sign(t, validClaims(), nil))
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+	assert.Equal(t, int32(1), atomic.LoadInt32(fetches))
+
+	now = now.Add(2 * time.Minute)
+	_, err = authenticator.Authenticate(context.Background(), newKey.sign(t, validClaims(), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(fetches))
+
+	// The retired key stops working with the reload
+	_, err = authenticator.Authenticate(context.Background(), oldKey.sign(t, validClaims(), nil))
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+}
+
+func TestJWKSKeepsCachedKeysWhenReloadFails(t *testing.T) {
+	key := newECKey(t, "key-1")
+	var healthy atomic.Bool
+	healthy.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(jwks(key))
+	}))
+	defer server.Close()
+	authenticator, keys := newTestAuthenticator(server.URL)
+	now := testNow
+	keys.now = func() time.Time { return now }
+
+	_, err := authenticator.Authenticate(context.Background(), key.sign(t, validClaims(), nil))
+	assert.NoError(t, err)
+
+	// The provider fails after the refresh interval; cached keys still verify
+	healthy.Store(false)
+	now = now.Add(2 * time.Hour)
+	_, err = authenticator.Authenticate(context.Background(), key.sign(t, validClaims(), nil))
+	assert.NoError(t, err)
+}
+
+func TestJWKSBacksOffWhenReloadFails(t *testing.T) {
+	key := newECKey(t, "key-1")
+	var healthy atomic.Bool
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(jwks(key))
+	}))
+	defer server.Close()
+	_, keys := newTestAuthenticator(server.URL)
+	now := testNow
+	keys.now = func() time.Time { return now }
+
+	// Without cached keys, a failed load is not retried on every request
+	for i := 0; i < 3; i++ {
+		_, err := keys.Key(context.Background(), "key-1")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// Once the minimum refresh interval has passed the source is tried again
+	healthy.Store(true)
+	now = now.Add(time.Minute)
+	_, err := keys.Key(context.Background(), "key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+
+	// A stale set whose reload fails keeps serving the cached keys
+	healthy.Store(false)
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < 3; i++ {
+		_, err := keys.Key(context.Background(), "key-1")
+		assert.NoError(t, err)
+	}
+	_, err = keys.Key(context.Background(), "key-2")
+	assert.ErrorIs(t, err, errKeyNotFound)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&fetches))
+}
+
+func TestJWKSReloadOutlivesCancelledCaller(t *testing.T) {
+	key := newECKey(t, "key-1")
+	release := make(chan struct{})
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		w.Write(jwks(key))
+	}))
+	defer server.Close()
+	_, keys := newTestAuthenticator(server.URL)
+
+	// The caller gives up while the source is slow
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := keys.Key(ctx, "key-1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The reload carries on and its keys are used without waiting out the
+	// backoff or fetching again
+	close(release)
+	assert.Eventually(t, func() bool {
+		_, err := keys.Key(context.Background(), "key-1")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
+
+func TestJWKSSharesReload(t *testing.T) {
+	key := newECKey(t, "key-1")
+	release := make(chan struct{})
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&fetches, 1) > 1 {
+			<-release
+		}
+		w.Write(jwks(key))
+	}))
+	defer server.Close()
+	_, keys := newTestAuthenticator(server.URL)
+	now := testNow
+	keys.now = func() time.Time { return now }
+
+	_, err := keys.Key(context.Background(), "key-1")
+	assert.NoError(t, err)
+
+	// While a reload for an unknown key hangs, callers of known keys are
+	// served from the cache and callers of unknown keys join the same reload
+	now = now.Add(2 * time.Minute)
+	results := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := keys.Key(context.Background(), "key-2")
+			results <- err
+		}()
+	}
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&fetches) == 2 }, time.Second, 10*time.Millisecond)
+	_, err = keys.Key(context.Background(), "key-1")
+	assert.NoError(t, err)
+
+	close(release)
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, <-results, errKeyNotFound)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestJWKSUnavailable(t *testing.T) {
+	key := newECKey(t, "key-1")
+	authenticator, _ := newTestAuthenticator(filepath.Join(t.TempDir(), "missing.json"))
+
+	// Without any keys the token cannot be checked, which is not its fault
+	_, err := authenticator.Authenticate(context.Background(), key.sign(t, validClaims(), nil))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidCredential)
+}
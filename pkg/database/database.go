@@ -1,12 +1,39 @@
 package database
 
 import (
+	"context"
+	"errors"
+
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
+// SchemaVersion is the migration version this build expects the database to
+// be at. Bump it with every migration added to cmd/migrate/migrations.
+const SchemaVersion = 18
+
+// queryTracing records a span with the SQL text of every statement. Bound
+// values are never recorded, and per-row and connection housekeeping spans
+// are left out to keep traces readable.
+var queryTracing = []otelsql.Option{
+	otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+	otelsql.WithSpanOptions(otelsql.SpanOptions{
+		DisableErrSkip:       true,
+		OmitConnResetSession: true,
+		OmitConnPrepare:      true,
+		OmitRows:             true,
+		OmitConnectorConnect: true,
+	}),
+}
+
+// ErrNotConnected is returned by checks made before Connect succeeds
+var ErrNotConnected = errors.New("database not connected")
+
 type Database struct {
 	config *Config
+	conn   *sqlx.DB
 }
 
 func New(config *Config) *Database {
@@ -16,9 +43,50 @@ func New(config *Config) *Database {
 }
 
 func (db *Database) Connect() (*sqlx.DB, error) {
-	conn, err := sqlx.Connect("postgres", db.config.DSN())
+	conn, err := open("postgres", db.config.DSN())
 	if err != nil {
 		return nil, err
 	}
+	db.conn = conn
 	return conn, nil
 }
+
+// open connects through driverName, traced, and checks the connection works
+func open(driverName, dsn string) (*sqlx.DB, error) {
+	sqlDB, err := otelsql.Open(driverName, dsn, queryTracing...)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := sqlx.NewDb(sqlDB, "postgres")
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Ping checks that the database still accepts queries
+func (db *Database) Ping(ctx context.Context) error {
+	if db.conn == nil {
+		return ErrNotConnected
+	}
+	return db.conn.PingContext(ctx)
+}
+
+// MigrationVersion returns the last migration applied to the database and
+// whether it failed partway, as recorded by golang-migrate
+func (db *Database) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	if db.conn == nil {
+		return 0, false, ErrNotConnected
+	}
+
+	var applied struct {
+		Version uint `db:"version"`
+		Dirty   bool `db:"dirty"`
+	}
+	if err := db.conn.GetContext(ctx, &applied, `SELECT version, dirty FROM schema_migrations LIMIT 1`); err != nil {
+		return 0, false, err
+	}
+	return applied.Version, applied.Dirty, nil
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+func TestSchemaVersionMatchesMigrations(t *testing.T) {
+	// Find the newest migration shipped with this build
+	files, err := filepath.Glob(filepath.Join("..", "..", "cmd", "migrate", "migrations", "*.up.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Error listing migrations: %v", err)
+	}
+
+	var latest uint64
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(filepath.Base(file), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			t.Fatalf("Error parsing migration %s: %v", file, err)
+		}
+		latest = max(latest, version)
+	}
+
+	// Assert
+	assert.Equal(t, uint64(SchemaVersion), latest, "bump SchemaVersion when adding a migration")
+}
+
+func TestMigrationVersion(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	database := &Database{conn: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(14, false))
+
+	// Call the MigrationVersion method
+	version, dirty, err := database.MigrationVersion(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint(14), version)
+	assert.False(t, dirty)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChecksBeforeConnect(t *testing.T) {
+	database := New(&Config{})
+
+	// Assert
+	assert.ErrorIs(t, database.Ping(context.Background()), ErrNotConnected)
+	_, _, err := database.MigrationVersion(context.Background())
+	assert.ErrorIs(t, err, ErrNotConnected)
+}
+
+func TestQueriesAreTracedWithoutBoundValues(t *testing.T) {
+	// Record spans in memory
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	// Create a new mock database, opened through the traced driver
+	db, mock, err := sqlmock.NewWithDSN("traced")
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	conn, err := open("sqlmock", "traced")
+	if err != nil {
+		t.Fatalf("Error opening traced database: %v", err)
+	}
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT symbol FROM orders WHERE client_order_id").
+		WithArgs("secret-client-id").
+		WillReturnRows(sqlmock.NewRows([]string{"symbol"}).AddRow("AAPL"))
+
+	// Run a query
+	var symbol string
+	err = conn.GetContext(context.Background(), &symbol, `SELECT symbol FROM orders WHERE client_order_id = $1`, "secret-client-id")
+	assert.NoError(t, err)
+
+	// Assert: the statement is recorded but its argument is not
+	var statement string
+	for _, span := range exporter.GetSpans() {
+		for _, attr := range span.Attributes {
+			if attr.Key == semconv.DBStatementKey {
+				statement = attr.Value.AsString()
+			}
+			assert.NotContains(t, attr.Value.Emit(), "secret-client-id")
+		}
+		assert.Contains(t, span.Attributes, attribute.KeyValue(semconv.DBSystemPostgreSQL))
+	}
+	assert.Equal(t, `SELECT symbol FROM orders WHERE client_order_id = $1`, statement)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,78 @@
+// Package expiry expires DAY and GTD orders once their expires_at has passed.
+package expiry
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/matching"
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// Expirer marks due orders as expired in storage
+type Expirer interface {
+	Expire(ctx context.Context, now time.Time) ([]models.Order, error)
+}
+
+// Worker periodically expires due orders and takes them off the book
+type Worker struct {
+	repo     Expirer
+	engine   *matching.Engine
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewWorker creates a worker that checks for due orders every interval
+func NewWorker(repo Expirer, engine *matching.Engine, interval time.Duration) *Worker {
+	return &Worker{
+		repo:     repo,
+		engine:   engine,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run expires due orders immediately and then on every tick, until ctx is done
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.ExpireDue(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to expire orders", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireDue expires every live order whose expiry has passed and removes it
+// from the book. It runs inside the engine's sequencing lock so an order
+// cannot be matched between being expired in storage and leaving the book.
+func (w *Worker) ExpireDue(ctx context.Context) ([]models.Order, error) {
+	var expired []models.Order
+	err := w.engine.Sequence(func() error {
+		orders, err := w.repo.Expire(ctx, w.now())
+		if err != nil {
+			return err
+		}
+
+		for _, order := range orders {
+			w.engine.Cancel(order.Symbol, order.ID)
+		}
+		expired = orders
+		return nil
+	})
+	return expired, err
+}
+
+// EndOfDay returns when a DAY order placed at t expires: the next midnight UTC
+func EndOfDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,82 @@
+package expiry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/matching"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+// stubExpirer returns a fixed set of due orders and records the time it was asked about
+type stubExpirer struct {
+	orders []models.Order
+	err    error
+	asked  time.Time
+}
+
+func (s *stubExpirer) Expire(_ context.Context, at time.Time) ([]models.Order, error) {
+	s.asked = at
+	return s.orders, s.err
+}
+
+func newRestingOrder(id int64, price int64) models.Order {
+	return models.Order{
+		ID:          id,
+		Symbol:      "AAPL",
+		Price:       models.NewDecimal(price, 0),
+		Quantity:    10,
+		OrderType:   models.Sell,
+		Kind:        models.Limit,
+		TimeInForce: models.GoodTillDate,
+		Status:      models.StatusNew,
+	}
+}
+
+func TestExpireDueRemovesOrdersFromBook(t *testing.T) {
+	engine := matching.NewEngine()
+	engine.Load([]models.Order{newRestingOrder(1, 150), newRestingOrder(2, 151)}, nil)
+
+	expired := newRestingOrder(1, 150)
+	expired.Status = models.StatusExpired
+	repo := &stubExpirer{orders: []models.Order{expired}}
+
+	worker := NewWorker(repo, engine, time.Minute)
+	worker.now = func() time.Time { return now }
+
+	orders, err := worker.ExpireDue(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, now, repo.asked)
+
+	_, asks := engine.Depth("AAPL")
+	require.Len(t, asks, 1)
+	assert.Equal(t, int64(2), asks[0].ID)
+}
+
+func TestExpireDueLeavesBookOnError(t *testing.T) {
+	engine := matching.NewEngine()
+	engine.Load([]models.Order{newRestingOrder(1, 150)}, nil)
+
+	worker := NewWorker(&stubExpirer{err: errors.New("database error")}, engine, time.Minute)
+
+	_, err := worker.ExpireDue(context.Background())
+	assert.Error(t, err)
+
+	_, asks := engine.Depth("AAPL")
+	assert.Len(t, asks, 1)
+}
+
+func TestEndOfDay(t *testing.T) {
+	assert.Equal(t, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), EndOfDay(now))
+
+	// 20:00 EST is already the next day in UTC
+	est := time.FixedZone("EST", -5*60*60)
+	assert.Equal(t, time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC), EndOfDay(time.Date(2025, 1, 2, 20, 0, 0, 0, est)))
+}
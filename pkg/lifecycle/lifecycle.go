@@ -0,0 +1,102 @@
+// Package lifecycle defines the order state machine: which status changes
+// are legal and which statuses are final.
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// ErrInvalidTransition is returned when an order cannot move between two statuses
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// ErrOrderClosed is returned when an order in a terminal status is modified
+var ErrOrderClosed = errors.New("order is no longer live")
+
+// statuses lists every order status, in the order lists derived from the
+// transition table are returned in
+var statuses = []models.OrderStatus{
+	models.StatusNew,
+	models.StatusPartiallyFilled,
+	models.StatusFilled,
+	models.StatusCancelled,
+	models.StatusRejected,
+	models.StatusExpired,
+}
+
+// transitions lists, for every status, the statuses an order may move to next.
+// Terminal statuses have no outgoing transitions. A live order may keep its
+// status while it changes in other ways, e.g. a triggered stop order that
+// rests without trading stays NEW.
+var transitions = map[models.OrderStatus][]models.OrderStatus{
+	models.StatusNew: {
+		models.StatusNew,
+		models.StatusPartiallyFilled,
+		models.StatusFilled,
+		models.StatusCancelled,
+		models.StatusRejected,
+		models.StatusExpired,
+	},
+	models.StatusPartiallyFilled: {
+		models.StatusPartiallyFilled,
+		models.StatusFilled,
+		models.StatusCancelled,
+		models.StatusExpired,
+	},
+	models.StatusFilled:    {},
+	models.StatusCancelled: {},
+	models.StatusRejected:  {},
+	models.StatusExpired:   {},
+}
+
+// IsValid reports whether status is a known order status
+func IsValid(status models.OrderStatus) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// IsTerminal reports whether no further transitions are possible from status
+func IsTerminal(status models.OrderStatus) bool {
+	next, ok := transitions[status]
+	return ok && len(next) == 0
+}
+
+// RequireLive returns ErrOrderClosed if an order in status can no longer be modified
+func RequireLive(status models.OrderStatus) error {
+	if !IsValid(status) || IsTerminal(status) {
+		return fmt.Errorf("%w: %s", ErrOrderClosed, status)
+	}
+	return nil
+}
+
+// CanTransition reports whether an order may move from one status to another
+func CanTransition(from, to models.OrderStatus) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Sources returns the statuses an order may move to status from, so that
+// bulk updates can guard on them in SQL
+func Sources(status models.OrderStatus) []models.OrderStatus {
+	var sources []models.OrderStatus
+	for _, from := range statuses {
+		if CanTransition(from, status) {
+			sources = append(sources, from)
+		}
+	}
+	return sources
+}
+
+// Transition validates a status change, returning ErrInvalidTransition if it is illegal
+func Transition(from, to models.OrderStatus) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition(t *testing.T) {
+	testCases := []struct {
+		name    string
+		from    models.OrderStatus
+		to      models.OrderStatus
+		allowed bool
+	}{
+		{"New to new", models.StatusNew, models.StatusNew, true},
+		{"New to partially filled", models.StatusNew, models.StatusPartiallyFilled, true},
+		{"New to filled", models.StatusNew, models.StatusFilled, true},
+		{"New to cancelled", models.StatusNew, models.StatusCancelled, true},
+		{"New to rejected", models.StatusNew, models.StatusRejected, true},
+		{"New to expired", models.StatusNew, models.StatusExpired, true},
+		{"Partially filled to partially filled", models.StatusPartiallyFilled, models.StatusPartiallyFilled, true},
+		{"Partially filled to filled", models.StatusPartiallyFilled, models.StatusFilled, true},
+		{"Partially filled to rejected", models.StatusPartiallyFilled, models.StatusRejected, false},
+		{"Partially filled to new", models.StatusPartiallyFilled, models.StatusNew, false},
+		{"Filled to cancelled", models.StatusFilled, models.StatusCancelled, false},
+		{"Cancelled to new", models.StatusCancelled, models.StatusNew, false},
+		{"Expired to filled", models.StatusExpired, models.StatusFilled, false},
+		{"Unknown status", "UNKNOWN", models.StatusFilled, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, CanTransition(tc.from, tc.to))
+
+			err := Transition(tc.from, tc.to)
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidTransition)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	assert.False(t, IsTerminal(models.StatusNew))
+	assert.False(t, IsTerminal(models.StatusPartiallyFilled))
+	assert.True(t, IsTerminal(models.StatusFilled))
+	assert.True(t, IsTerminal(models.StatusCancelled))
+	assert.True(t, IsTerminal(models.StatusRejected))
+	assert.True(t, IsTerminal(models.StatusExpired))
+	assert.False(t, IsTerminal("UNKNOWN"))
+}
+
+func TestIsValid(t *testing.T) {
+	assert.True(t, IsValid(models.StatusNew))
+	assert.True(t, IsValid(models.StatusExpired))
+	assert.False(t, IsValid("UNKNOWN"))
+}
+
+func TestSources(t *testing.T) {
+	assert.Equal(t, []models.OrderStatus{models.StatusNew, models.StatusPartiallyFilled}, Sources(models.StatusExpired))
+	assert.Equal(t, []models.OrderStatus{models.StatusNew, models.StatusPartiallyFilled}, Sources(models.StatusFilled))
+	assert.Equal(t, []models.OrderStatus{models.StatusNew}, Sources(models.StatusRejected))
+	assert.Empty(t, Sources("UNKNOWN"))
+}
+
+func TestRequireLive(t *testing.T) {
+	assert.NoError(t, RequireLive(models.StatusNew))
+	assert.NoError(t, RequireLive(models.StatusPartiallyFilled))
+	assert.ErrorIs(t, RequireLive(models.StatusFilled), ErrOrderClosed)
+	assert.ErrorIs(t, RequireLive(models.StatusCancelled), ErrOrderClosed)
+	assert.ErrorIs(t, RequireLive("UNKNOWN"), ErrOrderClosed)
+}
@@ -0,0 +1,93 @@
+// Package logging configures the structured log/slog logger the API writes
+// with, and carries the request ID that ties a request's log lines together.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Log formats accepted by New
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// requestIDKey is the context key of the request ID
+type requestIDKey struct{}
+
+// New creates a logger writing to w at level (debug, info, warn or error) in
+// format (json or text). Lines logged with a context carry the request ID and
+// trace ID found in it.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var minLevel slog.Level
+	if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown log level %q, expected one of: debug info warn error", level)
+	}
+
+	options := &slog.HandlerOptions{Level: minLevel}
+	var handler slog.Handler
+	switch format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	case FormatText:
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected one of: json text", format)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// FromEnv creates a logger writing to w at LOG_LEVEL (default info) in
+// LOG_FORMAT (default json). A variable that is set but empty is used as is,
+// and so rejected, rather than falling back to the default.
+func FromEnv(w io.Writer) (*slog.Logger, error) {
+	level, ok := os.LookupEnv("LOG_LEVEL")
+	if !ok {
+		level = "info"
+	}
+	format, ok := os.LookupEnv("LOG_FORMAT")
+	if !ok {
+		format = FormatJSON
+	}
+	return New(w, level, format)
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler adds the request and trace IDs of the logging context to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerAddsRequestID(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, "info", FormatJSON)
+	if err != nil {
+		t.Fatalf("Error creating logger: %v", err)
+	}
+
+	// Log with and without a request ID, and below the level
+	logger.InfoContext(WithRequestID(context.Background(), "req-1"), "Order created", slog.Int64("order_id", 42))
+	logger.With("component", "expiry").Info("Orders expired")
+	logger.Debug("Not written")
+
+	// Assert
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if assert.Len(t, lines, 2) {
+		var first, second map[string]any
+		assert.NoError(t, json.Unmarshal(lines[0], &first))
+		assert.NoError(t, json.Unmarshal(lines[1], &second))
+		assert.Equal(t, "req-1", first["request_id"])
+		assert.Equal(t, float64(42), first["order_id"])
+		assert.NotContains(t, second, "request_id")
+		assert.Equal(t, "expiry", second["component"])
+	}
+}
+
+func TestNewRejectsUnknownSettings(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "verbose", FormatJSON)
+	assert.Error(t, err)
+
+	_, err = New(&bytes.Buffer{}, "info", "xml")
+	assert.Error(t, err)
+}
+
+func TestFromEnv(t *testing.T) {
+	// Unset variables fall back to info and json
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("LOG_FORMAT", "")
+	os.Unsetenv("LOG_LEVEL")
+	os.Unsetenv("LOG_FORMAT")
+
+	var out bytes.Buffer
+	logger, err := FromEnv(&out)
+	if assert.NoError(t, err) {
+		logger.Debug("Not written")
+		logger.Info("Written")
+		assert.True(t, json.Valid(bytes.TrimSpace(out.Bytes())))
+		assert.NotContains(t, out.String(), "Not written")
+	}
+
+	// Set variables are used as given
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", FormatText)
+	out.Reset()
+	logger, err = FromEnv(&out)
+	if assert.NoError(t, err) {
+		logger.Debug("Written")
+		assert.Contains(t, out.String(), "level=DEBUG")
+	}
+
+	// An empty value is not mistaken for an unset one
+	t.Setenv("LOG_LEVEL", "")
+	_, err = FromEnv(&out)
+	assert.Error(t, err)
+}
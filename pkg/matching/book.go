@@ -0,0 +1,177 @@
+package matching
+
+import (
+	"sort"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// entry is an order resting on the book. seq is the arrival sequence used
+// for time priority within a price level.
+type entry struct {
+	order models.Order
+	seq   uint64
+}
+
+// book is the limit order book for a single symbol. Both sides are kept
+// sorted best-first: bids by descending price, asks by ascending price, and
+// by arrival sequence within the same price. Untriggered stop orders are held
+// apart from the book in arrival order, alongside the last trade price that
+// triggers them.
+type book struct {
+	bids  []*entry
+	asks  []*entry
+	stops []*entry
+
+	lastPrice models.Decimal
+	traded    bool
+}
+
+func newBook() *book {
+	return &book{}
+}
+
+// clone returns a copy of the book that matching on b leaves untouched
+func (b *book) clone() *book {
+	return &book{
+		bids:      cloneEntries(b.bids),
+		asks:      cloneEntries(b.asks),
+		stops:     cloneEntries(b.stops),
+		lastPrice: b.lastPrice,
+		traded:    b.traded,
+	}
+}
+
+// cloneEntries copies a list of entries, entries included, since matching
+// fills resting orders in place
+func cloneEntries(list []*entry) []*entry {
+	copies := make([]*entry, len(list))
+	for i, e := range list {
+		copied := *e
+		copies[i] = &copied
+	}
+	return copies
+}
+
+// side returns the orders resting on the given side of the book
+func (b *book) side(orderType models.OrderType) *[]*entry {
+	if orderType == models.Buy {
+		return &b.bids
+	}
+	return &b.asks
+}
+
+// opposite returns the side an incoming order of orderType matches against
+func (b *book) opposite(orderType models.OrderType) *[]*entry {
+	if orderType == models.Buy {
+		return &b.asks
+	}
+	return &b.bids
+}
+
+// insert rests an entry on its side of the book in price-time priority
+func (b *book) insert(e *entry) {
+	list := b.side(e.order.OrderType)
+	i := sort.Search(len(*list), func(i int) bool {
+		return before(e, (*list)[i])
+	})
+
+	*list = append(*list, nil)
+	copy((*list)[i+1:], (*list)[i:])
+	(*list)[i] = e
+}
+
+// park holds an untriggered stop order in arrival order
+func (b *book) park(e *entry) {
+	i := sort.Search(len(b.stops), func(i int) bool {
+		return e.seq < b.stops[i].seq
+	})
+
+	b.stops = append(b.stops, nil)
+	copy(b.stops[i+1:], b.stops[i:])
+	b.stops[i] = e
+}
+
+// nextTriggered takes the earliest stop order triggered by the last trade
+// price off the stop list, or returns nil if none is
+func (b *book) nextTriggered() *entry {
+	if !b.traded {
+		return nil
+	}
+	for i, e := range b.stops {
+		if triggered(e.order, b.lastPrice) {
+			b.stops = append(b.stops[:i], b.stops[i+1:]...)
+			return e
+		}
+	}
+	return nil
+}
+
+// remove takes an order off the book or the stop list, returning its entry if it was there
+func (b *book) remove(id int64) *entry {
+	for _, list := range []*[]*entry{&b.bids, &b.asks, &b.stops} {
+		for i, e := range *list {
+			if e.order.ID == id {
+				*list = append((*list)[:i], (*list)[i+1:]...)
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// before reports whether a has priority over b on the same side of the book
+func before(a, b *entry) bool {
+	if cmp := a.order.Price.Cmp(b.order.Price); cmp != 0 {
+		if a.order.OrderType == models.Buy {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	return a.seq < b.seq
+}
+
+// crosses reports whether an incoming order is willing to trade at a resting price
+func crosses(taker models.Order, price models.Decimal) bool {
+	if taker.Kind == models.Market {
+		return true
+	}
+	if taker.OrderType == models.Buy {
+		return taker.Price.Cmp(price) >= 0
+	}
+	return taker.Price.Cmp(price) <= 0
+}
+
+// triggered reports whether a last trade price reaches a stop order's trigger:
+// at or above it for a buy, at or below it for a sell
+func triggered(stop models.Order, lastPrice models.Decimal) bool {
+	if stop.TriggerPrice == nil {
+		return false
+	}
+	if stop.OrderType == models.Buy {
+		return lastPrice.Cmp(*stop.TriggerPrice) >= 0
+	}
+	return lastPrice.Cmp(*stop.TriggerPrice) <= 0
+}
+
+// available sums the resting quantity a taker could trade against, stopping
+// once it covers the taker's remaining quantity
+func available(list []*entry, taker models.Order) int {
+	total := 0
+	for _, e := range list {
+		if total >= taker.Remaining() || !crosses(taker, e.order.Price) {
+			break
+		}
+		total += e.order.Remaining()
+	}
+	return total
+}
+
+// orders returns copies of the orders resting on one side, best first
+func orders(list []*entry) []models.Order {
+	result := make([]models.Order, 0, len(list))
+	for _, e := range list {
+		result = append(result, e.order)
+	}
+	return result
+}
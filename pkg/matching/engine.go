@@ -0,0 +1,371 @@
+// Package matching implements an in-process limit order book per symbol with
+// price-time priority and partial fills.
+package matching
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// Result describes the outcome of submitting an order to the engine
+type Result struct {
+	// Order is the submitted order with its final fill state
+	Order models.Order
+	// Trades are the executions produced, in the order they happened
+	Trades []models.Trade
+	// Updated holds every order whose fill state changed, submitted order
+	// first, each with its latest state
+	Updated []models.Order
+	// Triggers records the stop orders the submission's trades triggered
+	Triggers []models.OrderTrigger
+}
+
+// Engine matches orders across per-symbol books
+type Engine struct {
+	mu    sync.Mutex
+	books map[string]*book
+	seq   uint64
+	now   func() time.Time
+
+	// saved holds, while Atomic runs, each book as it was before Atomic's
+	// function first touched it
+	saved map[string]*book
+
+	// sequenceMu orders callers that need to apply engine results
+	// to storage in the same order the engine produced them
+	sequenceMu sync.Mutex
+}
+
+// NewEngine creates an empty matching engine
+func NewEngine() *Engine {
+	return &Engine{
+		books: make(map[string]*book),
+		now:   time.Now,
+	}
+}
+
+// Sequence runs fn while holding the engine's sequencing lock. Callers wrap
+// an engine call together with persisting its result, so concurrent requests
+// cannot store fills out of order.
+func (e *Engine) Sequence(fn func() error) error {
+	e.sequenceMu.Lock()
+	defer e.sequenceMu.Unlock()
+	return fn()
+}
+
+// Atomic runs fn, which submits or amends orders and stores what they
+// produced, and puts every book fn touched back as it was if fn fails, so the
+// engine never holds fills that were not stored. Like the calls it wraps, it
+// must run inside Sequence.
+func (e *Engine) Atomic(fn func() error) error {
+	e.mu.Lock()
+	e.saved = make(map[string]*book)
+	e.mu.Unlock()
+
+	err := fn()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		for symbol, b := range e.saved {
+			e.books[symbol] = b
+		}
+	}
+	e.saved = nil
+	return err
+}
+
+// Load rests already-accepted orders on the book without matching them, and
+// holds untriggered stop orders until a trade triggers them. It is used to
+// restore live orders on startup; orders must be given in their original
+// arrival order. lastTrades holds the latest trade of each symbol, whose
+// price stop orders are checked against until the book trades again.
+func (e *Engine) Load(orders []models.Order, lastTrades []models.Trade) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, trade := range lastTrades {
+		b := e.book(trade.Symbol)
+		b.lastPrice, b.traded = trade.Price, true
+	}
+	for _, order := range orders {
+		switch {
+		case order.Remaining() <= 0:
+			continue
+		case order.Kind.IsStop():
+			e.park(order)
+		default:
+			e.rest(order)
+		}
+	}
+}
+
+// Submit matches an incoming order against the opposite side of its book.
+// A stop order is held until the last trade price reaches its trigger, then
+// converted to a market (STOP) or limit (STOP_LIMIT) order and matched; one
+// whose trigger the last trade price has already reached triggers at once.
+// Trades that move the last price trigger any stops they reach in turn.
+// Any quantity left over on a limit order rests on the book, unless its time
+// in force is IOC or FOK. Market orders never rest: one that finds no
+// liquidity is rejected, and the unfilled remainder of one that exhausts the
+// book is cancelled. A FOK order that cannot be filled in full on arrival
+// does not trade at all.
+func (e *Engine) Submit(order models.Order) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.submit(order)
+}
+
+// Cancel takes an order off the book or the stop list, reporting whether it was there
+func (e *Engine) Cancel(symbol string, id int64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.book(symbol).remove(id) != nil
+}
+
+// Amend applies new terms to a resting order. Reducing the quantity at the
+// same price keeps the order's time priority; any other change re-enters the
+// order as if newly submitted, which may produce trades.
+func (e *Engine) Amend(order models.Order) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	b := e.book(order.Symbol)
+	existing := b.remove(order.ID)
+	if existing != nil && existing.order.Price.Equal(order.Price) && order.Quantity <= existing.order.Quantity {
+		if order.Kind.IsStop() {
+			b.park(&entry{order: order, seq: existing.seq})
+		} else {
+			b.insert(&entry{order: order, seq: existing.seq})
+		}
+		return Result{Order: order}
+	}
+
+	return e.submit(order)
+}
+
+// Depth returns the orders resting on each side of a symbol's book, best first
+func (e *Engine) Depth(symbol string) (bids, asks []models.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	b := e.book(symbol)
+	return orders(b.bids), orders(b.asks)
+}
+
+// Stops returns a symbol's untriggered stop orders in arrival order
+func (e *Engine) Stops(symbol string) []models.Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return orders(e.book(symbol).stops)
+}
+
+// submit enters an order and any stops its trades trigger; e.mu must be held
+func (e *Engine) submit(order models.Order) Result {
+	var result Result
+	b := e.book(order.Symbol)
+
+	activated := false
+	if order.Kind.IsStop() {
+		if !b.traded || !triggered(order, b.lastPrice) {
+			e.park(order)
+			result.Order = order
+			return result
+		}
+		order = e.activate(order, b.lastPrice, &result)
+		activated = true
+	}
+
+	result.Order = e.execute(order, activated, &result)
+
+	for stop := b.nextTriggered(); stop != nil; stop = b.nextTriggered() {
+		e.execute(e.activate(stop.order, b.lastPrice, &result), true, &result)
+	}
+
+	result.Updated = latest(result.Updated)
+	return result
+}
+
+// execute matches an order and rests or closes its remainder, recording its
+// trades and every order they changed on result. changed forces the order
+// into result.Updated even if matching leaves its fill state alone; e.mu must
+// be held.
+func (e *Engine) execute(taker models.Order, changed bool, result *Result) models.Order {
+	b := e.book(taker.Symbol)
+	opposite := b.opposite(taker.OrderType)
+
+	var trades []models.Trade
+	var makers []models.Order
+	initialStatus := taker.Status
+
+	if taker.TimeInForce == models.FillOrKill && available(*opposite, taker) < taker.Remaining() {
+		taker.Status = unfilledStatus(taker)
+		result.Updated = append(result.Updated, taker)
+		return taker
+	}
+
+	for taker.Remaining() > 0 && len(*opposite) > 0 {
+		maker := (*opposite)[0]
+		if !crosses(taker, maker.order.Price) {
+			break
+		}
+
+		quantity := min(taker.Remaining(), maker.order.Remaining())
+		trades = append(trades, newTrade(taker, maker.order, quantity, e.now()))
+		b.lastPrice, b.traded = maker.order.Price, true
+
+		taker.FilledQuantity += quantity
+		maker.order.FilledQuantity += quantity
+		maker.order.Status = fillStatus(maker.order)
+		makers = append(makers, maker.order)
+
+		if maker.order.Remaining() == 0 {
+			*opposite = (*opposite)[1:]
+		}
+	}
+
+	taker.Status = fillStatus(taker)
+	if taker.Remaining() > 0 {
+		if rests(taker) {
+			e.rest(taker)
+		} else {
+			taker.Status = unfilledStatus(taker)
+		}
+	}
+
+	if changed || len(trades) > 0 || taker.Status != initialStatus {
+		result.Updated = append(result.Updated, taker)
+		result.Updated = append(result.Updated, makers...)
+	}
+	result.Trades = append(result.Trades, trades...)
+
+	return taker
+}
+
+// activate converts a triggered stop order into the market or limit order it
+// stands for and records why it triggered; e.mu must be held
+func (e *Engine) activate(stop models.Order, lastPrice models.Decimal, result *Result) models.Order {
+	trigger := models.OrderTrigger{
+		OrderID:      stop.ID,
+		Kind:         stop.Kind,
+		TriggerPrice: *stop.TriggerPrice,
+		LastPrice:    lastPrice,
+		TriggeredAt:  e.now(),
+	}
+
+	comparison := "at or above"
+	if stop.OrderType == models.Sell {
+		comparison = "at or below"
+	}
+	trigger.Reason = fmt.Sprintf("last trade price %s is %s trigger price %s", lastPrice, comparison, trigger.TriggerPrice)
+
+	order := stop
+	order.Kind = models.Limit
+	if stop.Kind == models.Stop {
+		order.Kind = models.Market
+	}
+	order.TriggeredAt = &trigger.TriggeredAt
+	trigger.ActivatedKind = order.Kind
+
+	result.Triggers = append(result.Triggers, trigger)
+	return order
+}
+
+// park holds a stop order with the next arrival sequence; e.mu must be held
+func (e *Engine) park(order models.Order) {
+	e.seq++
+	e.book(order.Symbol).park(&entry{order: order, seq: e.seq})
+}
+
+// rest places an order on its book with the next arrival sequence; e.mu must be held
+func (e *Engine) rest(order models.Order) {
+	e.seq++
+	e.book(order.Symbol).insert(&entry{order: order, seq: e.seq})
+}
+
+// book returns the book for a symbol, creating it on first use and saving a
+// copy of it the first time it is used while Atomic runs; e.mu must be held
+func (e *Engine) book(symbol string) *book {
+	b, ok := e.books[symbol]
+	if !ok {
+		b = newBook()
+		e.books[symbol] = b
+	}
+	if e.saved != nil {
+		if _, ok := e.saved[symbol]; !ok {
+			e.saved[symbol] = b.clone()
+		}
+	}
+	return b
+}
+
+// newTrade records an execution at the resting order's price
+func newTrade(taker, maker models.Order, quantity int, executedAt time.Time) models.Trade {
+	trade := models.Trade{
+		Symbol:     taker.Symbol,
+		Price:      maker.Price,
+		Quantity:   quantity,
+		ExecutedAt: executedAt,
+	}
+	if taker.OrderType == models.Buy {
+		trade.BuyOrderID, trade.SellOrderID = taker.ID, maker.ID
+	} else {
+		trade.BuyOrderID, trade.SellOrderID = maker.ID, taker.ID
+	}
+	return trade
+}
+
+// rests reports whether an order's unfilled remainder stays on the book
+func rests(order models.Order) bool {
+	if order.Kind == models.Market {
+		return false
+	}
+	return order.TimeInForce != models.ImmediateOrCancel && order.TimeInForce != models.FillOrKill
+}
+
+// latest collapses repeated entries for the same order into one holding its
+// last state, kept at the position the order first appeared
+func latest(updated []models.Order) []models.Order {
+	if len(updated) == 0 {
+		return nil
+	}
+
+	index := make(map[int64]int, len(updated))
+	result := make([]models.Order, 0, len(updated))
+	for _, order := range updated {
+		if i, ok := index[order.ID]; ok {
+			result[i] = order
+			continue
+		}
+		index[order.ID] = len(result)
+		result = append(result, order)
+	}
+	return result
+}
+
+// unfilledStatus is the final status of an order whose remainder cannot rest:
+// a market order that executed nothing is rejected, anything else is cancelled
+func unfilledStatus(order models.Order) models.OrderStatus {
+	if order.Kind == models.Market && order.FilledQuantity == 0 {
+		return models.StatusRejected
+	}
+	return models.StatusCancelled
+}
+
+// fillStatus derives an order's status from how much of it has been filled
+func fillStatus(order models.Order) models.OrderStatus {
+	switch {
+	case order.Remaining() == 0:
+		return models.StatusFilled
+	case order.FilledQuantity > 0:
+		return models.StatusPartiallyFilled
+	default:
+		return order.Status
+	}
+}
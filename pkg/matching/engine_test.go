@@ -0,0 +1,626 @@
+package matching
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var executedAt = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+// newTestEngine returns an engine with a fixed clock
+func newTestEngine() *Engine {
+	engine := NewEngine()
+	engine.now = func() time.Time { return executedAt }
+	return engine
+}
+
+// price returns a whole-number price
+func price(units int64) models.Decimal {
+	return models.NewDecimal(units, 0)
+}
+
+func newOrder(id int64, orderType models.OrderType, limit int64, quantity int) models.Order {
+	return models.Order{
+		ID:        id,
+		Symbol:    "AAPL",
+		Price:     price(limit),
+		Quantity:  quantity,
+		OrderType: orderType,
+		Kind:      models.Limit,
+		Status:    models.StatusNew,
+	}
+}
+
+func newMarketOrder(id int64, orderType models.OrderType, quantity int) models.Order {
+	order := newOrder(id, orderType, 0, quantity)
+	order.Kind = models.Market
+	return order
+}
+
+func TestSubmitRestsWhenNotCrossing(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 151, 10))
+	result := engine.Submit(newOrder(2, models.Buy, 150, 10))
+
+	assert.Empty(t, result.Trades)
+	assert.Empty(t, result.Updated)
+	assert.Equal(t, models.StatusNew, result.Order.Status)
+
+	bids, asks := engine.Depth("AAPL")
+	require.Len(t, bids, 1)
+	require.Len(t, asks, 1)
+	assert.Equal(t, int64(2), bids[0].ID)
+	assert.Equal(t, int64(1), asks[0].ID)
+}
+
+func TestSubmitComparesFractionalPricesExactly(t *testing.T) {
+	engine := newTestEngine()
+
+	ask := newOrder(1, models.Sell, 0, 10)
+	ask.Price = models.MustParseDecimal("150.5")
+	engine.Submit(ask)
+
+	// One tick below the ask must not cross
+	bid := newOrder(2, models.Buy, 0, 10)
+	bid.Price = models.MustParseDecimal("150.4999")
+	result := engine.Submit(bid)
+	assert.Empty(t, result.Trades)
+
+	// The same price written differently crosses at the resting price
+	taker := newOrder(3, models.Buy, 0, 10)
+	taker.Price = models.MustParseDecimal("150.50")
+	result = engine.Submit(taker)
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, "150.5", result.Trades[0].Price.String())
+}
+
+func TestSubmitFullyFillsCrossingOrders(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 10))
+	result := engine.Submit(newOrder(2, models.Buy, 152, 10))
+
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, models.Trade{
+		BuyOrderID:  2,
+		SellOrderID: 1,
+		Symbol:      "AAPL",
+		Price:       price(150), // trades execute at the resting price
+		Quantity:    10,
+		ExecutedAt:  executedAt,
+	}, result.Trades[0])
+
+	assert.Equal(t, models.StatusFilled, result.Order.Status)
+	assert.Equal(t, 10, result.Order.FilledQuantity)
+
+	require.Len(t, result.Updated, 2)
+	assert.Equal(t, int64(2), result.Updated[0].ID)
+	assert.Equal(t, int64(1), result.Updated[1].ID)
+	assert.Equal(t, models.StatusFilled, result.Updated[1].Status)
+
+	bids, asks := engine.Depth("AAPL")
+	assert.Empty(t, bids)
+	assert.Empty(t, asks)
+}
+
+func TestSubmitPartiallyFillsIncomingOrder(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 4))
+	result := engine.Submit(newOrder(2, models.Buy, 150, 10))
+
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, 4, result.Trades[0].Quantity)
+	assert.Equal(t, models.StatusPartiallyFilled, result.Order.Status)
+	assert.Equal(t, 4, result.Order.FilledQuantity)
+	assert.Equal(t, 6, result.Order.Remaining())
+
+	// The remainder rests on the bid side
+	bids, asks := engine.Depth("AAPL")
+	assert.Empty(t, asks)
+	require.Len(t, bids, 1)
+	assert.Equal(t, int64(2), bids[0].ID)
+	assert.Equal(t, 6, bids[0].Remaining())
+}
+
+func TestSubmitPartiallyFillsRestingOrder(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Buy, 150, 10))
+	result := engine.Submit(newOrder(2, models.Sell, 149, 3))
+
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, int64(1), result.Trades[0].BuyOrderID)
+	assert.Equal(t, int64(2), result.Trades[0].SellOrderID)
+	assert.Equal(t, price(150), result.Trades[0].Price)
+	assert.Equal(t, models.StatusFilled, result.Order.Status)
+
+	require.Len(t, result.Updated, 2)
+	assert.Equal(t, models.StatusPartiallyFilled, result.Updated[1].Status)
+	assert.Equal(t, 3, result.Updated[1].FilledQuantity)
+
+	bids, _ := engine.Depth("AAPL")
+	require.Len(t, bids, 1)
+	assert.Equal(t, 7, bids[0].Remaining())
+}
+
+func TestSubmitSweepsLevelsInPriceTimePriority(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 151, 5))
+	engine.Submit(newOrder(2, models.Sell, 150, 5))
+	engine.Submit(newOrder(3, models.Sell, 150, 5))
+	engine.Submit(newOrder(4, models.Sell, 152, 5))
+
+	result := engine.Submit(newOrder(5, models.Buy, 151, 12))
+
+	// Best price first, then earliest arrival within the price level
+	require.Len(t, result.Trades, 3)
+	assert.Equal(t, int64(2), result.Trades[0].SellOrderID)
+	assert.Equal(t, price(150), result.Trades[0].Price)
+	assert.Equal(t, int64(3), result.Trades[1].SellOrderID)
+	assert.Equal(t, price(150), result.Trades[1].Price)
+	assert.Equal(t, int64(1), result.Trades[2].SellOrderID)
+	assert.Equal(t, price(151), result.Trades[2].Price)
+	assert.Equal(t, 2, result.Trades[2].Quantity)
+
+	assert.Equal(t, models.StatusFilled, result.Order.Status)
+
+	// The 152 level is out of the buyer's limit and stays untouched
+	_, asks := engine.Depth("AAPL")
+	require.Len(t, asks, 2)
+	assert.Equal(t, int64(1), asks[0].ID)
+	assert.Equal(t, 3, asks[0].Remaining())
+	assert.Equal(t, int64(4), asks[1].ID)
+}
+
+func TestSubmitKeepsSymbolsSeparate(t *testing.T) {
+	engine := newTestEngine()
+
+	msft := newOrder(1, models.Sell, 150, 10)
+	msft.Symbol = "MSFT"
+	engine.Submit(msft)
+
+	result := engine.Submit(newOrder(2, models.Buy, 150, 10))
+
+	assert.Empty(t, result.Trades)
+	_, asks := engine.Depth("MSFT")
+	assert.Len(t, asks, 1)
+}
+
+func TestSubmitMarketOrderSweepsBook(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 152, 5))
+	engine.Submit(newOrder(2, models.Sell, 150, 5))
+
+	result := engine.Submit(newMarketOrder(3, models.Buy, 8))
+
+	// Best price first, with no limit on how far it walks the book
+	require.Len(t, result.Trades, 2)
+	assert.Equal(t, price(150), result.Trades[0].Price)
+	assert.Equal(t, 5, result.Trades[0].Quantity)
+	assert.Equal(t, price(152), result.Trades[1].Price)
+	assert.Equal(t, 3, result.Trades[1].Quantity)
+	assert.Equal(t, models.StatusFilled, result.Order.Status)
+}
+
+func TestSubmitMarketOrderRejectedOnEmptyBook(t *testing.T) {
+	engine := newTestEngine()
+
+	result := engine.Submit(newMarketOrder(1, models.Sell, 10))
+
+	assert.Empty(t, result.Trades)
+	assert.Equal(t, models.StatusRejected, result.Order.Status)
+	require.Len(t, result.Updated, 1)
+	assert.Equal(t, models.StatusRejected, result.Updated[0].Status)
+
+	// Market orders never rest
+	bids, asks := engine.Depth("AAPL")
+	assert.Empty(t, bids)
+	assert.Empty(t, asks)
+}
+
+func TestSubmitMarketOrderCancelsUnfilledRemainder(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Buy, 150, 4))
+
+	result := engine.Submit(newMarketOrder(2, models.Sell, 10))
+
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, 4, result.Order.FilledQuantity)
+	assert.Equal(t, models.StatusCancelled, result.Order.Status)
+
+	bids, asks := engine.Depth("AAPL")
+	assert.Empty(t, bids)
+	assert.Empty(t, asks)
+}
+
+func TestCancel(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 10))
+
+	assert.True(t, engine.Cancel("AAPL", 1))
+	assert.False(t, engine.Cancel("AAPL", 1))
+
+	result := engine.Submit(newOrder(2, models.Buy, 150, 10))
+	assert.Empty(t, result.Trades)
+}
+
+func TestAtomicRestoresBookOnFailure(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 10))
+	engine.Submit(newStopOrder(2, models.Sell, models.Stop, 0, 150, 5))
+
+	// The fills of a trade that cannot be stored are undone, stops included
+	err := engine.Atomic(func() error {
+		result := engine.Submit(newOrder(3, models.Buy, 150, 4))
+		require.Len(t, result.Trades, 1)
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	bids, asks := engine.Depth("AAPL")
+	assert.Empty(t, bids)
+	require.Len(t, asks, 1)
+	assert.Equal(t, 0, asks[0].FilledQuantity)
+	assert.Equal(t, models.StatusNew, asks[0].Status)
+	assert.Len(t, engine.Stops("AAPL"), 1)
+
+	// Stored fills stay
+	err = engine.Atomic(func() error {
+		engine.Submit(newOrder(4, models.Buy, 150, 4))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	_, asks = engine.Depth("AAPL")
+	require.Len(t, asks, 1)
+	assert.Equal(t, 4, asks[0].FilledQuantity)
+}
+
+func TestAmendReducingQuantityKeepsPriority(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 10))
+	engine.Submit(newOrder(2, models.Sell, 150, 10))
+
+	amended := newOrder(1, models.Sell, 150, 5)
+	result := engine.Amend(amended)
+	assert.Empty(t, result.Trades)
+
+	_, asks := engine.Depth("AAPL")
+	require.Len(t, asks, 2)
+	assert.Equal(t, int64(1), asks[0].ID)
+	assert.Equal(t, 5, asks[0].Quantity)
+}
+
+func TestAmendIncreasingQuantityLosesPriority(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 10))
+	engine.Submit(newOrder(2, models.Sell, 150, 10))
+
+	engine.Amend(newOrder(1, models.Sell, 150, 20))
+
+	_, asks := engine.Depth("AAPL")
+	require.Len(t, asks, 2)
+	assert.Equal(t, int64(2), asks[0].ID)
+	assert.Equal(t, int64(1), asks[1].ID)
+}
+
+func TestAmendPriceCanCross(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 10))
+	engine.Submit(newOrder(2, models.Buy, 149, 10))
+
+	result := engine.Amend(newOrder(2, models.Buy, 150, 10))
+
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, models.StatusFilled, result.Order.Status)
+	bids, asks := engine.Depth("AAPL")
+	assert.Empty(t, bids)
+	assert.Empty(t, asks)
+}
+
+func TestLoadRestoresBookWithoutMatching(t *testing.T) {
+	engine := newTestEngine()
+
+	partiallyFilled := newOrder(2, models.Buy, 150, 10)
+	partiallyFilled.FilledQuantity = 4
+	partiallyFilled.Status = models.StatusPartiallyFilled
+
+	engine.Load([]models.Order{
+		newOrder(1, models.Buy, 150, 10),
+		partiallyFilled,
+	}, nil)
+
+	result := engine.Submit(newOrder(3, models.Sell, 150, 12))
+
+	require.Len(t, result.Trades, 2)
+	assert.Equal(t, int64(1), result.Trades[0].BuyOrderID)
+	assert.Equal(t, 10, result.Trades[0].Quantity)
+	assert.Equal(t, int64(2), result.Trades[1].BuyOrderID)
+	assert.Equal(t, 2, result.Trades[1].Quantity)
+	assert.Equal(t, models.StatusPartiallyFilled, result.Updated[2].Status)
+	assert.Equal(t, 6, result.Updated[2].FilledQuantity)
+}
+
+func TestSubmitIOCCancelsUnfilledRemainder(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 4))
+
+	order := newOrder(2, models.Buy, 150, 10)
+	order.TimeInForce = models.ImmediateOrCancel
+	result := engine.Submit(order)
+
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, 4, result.Order.FilledQuantity)
+	assert.Equal(t, models.StatusCancelled, result.Order.Status)
+
+	// The remainder never rests
+	bids, _ := engine.Depth("AAPL")
+	assert.Empty(t, bids)
+}
+
+func TestSubmitIOCWithoutLiquidityIsCancelled(t *testing.T) {
+	engine := newTestEngine()
+
+	order := newOrder(1, models.Buy, 150, 10)
+	order.TimeInForce = models.ImmediateOrCancel
+	result := engine.Submit(order)
+
+	assert.Empty(t, result.Trades)
+	assert.Equal(t, models.StatusCancelled, result.Order.Status)
+	require.Len(t, result.Updated, 1)
+}
+
+func TestSubmitFOKFillsInFull(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 4))
+	engine.Submit(newOrder(2, models.Sell, 151, 6))
+
+	order := newOrder(3, models.Buy, 151, 10)
+	order.TimeInForce = models.FillOrKill
+	result := engine.Submit(order)
+
+	require.Len(t, result.Trades, 2)
+	assert.Equal(t, models.StatusFilled, result.Order.Status)
+}
+
+func TestSubmitFOKKilledWhenNotFullyFillable(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 4))
+	engine.Submit(newOrder(2, models.Sell, 152, 6)) // beyond the buyer's limit
+
+	order := newOrder(3, models.Buy, 151, 10)
+	order.TimeInForce = models.FillOrKill
+	result := engine.Submit(order)
+
+	assert.Empty(t, result.Trades)
+	assert.Equal(t, 0, result.Order.FilledQuantity)
+	assert.Equal(t, models.StatusCancelled, result.Order.Status)
+	require.Len(t, result.Updated, 1)
+
+	// The book is left untouched
+	bids, asks := engine.Depth("AAPL")
+	assert.Empty(t, bids)
+	require.Len(t, asks, 2)
+	assert.Equal(t, 0, asks[0].FilledQuantity)
+}
+
+func TestSubmitMarketFOKRejectedWhenNotFullyFillable(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Buy, 150, 4))
+
+	order := newMarketOrder(2, models.Sell, 10)
+	order.TimeInForce = models.FillOrKill
+	result := engine.Submit(order)
+
+	assert.Empty(t, result.Trades)
+	assert.Equal(t, models.StatusRejected, result.Order.Status)
+}
+
+func newStopOrder(id int64, orderType models.OrderType, kind models.OrderKind, limit, trigger int64, quantity int) models.Order {
+	order := newOrder(id, orderType, limit, quantity)
+	order.Kind = kind
+	triggerPrice := price(trigger)
+	order.TriggerPrice = &triggerPrice
+	return order
+}
+
+func TestSubmitStopWaitsForTrigger(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Buy, 140, 10))
+	result := engine.Submit(newStopOrder(2, models.Sell, models.Stop, 0, 145, 5))
+
+	// Untriggered stops do not trade and stay off the book
+	assert.Empty(t, result.Trades)
+	assert.Empty(t, result.Updated)
+	assert.Equal(t, models.Stop, result.Order.Kind)
+
+	_, asks := engine.Depth("AAPL")
+	assert.Empty(t, asks)
+	require.Len(t, engine.Stops("AAPL"), 1)
+}
+
+func TestSubmitTradeTriggersSellStop(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Buy, 144, 10))
+	engine.Submit(newOrder(2, models.Buy, 140, 10))
+	engine.Submit(newStopOrder(3, models.Sell, models.Stop, 0, 145, 5))
+
+	// A trade at 144 reaches the sell stop's 145 trigger
+	result := engine.Submit(newOrder(4, models.Sell, 144, 10))
+
+	require.Len(t, result.Trades, 2)
+	assert.Equal(t, int64(4), result.Trades[0].SellOrderID)
+	assert.Equal(t, int64(3), result.Trades[1].SellOrderID)
+	assert.Equal(t, price(140), result.Trades[1].Price)
+
+	require.Len(t, result.Triggers, 1)
+	assert.Equal(t, models.OrderTrigger{
+		OrderID:       3,
+		Kind:          models.Stop,
+		ActivatedKind: models.Market,
+		TriggerPrice:  price(145),
+		LastPrice:     price(144),
+		Reason:        "last trade price 144 is at or below trigger price 145",
+		TriggeredAt:   executedAt,
+	}, result.Triggers[0])
+
+	// The submitted order comes first; the triggered stop is converted and filled
+	require.Len(t, result.Updated, 4)
+	assert.Equal(t, int64(4), result.Updated[0].ID)
+	stop := result.Updated[2]
+	assert.Equal(t, int64(3), stop.ID)
+	assert.Equal(t, models.Market, stop.Kind)
+	assert.Equal(t, models.StatusFilled, stop.Status)
+	require.NotNil(t, stop.TriggeredAt)
+
+	assert.Empty(t, engine.Stops("AAPL"))
+}
+
+func TestSubmitStopLimitRestsAfterTrigger(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 5))
+	engine.Submit(newStopOrder(2, models.Buy, models.StopLimit, 149, 150, 5))
+
+	result := engine.Submit(newOrder(3, models.Buy, 150, 5))
+
+	// The stop-limit triggers at 150 but its 149 limit does not cross, so it rests
+	require.Len(t, result.Triggers, 1)
+	assert.Equal(t, models.Limit, result.Triggers[0].ActivatedKind)
+	require.Len(t, result.Updated, 3)
+	assert.Equal(t, models.Limit, result.Updated[2].Kind)
+	assert.Equal(t, models.StatusNew, result.Updated[2].Status)
+
+	bids, _ := engine.Depth("AAPL")
+	require.Len(t, bids, 1)
+	assert.Equal(t, int64(2), bids[0].ID)
+}
+
+func TestSubmitStopAlreadyThroughTriggersImmediately(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Sell, 150, 5))
+	engine.Submit(newOrder(2, models.Buy, 150, 2))
+
+	result := engine.Submit(newStopOrder(3, models.Buy, models.Stop, 0, 149, 3))
+
+	require.Len(t, result.Triggers, 1)
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, models.StatusFilled, result.Order.Status)
+	assert.Equal(t, models.Market, result.Order.Kind)
+	assert.Equal(t, int64(3), result.Updated[0].ID)
+}
+
+func TestSubmitCascadesTriggeredStops(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newOrder(1, models.Buy, 145, 5))
+	engine.Submit(newOrder(2, models.Buy, 140, 5))
+	engine.Submit(newStopOrder(3, models.Sell, models.Stop, 0, 145, 5))
+	engine.Submit(newStopOrder(4, models.Sell, models.Stop, 0, 140, 5))
+
+	// 145 triggers the first stop, whose fill at 140 triggers the second
+	result := engine.Submit(newOrder(5, models.Sell, 145, 5))
+
+	require.Len(t, result.Triggers, 2)
+	assert.Equal(t, int64(3), result.Triggers[0].OrderID)
+	assert.Equal(t, int64(4), result.Triggers[1].OrderID)
+
+	// The second stop found no bids left and is rejected as a market order
+	last := result.Updated[len(result.Updated)-1]
+	assert.Equal(t, int64(4), last.ID)
+	assert.Equal(t, models.StatusRejected, last.Status)
+}
+
+func TestCancelRemovesUntriggeredStop(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Submit(newStopOrder(1, models.Sell, models.Stop, 0, 145, 5))
+
+	assert.True(t, engine.Cancel("AAPL", 1))
+	assert.Empty(t, engine.Stops("AAPL"))
+}
+
+func TestLoadHoldsStops(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Load([]models.Order{
+		newOrder(1, models.Buy, 145, 5),
+		newStopOrder(2, models.Sell, models.StopLimit, 144, 145, 5),
+	}, nil)
+
+	bids, asks := engine.Depth("AAPL")
+	assert.Len(t, bids, 1)
+	assert.Empty(t, asks)
+	assert.Len(t, engine.Stops("AAPL"), 1)
+}
+
+func TestLoadedStopTriggersOnNextTrade(t *testing.T) {
+	engine := newTestEngine()
+
+	// The book last traded at 150 before the restart
+	engine.Load([]models.Order{
+		newOrder(1, models.Buy, 146, 5),
+		newOrder(2, models.Buy, 145, 5),
+		newOrder(3, models.Buy, 140, 5),
+		newStopOrder(4, models.Sell, models.Stop, 0, 145, 5),
+	}, []models.Trade{{Symbol: "AAPL", Price: price(150)}})
+
+	// A trade above the trigger leaves the stop parked
+	result := engine.Submit(newOrder(5, models.Sell, 146, 5))
+	require.Len(t, result.Trades, 1)
+	assert.Empty(t, result.Triggers)
+	assert.Len(t, engine.Stops("AAPL"), 1)
+
+	// The next trade reaching it triggers it against the remaining bids
+	result = engine.Submit(newOrder(6, models.Sell, 145, 5))
+	require.Len(t, result.Triggers, 1)
+	assert.Equal(t, int64(4), result.Triggers[0].OrderID)
+	assert.Equal(t, price(145), result.Triggers[0].LastPrice)
+	require.Len(t, result.Trades, 2)
+	assert.Equal(t, int64(3), result.Trades[1].BuyOrderID)
+	assert.Equal(t, price(140), result.Trades[1].Price)
+	assert.Empty(t, engine.Stops("AAPL"))
+}
+
+func TestLoadRestoresLastTradePrice(t *testing.T) {
+	engine := newTestEngine()
+
+	engine.Load([]models.Order{
+		newOrder(1, models.Buy, 140, 5),
+	}, []models.Trade{{Symbol: "AAPL", Price: price(144)}})
+
+	// A stop the last trade before the restart already reached triggers at once
+	result := engine.Submit(newStopOrder(2, models.Sell, models.Stop, 0, 145, 5))
+	require.Len(t, result.Triggers, 1)
+	assert.Equal(t, price(144), result.Triggers[0].LastPrice)
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, price(140), result.Trades[0].Price)
+
+	// A symbol without trades has no last price, so its stops wait
+	msft := newStopOrder(3, models.Sell, models.Stop, 0, 145, 5)
+	msft.Symbol = "MSFT"
+	result = engine.Submit(msft)
+	assert.Empty(t, result.Triggers)
+	assert.Len(t, engine.Stops("MSFT"), 1)
+}
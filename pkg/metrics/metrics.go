@@ -0,0 +1,63 @@
+// Package metrics defines the Prometheus metrics the API exposes on /metrics.
+// They are registered with the default registry when the package loads.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace prefixes every metric the API defines
+const namespace = "trade_orders"
+
+var (
+	// HTTPRequests counts handled requests by method, route template and status
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests handled, by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes request latency by method, route template and status
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency, by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// RepositoryCallDuration observes repository call latency by repository and method
+	RepositoryCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "repository",
+		Name:      "call_duration_seconds",
+		Help:      "Repository call latency, by repository and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"repository", "method"})
+
+	// RepositoryErrors counts failed repository calls by repository and
+	// method. Not found, conflicts and other outcomes the caller caused are
+	// not failures.
+	RepositoryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "repository",
+		Name:      "errors_total",
+		Help:      "Repository calls that failed, by repository and method; not found and conflicts are not counted.",
+	}, []string{"repository", "method"})
+
+	// OrdersCreated counts stored orders by symbol and side
+	OrdersCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Orders created, by symbol and side.",
+	}, []string{"symbol", "side"})
+
+	// TradesExecuted counts executions by symbol
+	TradesExecuted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "trades_executed_total",
+		Help:      "Trades executed, by symbol.",
+	}, []string{"symbol"})
+)
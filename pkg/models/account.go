@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// Account owns orders. Callers act for an account named by their API key or
+// by a claim of their token, and only see that account's orders.
+type Account struct {
+	ID        string    `json:"id" db:"id" example:"acme-trading"`
+	Name      string    `json:"name" db:"name" example:"Acme Trading LLC"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// AccountRequest opens a new account. The ID must match what API keys and
+// token claims use to name it.
+type AccountRequest struct {
+	ID   string `json:"id" binding:"required,max=64,printascii" example:"acme-trading"`
+	Name string `json:"name" binding:"required,max=100" example:"Acme Trading LLC"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Scope is a permission granted to an API key
+type Scope string
+
+const (
+	// ScopeOrdersRead allows reading orders and trades
+	ScopeOrdersRead Scope = "orders:read"
+	// ScopeOrdersWrite allows creating, amending and cancelling orders
+	ScopeOrdersWrite Scope = "orders:write"
+	// ScopeAdmin allows managing instruments and API keys, and grants every other scope
+	ScopeAdmin Scope = "admin"
+)
+
+// Scopes is a set of scopes, stored as a Postgres text array
+type Scopes []Scope
+
+// Has reports whether the scopes grant scope. Admin grants every scope.
+func (s Scopes) Has(scope Scope) bool {
+	for _, granted := range s {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Value writes the scopes to the database as a text array
+func (s Scopes) Value() (driver.Value, error) {
+	values := make(pq.StringArray, len(s))
+	for i, scope := range s {
+		values[i] = string(scope)
+	}
+	return values.Value()
+}
+
+// Scan reads a text array column
+func (s *Scopes) Scan(src interface{}) error {
+	var values pq.StringArray
+	if err := values.Scan(src); err != nil {
+		return err
+	}
+	*s = make(Scopes, len(values))
+	for i, value := range values {
+		(*s)[i] = Scope(value)
+	}
+	return nil
+}
+
+// APIKey is a credential for the API. Only a SHA-256 hash of the key is
+// stored; Prefix keeps its first characters so it can be recognised. The
+// signing secret is stored as is, since verifying a signature needs it, and
+// is never read back with the key. Keys for an account act for it on the
+// order routes.
+type APIKey struct {
+	ID            int64      `json:"id" db:"id"`
+	Name          string     `json:"name" db:"name"`
+	AccountID     *string    `json:"account_id,omitempty" db:"account_id" example:"acme-trading"`
+	Prefix        string     `json:"prefix" db:"prefix"`
+	KeyHash       string     `json:"-" db:"key_hash"`
+	SigningSecret string     `json:"-" db:"signing_secret"`
+	Scopes        Scopes     `json:"scopes" db:"scopes" swaggertype:"array,string"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	RotatedAt     *time.Time `json:"rotated_at" db:"rotated_at"`
+	RevokedAt     *time.Time `json:"revoked_at" db:"revoked_at"`
+}
+
+// APIKeyRequest creates an API key, acting for AccountID if set
+type APIKeyRequest struct {
+	Name      string  `json:"name" binding:"required,max=100" example:"trading-bot"`
+	AccountID string  `json:"account_id" binding:"omitempty,max=64" example:"acme-trading"`
+	Scopes    []Scope `json:"scopes" binding:"required,min=1,dive,oneof=orders:read orders:write admin" swaggertype:"array,string" example:"orders:read,orders:write"`
+}
+
+// APIKeySecret is an API key together with its secrets, which are only ever
+// returned when the key is created or rotated
+type APIKeySecret struct {
+	APIKey
+	Key           string `json:"key" example:"ak_3f9c1b2d7e4a5c6b8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c"`
+	SigningSecret string `json:"signing_secret" example:"9d2f4c6e8a0b1d3f5a7c9e1b3d5f7a9c0e2b4d6f8a1c3e5b7d9f0a2c4e6b8d1f"`
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller, e.g. api-key:12
+	Subject string
+	// Name is a human-readable label for the caller
+	Name string
+	// Account is the account the caller acts for, if its credential names one
+	Account string
+	// APIKeyID is the stored API key the caller authenticated with, or 0
+	APIKeyID int64
+	// AdminKey is set when the caller authenticated with ADMIN_API_KEY, which
+	// has no stored record and so no signing secret
+	AdminKey bool
+	Scopes   Scopes
+}
@@ -0,0 +1,260 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DecimalPrecision is the number of digits a Decimal holds in total. It
+// matches the DECIMAL(12, 4) price columns.
+const DecimalPrecision = 12
+
+// DecimalScale is the number of fractional digits a Decimal holds. It matches
+// the DECIMAL(12, 4) price columns.
+const DecimalScale = 4
+
+// decimalFactor is 10^DecimalScale
+const decimalFactor = 10000
+
+// ErrInvalidDecimal is returned when a value is not a decimal number with at
+// most DecimalPrecision-DecimalScale whole and DecimalScale fractional digits
+var ErrInvalidDecimal = fmt.Errorf("invalid decimal: expected a number with at most %d digits before and %d after the decimal point", DecimalPrecision-DecimalScale, DecimalScale)
+
+// Decimal is an exact fixed-point number with DecimalScale fractional digits,
+// used for prices. It scans from and writes to DECIMAL columns and marshals to
+// a JSON number without ever passing through a float. The zero value is 0, and
+// two Decimals holding the same number are ==.
+type Decimal struct {
+	units int64
+}
+
+// NewDecimal returns value * 10^-scale, e.g. NewDecimal(15050, 2) is 150.50.
+// scale must be between 0 and DecimalScale.
+func NewDecimal(value int64, scale int32) Decimal {
+	if scale < 0 || scale > DecimalScale {
+		panic(fmt.Sprintf("models: decimal scale %d out of range", scale))
+	}
+	return Decimal{units: value * pow10(DecimalScale-scale)}
+}
+
+// ParseDecimal parses a plain decimal string such as "150.5" or "-0.0001".
+// Values that would not fit the DECIMAL(12, 4) columns are rejected.
+func ParseDecimal(s string) (Decimal, error) {
+	negative := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	significant := strings.TrimLeft(whole, "0")
+	if whole == "" || (hasPoint && fraction == "") || len(fraction) > DecimalScale || len(significant) > DecimalPrecision-DecimalScale {
+		return Decimal{}, ErrInvalidDecimal
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return Decimal{}, ErrInvalidDecimal
+	}
+
+	fraction += strings.Repeat("0", DecimalScale-len(fraction))
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Decimal{}, ErrInvalidDecimal
+	}
+	if negative {
+		units = -units
+	}
+	return Decimal{units: units}, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on invalid input. It is
+// meant for constants and tests.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(fmt.Sprintf("models: %v: %q", err, s))
+	}
+	return d
+}
+
+// Cmp returns -1, 0 or +1 as d is less than, equal to or greater than other
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.units < other.units:
+		return -1
+	case d.units > other.units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Equal reports whether d and other are the same number
+func (d Decimal) Equal(other Decimal) bool {
+	return d.units == other.units
+}
+
+// Sign returns -1, 0 or +1 as d is negative, zero or positive
+func (d Decimal) Sign() int {
+	return d.Cmp(Decimal{})
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// Scale returns the number of fractional digits needed to write d exactly,
+// e.g. 2 for 150.25 and 0 for 150
+func (d Decimal) Scale() int32 {
+	units := d.units
+	scale := int32(DecimalScale)
+	for scale > 0 && units%10 == 0 {
+		units /= 10
+		scale--
+	}
+	return scale
+}
+
+// IsMultipleOf reports whether d is a whole number of steps, e.g. whether a
+// price sits on a tick. A non-positive step matches nothing.
+func (d Decimal) IsMultipleOf(step Decimal) bool {
+	return step.units > 0 && d.units%step.units == 0
+}
+
+// Units returns d as an integer count of 10^-DecimalScale
+func (d Decimal) Units() int64 {
+	return d.units
+}
+
+// Float64 returns the nearest float64 to d, for reporting only
+func (d Decimal) Float64() float64 {
+	return float64(d.units) / decimalFactor
+}
+
+// String writes d in its shortest exact form, e.g. "150.5"
+func (d Decimal) String() string {
+	units := d.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	whole := units / decimalFactor
+	fraction := units % decimalFactor
+	if fraction == 0 {
+		return fmt.Sprintf("%s%d", sign, whole)
+	}
+	digits := strings.TrimRight(fmt.Sprintf("%0*d", DecimalScale, fraction), "0")
+	return fmt.Sprintf("%s%d.%s", sign, whole, digits)
+}
+
+// MarshalJSON writes d as a JSON number
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number, or a string holding one, exactly
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	// Only a balanced pair of quotes is stripped; a stray one fails to parse
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+	parsed, err := ParseDecimal(string(data))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// UnmarshalText reads a decimal from query and form values
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value writes d to the database as an exact decimal string
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a DECIMAL column. Postgres sends the exact text; floats and
+// integers are accepted for drivers that convert numerics.
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return d.UnmarshalText(v)
+	case string:
+		return d.UnmarshalText([]byte(v))
+	case int64:
+		*d = Decimal{units: v * decimalFactor}
+		return nil
+	case float64:
+		*d = Decimal{units: int64(math.Round(v * decimalFactor))}
+		return nil
+	case nil:
+		return errors.New("models: cannot scan NULL into Decimal")
+	default:
+		return fmt.Errorf("models: cannot scan %T into Decimal", src)
+	}
+}
+
+// PriceScales limits how many decimal places prices may use per symbol.
+// Symbols that are not listed allow the full DecimalScale.
+type PriceScales map[string]int32
+
+// For returns the number of decimal places allowed for symbol's prices
+func (s PriceScales) For(symbol string) int32 {
+	if scale, ok := s[symbol]; ok {
+		return scale
+	}
+	return DecimalScale
+}
+
+// ParsePriceScales reads comma-separated SYMBOL=scale pairs, e.g. "AAPL=2,EURUSD=4"
+func ParsePriceScales(value string) (PriceScales, error) {
+	scales := PriceScales{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		symbol, rawScale, ok := strings.Cut(pair, "=")
+		scale, err := strconv.ParseInt(rawScale, 10, 32)
+		if !ok || symbol == "" || err != nil || scale < 0 || scale > DecimalScale {
+			return nil, fmt.Errorf("invalid price scale %q: expected SYMBOL=0..%d", pair, DecimalScale)
+		}
+		scales[symbol] = int32(scale)
+	}
+	return scales, nil
+}
+
+// pow10 returns 10^n for small non-negative n
+func pow10(n int32) int64 {
+	result := int64(1)
+	for i := int32(0); i < n; i++ {
+		result *= 10
+	}
+	return result
+}
+
+// isDigits reports whether s holds only ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
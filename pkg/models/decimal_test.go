@@ -0,0 +1,157 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDecimal(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+		scale    int32
+	}{
+		{"150.5", "150.5", 1},
+		{"150.50", "150.5", 1},
+		{"150", "150", 0},
+		{"0.0001", "0.0001", 4},
+		{"-12.25", "-12.25", 2},
+		{"150.4999", "150.4999", 4},
+		{"99999999.9999", "99999999.9999", 4},
+		{"000150.5", "150.5", 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			d, err := ParseDecimal(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, d.String())
+			assert.Equal(t, tc.scale, d.Scale())
+		})
+	}
+}
+
+func TestParseDecimalInvalid(t *testing.T) {
+	for _, input := range []string{"", "-", ".5", "1.", "1.23456", "1e2", "abc", "1.2.3", "12345678901234567", "123456789.5", "-100000000"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseDecimal(input)
+			assert.ErrorIs(t, err, ErrInvalidDecimal)
+		})
+	}
+}
+
+func TestDecimalCompare(t *testing.T) {
+	a := MustParseDecimal("150.1")
+	b := MustParseDecimal("150.10")
+
+	assert.True(t, a == b)
+	assert.True(t, a.Equal(b))
+	assert.Equal(t, -1, a.Cmp(MustParseDecimal("150.1001")))
+	assert.Equal(t, 1, a.Cmp(NewDecimal(150, 0)))
+	assert.Equal(t, 0, Decimal{}.Sign())
+	assert.Equal(t, NewDecimal(15050, 2), MustParseDecimal("150.5"))
+}
+
+func TestDecimalIsMultipleOf(t *testing.T) {
+	tick := MustParseDecimal("0.05")
+
+	assert.True(t, MustParseDecimal("150.25").IsMultipleOf(tick))
+	assert.True(t, NewDecimal(150, 0).IsMultipleOf(tick))
+	assert.False(t, MustParseDecimal("150.26").IsMultipleOf(tick))
+	assert.False(t, MustParseDecimal("150.25").IsMultipleOf(Decimal{}))
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var payload struct {
+		Price    Decimal  `json:"price"`
+		Quoted   Decimal  `json:"quoted"`
+		Optional *Decimal `json:"optional"`
+	}
+
+	// Numbers are read from their literal text, never through a float
+	err := json.Unmarshal([]byte(`{"price": 150.4999, "quoted": "0.1", "optional": null}`), &payload)
+	require.NoError(t, err)
+	assert.Equal(t, "150.4999", payload.Price.String())
+	assert.Equal(t, "0.1", payload.Quoted.String())
+	assert.Nil(t, payload.Optional)
+
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"price": 150.4999, "quoted": 0.1, "optional": null}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"price": 1.23456}`), &payload))
+}
+
+func TestDecimalUnmarshalJSONQuotes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected string
+		valid    bool
+	}{
+		{"Number", `12.5`, "12.5", true},
+		{"Quoted", `"12.5"`, "12.5", true},
+		{"Leading quote only", `"12.5`, "", false},
+		{"Trailing quote only", `12.5"`, "", false},
+		{"Lone quote", `"`, "", false},
+		{"Empty string", `""`, "", false},
+		{"Doubled quotes", `""12.5""`, "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var d Decimal
+			err := d.UnmarshalJSON([]byte(tc.data))
+			if !tc.valid {
+				assert.ErrorIs(t, err, ErrInvalidDecimal)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, d.String())
+		})
+	}
+}
+
+func TestDecimalScan(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      interface{}
+		expected string
+	}{
+		{"Postgres text", []byte("150.5000"), "150.5"},
+		{"String", "0.1000", "0.1"},
+		{"Integer", int64(150), "150"},
+		{"Float", 150.49999999, "150.5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var d Decimal
+			require.NoError(t, d.Scan(tc.src))
+			assert.Equal(t, tc.expected, d.String())
+		})
+	}
+
+	var d Decimal
+	assert.Error(t, d.Scan(nil))
+
+	value, err := MustParseDecimal("150.5").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "150.5", value)
+}
+
+func TestParsePriceScales(t *testing.T) {
+	scales, err := ParsePriceScales("AAPL=2, EURUSD=4,")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), scales.For("AAPL"))
+	assert.Equal(t, int32(4), scales.For("EURUSD"))
+	assert.Equal(t, int32(DecimalScale), scales.For("MSFT"))
+
+	for _, value := range []string{"AAPL", "AAPL=x", "=2", "AAPL=5", "AAPL=-1"} {
+		_, err := ParsePriceScales(value)
+		assert.Error(t, err, value)
+	}
+}
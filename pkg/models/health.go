@@ -0,0 +1,21 @@
+package models
+
+// Health statuses reported by the liveness and readiness endpoints
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+	HealthDraining    = "draining"
+)
+
+// CheckResult is the state of one dependency the API needs to serve traffic
+type CheckResult struct {
+	Status string `json:"status" example:"ok"`
+	Error  string `json:"error,omitempty" example:"connection refused"`
+}
+
+// HealthResponse reports whether the API is alive or ready, with the result
+// of each dependency check for readiness
+type HealthResponse struct {
+	Status string                 `json:"status" example:"ok"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
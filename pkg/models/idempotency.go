@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// IdempotencyKey records a client-supplied Idempotency-Key, scoped to the
+// Subject of the caller that sent it, a hash of the request it was first used
+// with and, once that request succeeded, the response to replay. StatusCode is
+// nil while the first request is in flight.
+type IdempotencyKey struct {
+	Subject      string     `json:"subject" db:"subject"`
+	Key          string     `json:"key" db:"key"`
+	RequestHash  string     `json:"request_hash" db:"request_hash"`
+	StatusCode   *int       `json:"status_code" db:"status_code"`
+	ResponseBody []byte     `json:"-" db:"response_body"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	CompletedAt  *time.Time `json:"completed_at" db:"completed_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+)
+
+// Instrument is a tradable symbol together with the rules its orders must follow
+type Instrument struct {
+	Symbol      string    `json:"symbol" db:"symbol"`
+	Description string    `json:"description" db:"description"`
+	Currency    string    `json:"currency" db:"currency"`
+	TickSize    Decimal   `json:"tick_size" db:"tick_size" swaggertype:"number"`
+	LotSize     int       `json:"lot_size" db:"lot_size"`
+	MinQuantity int       `json:"min_quantity" db:"min_quantity"`
+	MaxQuantity int       `json:"max_quantity" db:"max_quantity"`
+	Tradable    bool      `json:"tradable" db:"tradable"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// InstrumentSpec holds the editable terms of an instrument. A zero
+// MaxQuantity leaves the order size unbounded and Tradable defaults to true.
+type InstrumentSpec struct {
+	Description string  `json:"description" binding:"max=255" example:"Apple Inc."`
+	Currency    string  `json:"currency" binding:"required,len=3,uppercase" example:"USD"`
+	TickSize    Decimal `json:"tick_size" binding:"required,gt=0" swaggertype:"number" example:"0.01"`
+	LotSize     int     `json:"lot_size" binding:"required,gt=0" example:"1"`
+	MinQuantity int     `json:"min_quantity" binding:"omitempty,gt=0" example:"1"`
+	MaxQuantity int     `json:"max_quantity" binding:"omitempty,gtefield=MinQuantity" example:"100000"`
+	Tradable    *bool   `json:"tradable" example:"true"`
+}
+
+// InstrumentRequest lists a new instrument
+type InstrumentRequest struct {
+	Symbol string `json:"symbol" binding:"required,max=20,uppercase" example:"AAPL"`
+	InstrumentSpec
+}
+
+// Apply copies the spec onto instrument, defaulting the minimum quantity to
+// one lot and tradable to true
+func (s InstrumentSpec) Apply(instrument *Instrument) {
+	instrument.Description = s.Description
+	instrument.Currency = s.Currency
+	instrument.TickSize = s.TickSize
+	instrument.LotSize = s.LotSize
+	instrument.MinQuantity = s.MinQuantity
+	if instrument.MinQuantity == 0 {
+		instrument.MinQuantity = s.LotSize
+	}
+	instrument.MaxQuantity = s.MaxQuantity
+	instrument.Tradable = s.Tradable == nil || *s.Tradable
+}
@@ -12,20 +12,180 @@ const (
 	Sell OrderType = "SELL"
 )
 
+// OrderKind represents how an order is priced
+type OrderKind string
+
+const (
+	// Market orders take liquidity at the best available prices and never rest on the book
+	Market OrderKind = "MARKET"
+	// Limit orders trade at their price or better; any remainder rests on the book
+	Limit OrderKind = "LIMIT"
+	// Stop orders wait for the last trade price to reach their trigger, then become market orders
+	Stop OrderKind = "STOP"
+	// StopLimit orders wait for the last trade price to reach their trigger, then become limit orders
+	StopLimit OrderKind = "STOP_LIMIT"
+)
+
+// IsStop reports whether orders of this kind wait for a trigger before they can trade
+func (k OrderKind) IsStop() bool {
+	return k == Stop || k == StopLimit
+}
+
+// TimeInForce represents how long an order stays working
+type TimeInForce string
+
+const (
+	// Day orders expire at the end of the trading day (midnight UTC)
+	Day TimeInForce = "DAY"
+	// GoodTillCancel orders work until filled or cancelled
+	GoodTillCancel TimeInForce = "GTC"
+	// ImmediateOrCancel orders trade what they can on arrival; the rest is cancelled
+	ImmediateOrCancel TimeInForce = "IOC"
+	// FillOrKill orders trade in full on arrival or not at all
+	FillOrKill TimeInForce = "FOK"
+	// GoodTillDate orders work until their expires_at
+	GoodTillDate TimeInForce = "GTD"
+)
+
+// OrderStatus represents the lifecycle state of an order
+type OrderStatus string
+
+const (
+	StatusNew             OrderStatus = "NEW"
+	StatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	StatusFilled          OrderStatus = "FILLED"
+	StatusCancelled       OrderStatus = "CANCELLED"
+	StatusRejected        OrderStatus = "REJECTED"
+	StatusExpired         OrderStatus = "EXPIRED"
+)
+
 // Order represents a trade order
 type Order struct {
-	ID        int64     `json:"id" db:"id"`
-	Symbol    string    `json:"symbol" db:"symbol"`
-	Price     float64   `json:"price" db:"price"`
-	Quantity  int       `json:"quantity" db:"quantity"`
-	OrderType OrderType `json:"order_type" db:"order_type"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID             int64       `json:"id" db:"id"`
+	AccountID      *string     `json:"account_id,omitempty" db:"account_id" example:"acme-trading"`
+	ClientOrderID  *string     `json:"client_order_id,omitempty" db:"client_order_id" example:"oms-20250102-0001"`
+	Symbol         string      `json:"symbol" db:"symbol"`
+	Price          Decimal     `json:"price" db:"price" swaggertype:"number"`
+	Quantity       int         `json:"quantity" db:"quantity"`
+	OrderType      OrderType   `json:"order_type" db:"order_type"`
+	Kind           OrderKind   `json:"kind" db:"kind" example:"LIMIT"`
+	TimeInForce    TimeInForce `json:"time_in_force" db:"time_in_force" example:"GTC"`
+	ExpiresAt      *time.Time  `json:"expires_at,omitempty" db:"expires_at"`
+	TriggerPrice   *Decimal    `json:"trigger_price,omitempty" db:"trigger_price" example:"145.00" swaggertype:"number"`
+	TriggeredAt    *time.Time  `json:"triggered_at,omitempty" db:"triggered_at"`
+	Status         OrderStatus `json:"status" db:"status" example:"NEW"`
+	FilledQuantity int         `json:"filled_quantity" db:"filled_quantity" example:"0"`
+	Version        int         `json:"version" db:"version" example:"1"`
+	CreatedAt      time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at" db:"updated_at"`
 }
 
-// OrderRequest represents the order creation request
+// Remaining returns the quantity still open for execution
+func (o Order) Remaining() int {
+	return o.Quantity - o.FilledQuantity
+}
+
+// OrderRequest represents the order creation request. Kind defaults to LIMIT;
+// price is required for limit and stop-limit orders and must be omitted for
+// market and stop orders. TriggerPrice is required for (and only allowed with)
+// STOP and STOP_LIMIT.
+// TimeInForce defaults to GTC for limit orders and IOC for market orders, and
+// ExpiresAt is required for (and only allowed with) GTD.
 type OrderRequest struct {
-	Symbol    string    `json:"symbol" binding:"required" example:"AAPL"`
-	Price     float64   `json:"price" binding:"required,gt=0" example:"150.50"`
-	Quantity  int       `json:"quantity" binding:"required,gt=0" example:"10"`
-	OrderType OrderType `json:"order_type" binding:"required,oneof=BUY SELL" example:"BUY"`
+	ClientOrderID string      `json:"client_order_id" binding:"omitempty,max=64,printascii" example:"oms-20250102-0001"`
+	Symbol        string      `json:"symbol" binding:"required" example:"AAPL"`
+	Price         Decimal     `json:"price" binding:"required_unless=Kind MARKET Kind STOP,excluded_if=Kind MARKET,excluded_if=Kind STOP,omitempty,gt=0" example:"150.50" swaggertype:"number"`
+	Quantity      int         `json:"quantity" binding:"required,gt=0" example:"10"`
+	OrderType     OrderType   `json:"order_type" binding:"required,oneof=BUY SELL" example:"BUY"`
+	Kind          OrderKind   `json:"kind" binding:"omitempty,oneof=MARKET LIMIT STOP STOP_LIMIT" example:"LIMIT"`
+	TimeInForce   TimeInForce `json:"time_in_force" binding:"omitempty,oneof=DAY GTC IOC FOK GTD" example:"GTC"`
+	ExpiresAt     *time.Time  `json:"expires_at" binding:"required_if=TimeInForce GTD,excluded_unless=TimeInForce GTD,omitempty,gt" example:"2025-01-03T21:00:00Z"`
+	TriggerPrice  *Decimal    `json:"trigger_price" binding:"required_if=Kind STOP,required_if=Kind STOP_LIMIT,omitempty,gt=0" example:"145.00" swaggertype:"number"`
+}
+
+// BatchMode selects how a batch of orders is accepted
+type BatchMode string
+
+const (
+	// AllOrNothing stores every order of a batch or none of them
+	AllOrNothing BatchMode = "all_or_nothing"
+	// BestEffort stores the valid orders of a batch and reports the rest
+	BestEffort BatchMode = "best_effort"
+)
+
+// MaxOrderBatchSize is the most orders a single batch may carry
+const MaxOrderBatchSize = 500
+
+// BatchOrderResult is the outcome of one order in a batch. Status is the
+// HTTP status the order would have received on its own: 201 when created,
+// 400 when invalid, 409 when its client order ID is already in use and 0 when
+// it was valid but not submitted because another order failed an
+// all-or-nothing batch.
+type BatchOrderResult struct {
+	Index  int               `json:"index" example:"0"`
+	Status int               `json:"status" example:"201"`
+	Order  *Order            `json:"order,omitempty"`
+	Errors []ValidationError `json:"errors,omitempty"`
+}
+
+// BatchOrderResponse lists the outcome of every order in a batch, in request order
+type BatchOrderResponse struct {
+	Results []BatchOrderResult `json:"results"`
+}
+
+// Order listing page sizes
+const (
+	DefaultOrderPageSize = 50
+	MaxOrderPageSize     = 500
+)
+
+// OrderFilter narrows, sorts and pages an order listing. Zero values leave the
+// corresponding filter unset; Sort defaults to -created_at (newest first) and
+// Limit to DefaultOrderPageSize. Status may be repeated to match any of several.
+// AccountID is only taken from the query on the admin listing; elsewhere it is
+// the caller's account.
+type OrderFilter struct {
+	AccountID string        `form:"account_id" binding:"omitempty,max=64" example:"acme-trading"`
+	Symbol    string        `form:"symbol" example:"AAPL"`
+	OrderType OrderType     `form:"order_type" binding:"omitempty,oneof=BUY SELL" example:"BUY"`
+	Status    []OrderStatus `form:"status" binding:"omitempty,dive,oneof=NEW PARTIALLY_FILLED FILLED CANCELLED REJECTED EXPIRED" example:"NEW"`
+	From      *time.Time    `form:"from" time_format:"2006-01-02T15:04:05Z07:00" example:"2025-01-02T00:00:00Z"`
+	To        *time.Time    `form:"to" time_format:"2006-01-02T15:04:05Z07:00" example:"2025-01-03T00:00:00Z"`
+	Sort      string        `form:"sort" binding:"omitempty,oneof=created_at -created_at" example:"-created_at"`
+	Limit     int           `form:"limit" binding:"omitempty,gt=0,max=500" example:"50"`
+	Cursor    string        `form:"cursor"`
+}
+
+// AmendOrderRequest represents a cancel/replace request for a live order.
+// At least one of price or quantity must be provided.
+type AmendOrderRequest struct {
+	Version  int      `json:"version" binding:"required,gt=0" example:"1"`
+	Price    *Decimal `json:"price" binding:"required_without=Quantity,omitempty,gt=0" example:"151.25" swaggertype:"number"`
+	Quantity *int     `json:"quantity" binding:"required_without=Price,omitempty,gt=0" example:"20"`
+}
+
+// OrderRevision records one amendment of an order, linking the replaced
+// terms to the terms that superseded them
+type OrderRevision struct {
+	ID               int64     `json:"id" db:"id"`
+	OrderID          int64     `json:"order_id" db:"order_id"`
+	Version          int       `json:"version" db:"version"`
+	PreviousPrice    Decimal   `json:"previous_price" db:"previous_price" swaggertype:"number"`
+	PreviousQuantity int       `json:"previous_quantity" db:"previous_quantity"`
+	Price            Decimal   `json:"price" db:"price" swaggertype:"number"`
+	Quantity         int       `json:"quantity" db:"quantity"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+}
+
+// OrderTrigger records a stop order being triggered: when it happened, the
+// last trade price that caused it, and the kind the order was converted to
+type OrderTrigger struct {
+	ID            int64     `json:"id" db:"id"`
+	OrderID       int64     `json:"order_id" db:"order_id"`
+	Kind          OrderKind `json:"kind" db:"kind" example:"STOP"`
+	ActivatedKind OrderKind `json:"activated_kind" db:"activated_kind" example:"MARKET"`
+	TriggerPrice  Decimal   `json:"trigger_price" db:"trigger_price" swaggertype:"number"`
+	LastPrice     Decimal   `json:"last_price" db:"last_price" swaggertype:"number"`
+	Reason        string    `json:"reason" db:"reason"`
+	TriggeredAt   time.Time `json:"triggered_at" db:"triggered_at"`
 }
@@ -0,0 +1,8 @@
+package models
+
+// Page is one page of a cursor-paginated listing. NextCursor is opaque and
+// empty on the last page; pass it back as the cursor parameter for the next page.
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty" example:"LWNyZWF0ZWRfYXR8MjAyNS0wMS0wMlQxNTowNDowNVp8NDI"`
+}
@@ -2,7 +2,8 @@ package models
 
 // ErrorResponse represents a standardized error response
 type ErrorResponse struct {
-	Error string `json:"error" example:"Invalid input data"`
+	Error     string `json:"error" example:"Invalid input data"`
+	RequestID string `json:"request_id,omitempty" example:"9f1c2a7e4b0d4c8e8a1f3b6d2e5c7a90"`
 }
 
 // ValidationError represents an individual validation error
@@ -13,5 +14,6 @@ type ValidationError struct {
 
 // ValidationErrorResponse represents a response with validation errors
 type ValidationErrorResponse struct {
-	Errors []ValidationError `json:"errors"`
+	Errors    []ValidationError `json:"errors"`
+	RequestID string            `json:"request_id,omitempty" example:"9f1c2a7e4b0d4c8e8a1f3b6d2e5c7a90"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Trade represents an execution between a buy and a sell order
+type Trade struct {
+	ID          int64     `json:"id" db:"id"`
+	BuyOrderID  int64     `json:"buy_order_id" db:"buy_order_id"`
+	SellOrderID int64     `json:"sell_order_id" db:"sell_order_id"`
+	Symbol      string    `json:"symbol" db:"symbol"`
+	Price       Decimal   `json:"price" db:"price" swaggertype:"number"`
+	Quantity    int       `json:"quantity" db:"quantity"`
+	ExecutedAt  time.Time `json:"executed_at" db:"executed_at"`
+}
+
+// TradeFilter narrows the trades returned by a trade listing. Zero values
+// leave the corresponding filter unset. AccountID limits the listing to trades
+// in which an order of that account took part; it is only taken from the query
+// on the admin listing, elsewhere it is the caller's account.
+type TradeFilter struct {
+	AccountID string     `form:"account_id" binding:"omitempty,max=64" example:"acme-trading"`
+	Symbol    string     `form:"symbol" example:"AAPL"`
+	OrderID   int64      `form:"order_id" binding:"omitempty,gt=0" example:"42"`
+	From      *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00" example:"2025-01-02T00:00:00Z"`
+	To        *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" example:"2025-01-03T00:00:00Z"`
+}
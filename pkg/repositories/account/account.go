@@ -0,0 +1,69 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// PostgresAccountRepository is an implementation of AccountRepository
+type PostgresAccountRepository struct {
+	DB *sqlx.DB
+}
+
+// NewAccountRepository creates a new account repository
+func NewAccountRepository(db *sqlx.DB) (AccountRepository, error) {
+	return &PostgresAccountRepository{DB: db}, nil
+}
+
+// Create opens a new account, returning ErrAccountExists if its ID is taken
+func (r *PostgresAccountRepository) Create(ctx context.Context, account *models.Account) error {
+	account.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO accounts (id, name, created_at)
+		VALUES ($1, $2, $3)
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, account.ID, account.Name, account.CreatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return ErrAccountExists
+	}
+	return err
+}
+
+// GetAll retrieves every account ordered by ID
+func (r *PostgresAccountRepository) GetAll(ctx context.Context) ([]models.Account, error) {
+	accounts := []models.Account{}
+	err := r.DB.SelectContext(ctx, &accounts, `SELECT id, name, created_at FROM accounts ORDER BY id`)
+	return accounts, err
+}
+
+// GetByID retrieves a single account, returning ErrAccountNotFound if it does not exist
+func (r *PostgresAccountRepository) GetByID(ctx context.Context, id string) (*models.Account, error) {
+	var account models.Account
+	query := `
+		SELECT id, name, created_at
+		FROM accounts
+		WHERE id = $1
+	`
+
+	err := r.DB.GetContext(ctx, &account, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
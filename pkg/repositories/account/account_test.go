@@ -0,0 +1,121 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAccount(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresAccountRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	account := &models.Account{ID: "acme", Name: "Acme Trading"}
+
+	// Setup expectations
+	mock.ExpectExec("INSERT INTO accounts").
+		WithArgs("acme", "Acme Trading", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Call the Create method
+	err = repo.Create(context.Background(), account)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, account.CreatedAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAccountDuplicate(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresAccountRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: the primary key is already taken
+	mock.ExpectExec("INSERT INTO accounts").
+		WillReturnError(&pq.Error{Code: uniqueViolation})
+
+	// Call the Create method
+	err = repo.Create(context.Background(), &models.Account{ID: "acme", Name: "Acme Trading"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrAccountExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAccounts(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresAccountRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations
+	rows := sqlmock.NewRows([]string{"id", "name", "created_at"}).
+		AddRow("acme", "Acme Trading", now).
+		AddRow("globex", "Globex", now)
+	mock.ExpectQuery("SELECT (.+) FROM accounts ORDER BY id").WillReturnRows(rows)
+
+	// Call the GetAll method
+	accounts, err := repo.GetAll(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, accounts, 2) {
+		assert.Equal(t, "acme", accounts[0].ID)
+		assert.Equal(t, "Globex", accounts[1].Name)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAccountByID(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresAccountRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: the first account exists, the second does not
+	rows := sqlmock.NewRows([]string{"id", "name", "created_at"}).AddRow("acme", "Acme Trading", time.Now())
+	mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = \\$1").WithArgs("acme").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = \\$1").WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}))
+
+	// Call the GetByID method
+	account, err := repo.GetByID(context.Background(), "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme Trading", account.Name)
+
+	_, err = repo.GetByID(context.Background(), "missing")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,22 @@
+package account
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+var (
+	// ErrAccountNotFound is returned when no account has the requested ID
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrAccountExists is returned when creating an account whose ID is already taken
+	ErrAccountExists = errors.New("account already exists")
+)
+
+// AccountRepository interface for account operations
+type AccountRepository interface {
+	Create(ctx context.Context, account *models.Account) error
+	GetAll(ctx context.Context) ([]models.Account, error)
+	GetByID(ctx context.Context, id string) (*models.Account, error)
+}
@@ -0,0 +1,136 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// apiKeyColumns lists the columns selected for every models.APIKey
+const apiKeyColumns = "id, name, account_id, prefix, key_hash, scopes, created_at, rotated_at, revoked_at"
+
+// foreignKeyViolation is the Postgres error code for a foreign key violation
+const foreignKeyViolation = "23503"
+
+// PostgresAPIKeyRepository is an implementation of APIKeyRepository
+type PostgresAPIKeyRepository struct {
+	DB *sqlx.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sqlx.DB) (APIKeyRepository, error) {
+	return &PostgresAPIKeyRepository{DB: db}, nil
+}
+
+// Create stores a new key, filling in its ID and creation time. It returns
+// ErrAccountNotFound if the key names an account that is not registered.
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	key.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO api_keys (name, account_id, prefix, key_hash, signing_secret, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err := r.DB.QueryRowContext(ctx, query,
+		key.Name,
+		key.AccountID,
+		key.Prefix,
+		key.KeyHash,
+		key.SigningSecret,
+		key.Scopes,
+		key.CreatedAt,
+	).Scan(&key.ID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return ErrAccountNotFound
+	}
+	return err
+}
+
+// GetAll retrieves every key, revoked ones included, ordered by ID
+func (r *PostgresAPIKeyRepository) GetAll(ctx context.Context) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	err := r.DB.SelectContext(ctx, &keys, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	return keys, err
+}
+
+// GetByHash retrieves the live key stored under keyHash
+func (r *PostgresAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`
+
+	err := r.DB.GetContext(ctx, &key, query, keyHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetSigningSecret retrieves the signing secret of a live key
+func (r *PostgresAPIKeyRepository) GetSigningSecret(ctx context.Context, id int64) (string, error) {
+	var secret string
+	query := `
+		SELECT signing_secret
+		FROM api_keys
+		WHERE id = $1 AND revoked_at IS NULL AND signing_secret IS NOT NULL
+	`
+
+	err := r.DB.GetContext(ctx, &secret, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrAPIKeyNotFound
+	}
+	return secret, err
+}
+
+// Rotate gives a live key new secrets, returning ErrAPIKeyNotFound for
+// unknown and revoked keys
+func (r *PostgresAPIKeyRepository) Rotate(ctx context.Context, id int64, prefix, keyHash, signingSecret string) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `
+		UPDATE api_keys
+		SET prefix = $1, key_hash = $2, signing_secret = $3, rotated_at = $4
+		WHERE id = $5 AND revoked_at IS NULL
+		RETURNING ` + apiKeyColumns
+
+	err := r.DB.GetContext(ctx, &key, query, prefix, keyHash, signingSecret, time.Now(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Revoke disables a live key for good, returning ErrAPIKeyNotFound for
+// unknown and already revoked keys
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	result, err := r.DB.ExecContext(ctx, `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// apiKeyColumnNames mirrors apiKeyColumns for building mocked result rows
+var apiKeyColumnNames = []string{"id", "name", "account_id", "prefix", "key_hash", "scopes", "created_at", "rotated_at", "revoked_at"}
+
+func TestCreateAPIKey(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresAPIKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	account := "acme"
+	key := &models.APIKey{
+		Name:          "trading-bot",
+		AccountID:     &account,
+		Prefix:        "ak_3f9c1b2d",
+		KeyHash:       Hash("ak_3f9c1b2d"),
+		SigningSecret: "signing-secret",
+		Scopes:        models.Scopes{models.ScopeOrdersRead, models.ScopeOrdersWrite},
+	}
+
+	// Setup expectations: scopes are written as a text array
+	mock.ExpectQuery("INSERT INTO api_keys (.+) RETURNING id").
+		WithArgs("trading-bot", &account, "ak_3f9c1b2d", key.KeyHash, "signing-secret", `{"orders:read","orders:write"}`, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	// Call the Create method
+	err = repo.Create(context.Background(), key)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), key.ID)
+	assert.False(t, key.CreatedAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAPIKeyUnknownAccount(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresAPIKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	account := "unregistered"
+
+	// Setup expectations: the account foreign key rejects the insert
+	mock.ExpectQuery("INSERT INTO api_keys (.+) RETURNING id").
+		WillReturnError(&pq.Error{Code: foreignKeyViolation})
+
+	// Call the Create method
+	err = repo.Create(context.Background(), &models.APIKey{Name: "trading-bot", AccountID: &account, Scopes: models.Scopes{models.ScopeOrdersRead}})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAPIKeyByHash(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresAPIKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	keyHash := Hash("ak_secret")
+
+	// Setup expectations: only live keys match
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE key_hash = \\$1 AND revoked_at IS NULL").
+		WithArgs(keyHash).
+		WillReturnRows(sqlmock.NewRows(apiKeyColumnNames).
+			AddRow(7, "trading-bot", "acme", "ak_3f9c1b2d", keyHash, `{orders:read,admin}`, time.Now(), nil, nil))
+
+	// Call the GetByHash method
+	key, err := repo.GetByHash(context.Background(), keyHash)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.NotNil(t, key) {
+		assert.Equal(t, int64(7), key.ID)
+		assert.Equal(t, models.Scopes{models.ScopeOrdersRead, models.ScopeAdmin}, key.Scopes)
+		assert.Equal(t, "acme", *key.AccountID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAPIKeyByHashNotFound(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresAPIKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: unknown or revoked
+	mock.ExpectQuery("SELECT (.+) FROM api_keys").
+		WillReturnRows(sqlmock.NewRows(apiKeyColumnNames))
+
+	// Call the GetByHash method
+	key, err := repo.GetByHash(context.Background(), Hash("ak_unknown"))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+	assert.Nil(t, key)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresAPIKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations
+	mock.ExpectQuery("UPDATE api_keys SET prefix = \\$1, key_hash = \\$2, signing_secret = \\$3, rotated_at = \\$4 WHERE id = \\$5 AND revoked_at IS NULL RETURNING (.+)").
+		WithArgs("ak_0a1b2c3d", "new-hash", "new-secret", sqlmock.AnyArg(), int64(7)).
+		WillReturnRows(sqlmock.NewRows(apiKeyColumnNames).
+			AddRow(7, "trading-bot", nil, "ak_0a1b2c3d", "new-hash", `{orders:read}`, now.Add(-time.Hour), now, nil))
+
+	// Call the Rotate method
+	key, err := repo.Rotate(context.Background(), 7, "ak_0a1b2c3d", "new-hash", "new-secret")
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.NotNil(t, key) {
+		assert.Equal(t, "ak_0a1b2c3d", key.Prefix)
+		assert.NotNil(t, key.RotatedAt)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSigningSecret(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresAPIKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: key 7 is live, key 8 is revoked or has no secret
+	mock.ExpectQuery("SELECT signing_secret FROM api_keys WHERE id = \\$1 AND revoked_at IS NULL AND signing_secret IS NOT NULL").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"signing_secret"}).AddRow("signing-secret"))
+	mock.ExpectQuery("SELECT signing_secret FROM api_keys").
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"signing_secret"}))
+
+	// Call the GetSigningSecret method
+	secret, err := repo.GetSigningSecret(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "signing-secret", secret)
+
+	_, err = repo.GetSigningSecret(context.Background(), 8)
+	assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{name: "Live key", affected: 1, wantErr: nil},
+		{name: "Unknown or revoked key", affected: 0, wantErr: ErrAPIKeyNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new mock database
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+
+			// Create repository with the mock
+			repo := &PostgresAPIKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+			// Setup expectations
+			mock.ExpectExec("UPDATE api_keys SET revoked_at = \\$1 WHERE id = \\$2 AND revoked_at IS NULL").
+				WithArgs(sqlmock.AnyArg(), int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, tc.affected))
+
+			// Call the Revoke method
+			err = repo.Revoke(context.Background(), 7)
+
+			// Assert
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	key, prefix, keyHash, err := Generate()
+	assert.NoError(t, err)
+
+	// Assert: the prefix is the start of the key and the hash is reproducible
+	assert.True(t, strings.HasPrefix(key, keyMarker))
+	assert.Len(t, key, len(keyMarker)+64)
+	assert.Equal(t, key[:prefixLength], prefix)
+	assert.Equal(t, Hash(key), keyHash)
+	assert.NotEqual(t, key, keyHash)
+
+	other, _, _, err := Generate()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
@@ -0,0 +1,31 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// ErrAPIKeyNotFound is returned when no live API key matches
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// ErrAccountNotFound is returned when a new key names an account that is not registered
+var ErrAccountNotFound = errors.New("account not found")
+
+// APIKeyRepository interface for API key operations
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetAll(ctx context.Context) ([]models.APIKey, error)
+	// GetByHash retrieves the live key with the given hash, returning
+	// ErrAPIKeyNotFound for unknown and revoked keys
+	GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	// GetSigningSecret retrieves the request signing secret of a live key,
+	// returning ErrAPIKeyNotFound for unknown and revoked keys and keys issued
+	// before request signing
+	GetSigningSecret(ctx context.Context, id int64) (string, error)
+	// Rotate replaces the hash, prefix and signing secret of a live key, so
+	// its previous secrets stop working at once
+	Rotate(ctx context.Context, id int64, prefix, keyHash, signingSecret string) (*models.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+}
@@ -0,0 +1,40 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	// keyMarker starts every generated key, so leaked keys are easy to spot
+	keyMarker = "ak_"
+	// prefixLength is how much of a key is kept in the clear to recognise it
+	prefixLength = len(keyMarker) + 8
+)
+
+// Generate returns a new random API key together with its prefix and hash
+func Generate() (key, prefix, keyHash string, err error) {
+	var secret [32]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return "", "", "", err
+	}
+	key = keyMarker + hex.EncodeToString(secret[:])
+	return key, key[:prefixLength], Hash(key), nil
+}
+
+// GenerateSigningSecret returns a new random secret for signing requests
+func GenerateSigningSecret() (string, error) {
+	var secret [32]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret[:]), nil
+}
+
+// Hash returns the hex SHA-256 hash under which key is stored. Keys carry
+// 256 random bits, so a fast hash is enough to make the stored value useless.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
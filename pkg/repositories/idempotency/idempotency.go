@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// keyColumns lists the columns selected for every models.IdempotencyKey
+const keyColumns = "subject, key, request_hash, status_code, response_body, created_at, completed_at"
+
+// PostgresKeyRepository is an implementation of KeyRepository
+type PostgresKeyRepository struct {
+	DB *sqlx.DB
+}
+
+// NewKeyRepository creates a new idempotency key repository
+func NewKeyRepository(db *sqlx.DB) (KeyRepository, error) {
+	return &PostgresKeyRepository{DB: db}, nil
+}
+
+// Reserve inserts subject's key, or takes over an expired or abandoned record
+// of it, in a single statement so that concurrent requests with the same key
+// cannot both win
+func (r *PostgresKeyRepository) Reserve(ctx context.Context, subject, key, requestHash string, now, expiredBefore, abandonedBefore time.Time) (*models.IdempotencyKey, error) {
+	query := `
+		INSERT INTO idempotency_keys (subject, key, request_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subject, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL,
+			created_at = EXCLUDED.created_at, completed_at = NULL
+		WHERE idempotency_keys.created_at < $5
+			OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < $6)
+		RETURNING key
+	`
+
+	var reserved string
+	err := r.DB.GetContext(ctx, &reserved, query, subject, key, requestHash, now, expiredBefore, abandonedBefore)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// The key is held by a live record
+	var existing models.IdempotencyKey
+	err = r.DB.GetContext(ctx, &existing, `SELECT `+keyColumns+` FROM idempotency_keys WHERE subject = $1 AND key = $2`, subject, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// Complete stores the response to replay for subject's reserved key
+func (r *PostgresKeyRepository) Complete(ctx context.Context, subject, key string, statusCode int, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_body = $2, completed_at = $3
+		WHERE subject = $4 AND key = $5 AND status_code IS NULL
+	`
+
+	result, err := r.DB.ExecContext(ctx, query, statusCode, body, time.Now().UTC(), subject, key)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// Release deletes subject's reserved key whose request did not succeed, so that it can be retried
+func (r *PostgresKeyRepository) Release(ctx context.Context, subject, key string) error {
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE subject = $1 AND key = $2 AND status_code IS NULL`, subject, key)
+	return err
+}
+
+// Purge deletes keys created before expiredBefore and returns how many were removed
+func (r *PostgresKeyRepository) Purge(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, expiredBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
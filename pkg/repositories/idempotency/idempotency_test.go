@@ -0,0 +1,154 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// keyColumnNames mirrors keyColumns for building mocked result rows
+var keyColumnNames = []string{"subject", "key", "request_hash", "status_code", "response_body", "created_at", "completed_at"}
+
+func TestReserveNewKey(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now().UTC()
+	expiredBefore := now.Add(-24 * time.Hour)
+	abandonedBefore := now.Add(-time.Minute)
+
+	// Setup expectations: the insert wins
+	mock.ExpectQuery("INSERT INTO idempotency_keys (.+) ON CONFLICT \\(subject, key\\) DO UPDATE (.+) WHERE idempotency_keys.created_at < \\$5").
+		WithArgs("api-key:1", "key-1", "hash", now, expiredBefore, abandonedBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key-1"))
+
+	// Call the Reserve method
+	existing, err := repo.Reserve(context.Background(), "api-key:1", "key-1", "hash", now, expiredBefore, abandonedBefore)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReserveHeldKey(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now().UTC()
+
+	// Setup expectations: the key is live, so the stored response is returned
+	mock.ExpectQuery("INSERT INTO idempotency_keys").
+		WillReturnRows(sqlmock.NewRows([]string{"key"}))
+	mock.ExpectQuery("SELECT (.+) FROM idempotency_keys WHERE subject = (.+) AND key = (.+)").
+		WithArgs("api-key:1", "key-1").
+		WillReturnRows(sqlmock.NewRows(keyColumnNames).
+			AddRow("api-key:1", "key-1", "hash", 201, []byte(`{"id":1}`), now.Add(-time.Minute), now.Add(-time.Minute)))
+
+	// Call the Reserve method
+	existing, err := repo.Reserve(context.Background(), "api-key:1", "key-1", "hash", now, now.Add(-24*time.Hour), now.Add(-time.Minute))
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.NotNil(t, existing) && assert.NotNil(t, existing.StatusCode) {
+		assert.Equal(t, 201, *existing.StatusCode)
+		assert.Equal(t, `{"id":1}`, string(existing.ResponseBody))
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReserveAbandonedKey(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now().UTC()
+	abandonedBefore := now.Add(-time.Minute)
+
+	// Setup expectations: a reservation without a response that is older
+	// than abandonedBefore is taken over, so no existing record is looked up
+	mock.ExpectQuery("INSERT INTO idempotency_keys (.+) OR \\(idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < \\$6\\)").
+		WithArgs("api-key:1", "key-1", "hash", now, now.Add(-24*time.Hour), abandonedBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key-1"))
+
+	// Call the Reserve method
+	existing, err := repo.Reserve(context.Background(), "api-key:1", "key-1", "hash", now, now.Add(-24*time.Hour), abandonedBefore)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompleteKey(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: the first completion lands, a second finds nothing in flight
+	mock.ExpectExec("UPDATE idempotency_keys SET (.+) WHERE subject = (.+) AND key = (.+) AND status_code IS NULL").
+		WithArgs(201, []byte(`{"id":1}`), sqlmock.AnyArg(), "api-key:1", "key-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE idempotency_keys").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Assert
+	assert.NoError(t, repo.Complete(context.Background(), "api-key:1", "key-1", 201, []byte(`{"id":1}`)))
+	assert.ErrorIs(t, repo.Complete(context.Background(), "api-key:1", "key-1", 201, []byte(`{"id":1}`)), ErrKeyNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReleaseAndPurgeKeys(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresKeyRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	expiredBefore := time.Now().UTC().Add(-24 * time.Hour)
+
+	// Setup expectations
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE subject = (.+) AND key = (.+) AND status_code IS NULL").
+		WithArgs("api-key:1", "key-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE created_at < (.+)").
+		WithArgs(expiredBefore).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	// Assert
+	assert.NoError(t, repo.Release(context.Background(), "api-key:1", "key-1"))
+	purged, err := repo.Purge(context.Background(), expiredBefore)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,26 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// ErrKeyNotFound is returned when no live idempotency key matches
+var ErrKeyNotFound = errors.New("idempotency key not found")
+
+// KeyRepository interface for idempotency key operations
+type KeyRepository interface {
+	// Reserve claims key for subject's request with requestHash. Keys are
+	// scoped to their subject, so other callers' keys never match. Keys
+	// created before expiredBefore are reclaimed, as are reservations still
+	// without a response that were made before abandonedBefore, whose request
+	// is presumed to have died. If the key is held by a live record, that
+	// record is returned instead and nothing is reserved.
+	Reserve(ctx context.Context, subject, key, requestHash string, now, expiredBefore, abandonedBefore time.Time) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, subject, key string, statusCode int, body []byte) error
+	Release(ctx context.Context, subject, key string) error
+	Purge(ctx context.Context, expiredBefore time.Time) (int64, error)
+}
@@ -0,0 +1,139 @@
+package instrument
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// instrumentColumns lists the columns selected for every models.Instrument
+const instrumentColumns = "symbol, description, currency, tick_size, lot_size, min_quantity, max_quantity, tradable, created_at, updated_at"
+
+// Postgres error codes for constraint violations
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// PostgresInstrumentRepository is an implementation of InstrumentRepository
+type PostgresInstrumentRepository struct {
+	DB *sqlx.DB
+}
+
+// NewInstrumentRepository creates a new instrument repository
+func NewInstrumentRepository(db *sqlx.DB) (InstrumentRepository, error) {
+	return &PostgresInstrumentRepository{DB: db}, nil
+}
+
+// Create lists a new instrument, returning ErrInstrumentExists if its symbol is taken
+func (r *PostgresInstrumentRepository) Create(ctx context.Context, instrument *models.Instrument) error {
+	now := time.Now()
+	instrument.CreatedAt = now
+	instrument.UpdatedAt = now
+
+	query := `
+		INSERT INTO instruments (symbol, description, currency, tick_size, lot_size, min_quantity, max_quantity, tradable, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.DB.ExecContext(ctx, query,
+		instrument.Symbol,
+		instrument.Description,
+		instrument.Currency,
+		instrument.TickSize,
+		instrument.LotSize,
+		instrument.MinQuantity,
+		instrument.MaxQuantity,
+		instrument.Tradable,
+		instrument.CreatedAt,
+		instrument.UpdatedAt,
+	)
+	if isViolation(err, uniqueViolation) {
+		return ErrInstrumentExists
+	}
+	return err
+}
+
+// GetAll retrieves every listed instrument ordered by symbol
+func (r *PostgresInstrumentRepository) GetAll(ctx context.Context) ([]models.Instrument, error) {
+	instruments := []models.Instrument{}
+	err := r.DB.SelectContext(ctx, &instruments, `SELECT `+instrumentColumns+` FROM instruments ORDER BY symbol`)
+	return instruments, err
+}
+
+// GetBySymbol retrieves a single instrument, returning ErrInstrumentNotFound if it is not listed
+func (r *PostgresInstrumentRepository) GetBySymbol(ctx context.Context, symbol string) (*models.Instrument, error) {
+	var instrument models.Instrument
+	query := `
+		SELECT ` + instrumentColumns + `
+		FROM instruments
+		WHERE symbol = $1
+	`
+
+	err := r.DB.GetContext(ctx, &instrument, query, symbol)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInstrumentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrument, nil
+}
+
+// Update replaces the terms of a listed instrument and refreshes it from the stored row
+func (r *PostgresInstrumentRepository) Update(ctx context.Context, instrument *models.Instrument) error {
+	query := `
+		UPDATE instruments
+		SET description = $1, currency = $2, tick_size = $3, lot_size = $4,
+			min_quantity = $5, max_quantity = $6, tradable = $7, updated_at = $8
+		WHERE symbol = $9
+		RETURNING ` + instrumentColumns
+
+	err := r.DB.GetContext(ctx, instrument, query,
+		instrument.Description,
+		instrument.Currency,
+		instrument.TickSize,
+		instrument.LotSize,
+		instrument.MinQuantity,
+		instrument.MaxQuantity,
+		instrument.Tradable,
+		time.Now(),
+		instrument.Symbol,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInstrumentNotFound
+	}
+	return err
+}
+
+// Delete removes an instrument. Instruments that orders reference cannot be
+// deleted and return ErrInstrumentInUse; halt them instead.
+func (r *PostgresInstrumentRepository) Delete(ctx context.Context, symbol string) error {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM instruments WHERE symbol = $1`, symbol)
+	if isViolation(err, foreignKeyViolation) {
+		return ErrInstrumentInUse
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInstrumentNotFound
+	}
+	return nil
+}
+
+// isViolation reports whether err is a Postgres error with the given code
+func isViolation(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
@@ -0,0 +1,220 @@
+package instrument
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// instrumentColumnNames mirrors instrumentColumns for building mocked result rows
+var instrumentColumnNames = []string{"symbol", "description", "currency", "tick_size", "lot_size", "min_quantity", "max_quantity", "tradable", "created_at", "updated_at"}
+
+func newAAPL() *models.Instrument {
+	return &models.Instrument{
+		Symbol:      "AAPL",
+		Description: "Apple Inc.",
+		Currency:    "USD",
+		TickSize:    models.MustParseDecimal("0.01"),
+		LotSize:     1,
+		MinQuantity: 1,
+		Tradable:    true,
+	}
+}
+
+func TestCreateInstrument(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresInstrumentRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	instrument := newAAPL()
+
+	// Setup expectations
+	mock.ExpectExec("INSERT INTO instruments").
+		WithArgs("AAPL", "Apple Inc.", "USD", instrument.TickSize, 1, 1, 0, true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Call the Create method
+	err = repo.Create(context.Background(), instrument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, instrument.CreatedAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateInstrumentDuplicate(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresInstrumentRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: the primary key is already taken
+	mock.ExpectExec("INSERT INTO instruments").
+		WillReturnError(&pq.Error{Code: uniqueViolation})
+
+	// Call the Create method
+	err = repo.Create(context.Background(), newAAPL())
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInstrumentExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInstrumentBySymbol(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresInstrumentRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT (.+) FROM instruments WHERE symbol = (.+)").
+		WithArgs("AAPL").
+		WillReturnRows(sqlmock.NewRows(instrumentColumnNames).
+			AddRow("AAPL", "Apple Inc.", "USD", "0.0100", 1, 1, 0, true, now, now))
+	mock.ExpectQuery("SELECT (.+) FROM instruments WHERE symbol = (.+)").
+		WithArgs("APPL").
+		WillReturnRows(sqlmock.NewRows(instrumentColumnNames))
+
+	// Call the GetBySymbol method
+	instrument, err := repo.GetBySymbol(context.Background(), "AAPL")
+	assert.NoError(t, err)
+	assert.Equal(t, models.MustParseDecimal("0.01"), instrument.TickSize)
+	assert.True(t, instrument.Tradable)
+
+	_, err = repo.GetBySymbol(context.Background(), "APPL")
+	assert.ErrorIs(t, err, ErrInstrumentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllInstruments(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresInstrumentRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT (.+) FROM instruments ORDER BY symbol").
+		WillReturnRows(sqlmock.NewRows(instrumentColumnNames).
+			AddRow("AAPL", "Apple Inc.", "USD", "0.0100", 1, 1, 0, true, now, now).
+			AddRow("MSFT", "Microsoft Corp.", "USD", "0.0100", 1, 1, 0, false, now, now))
+
+	// Call the GetAll method
+	instruments, err := repo.GetAll(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, instruments, 2)
+	assert.False(t, instruments[1].Tradable)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateInstrument(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresInstrumentRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	created := time.Now().Add(-time.Hour)
+	now := time.Now()
+	instrument := newAAPL()
+	instrument.Tradable = false
+
+	// Setup expectations: halting keeps the creation time
+	mock.ExpectQuery("UPDATE instruments SET (.+) WHERE symbol = (.+) RETURNING").
+		WithArgs("Apple Inc.", "USD", instrument.TickSize, 1, 1, 0, false, sqlmock.AnyArg(), "AAPL").
+		WillReturnRows(sqlmock.NewRows(instrumentColumnNames).
+			AddRow("AAPL", "Apple Inc.", "USD", "0.0100", 1, 1, 0, false, created, now))
+
+	// Call the Update method
+	err = repo.Update(context.Background(), instrument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, instrument.Tradable)
+	assert.Equal(t, created, instrument.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateInstrumentNotFound(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresInstrumentRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations
+	mock.ExpectQuery("UPDATE instruments").
+		WillReturnRows(sqlmock.NewRows(instrumentColumnNames))
+
+	// Call the Update method
+	err = repo.Update(context.Background(), newAAPL())
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInstrumentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteInstrument(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresInstrumentRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: one deleted, one missing, one still referenced by orders
+	mock.ExpectExec("DELETE FROM instruments WHERE symbol = (.+)").
+		WithArgs("AAPL").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM instruments WHERE symbol = (.+)").
+		WithArgs("APPL").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM instruments WHERE symbol = (.+)").
+		WithArgs("MSFT").
+		WillReturnError(&pq.Error{Code: foreignKeyViolation})
+
+	// Assert
+	assert.NoError(t, repo.Delete(context.Background(), "AAPL"))
+	assert.ErrorIs(t, repo.Delete(context.Background(), "APPL"), ErrInstrumentNotFound)
+	assert.ErrorIs(t, repo.Delete(context.Background(), "MSFT"), ErrInstrumentInUse)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
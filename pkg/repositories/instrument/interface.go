@@ -0,0 +1,26 @@
+package instrument
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+var (
+	// ErrInstrumentNotFound is returned when no instrument is listed under the requested symbol
+	ErrInstrumentNotFound = errors.New("instrument not found")
+	// ErrInstrumentExists is returned when creating an instrument whose symbol is already listed
+	ErrInstrumentExists = errors.New("instrument already exists")
+	// ErrInstrumentInUse is returned when deleting an instrument that orders still reference
+	ErrInstrumentInUse = errors.New("instrument has orders")
+)
+
+// InstrumentRepository interface for instrument master operations
+type InstrumentRepository interface {
+	Create(ctx context.Context, instrument *models.Instrument) error
+	GetAll(ctx context.Context) ([]models.Instrument, error)
+	GetBySymbol(ctx context.Context, symbol string) (*models.Instrument, error)
+	Update(ctx context.Context, instrument *models.Instrument) error
+	Delete(ctx context.Context, symbol string) error
+}
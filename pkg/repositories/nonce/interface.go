@@ -0,0 +1,19 @@
+package nonce
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNonceUsed is returned when a nonce is presented again before it expires
+var ErrNonceUsed = errors.New("nonce already used")
+
+// NonceRepository remembers the nonces of signed requests until their
+// signatures expire, so that a captured request cannot be replayed
+type NonceRepository interface {
+	// Use records nonce for the signing key until expiresAt, returning
+	// ErrNonceUsed if it is still recorded at now
+	Use(ctx context.Context, keyID int64, nonce string, now, expiresAt time.Time) error
+	Purge(ctx context.Context, expiredBefore time.Time) (int64, error)
+}
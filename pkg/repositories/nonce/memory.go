@@ -0,0 +1,54 @@
+package nonce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// usedNonce identifies a nonce of a signing key
+type usedNonce struct {
+	keyID int64
+	nonce string
+}
+
+// MemoryNonceRepository is an implementation of NonceRepository held in
+// process memory. It only protects a single instance of the API, and forgets
+// every nonce on restart.
+type MemoryNonceRepository struct {
+	mu   sync.Mutex
+	used map[usedNonce]time.Time
+}
+
+// NewMemoryNonceRepository creates an empty in-memory nonce repository
+func NewMemoryNonceRepository() *MemoryNonceRepository {
+	return &MemoryNonceRepository{used: make(map[usedNonce]time.Time)}
+}
+
+// Use records a nonce. Expired records of the same nonce are reused.
+func (r *MemoryNonceRepository) Use(ctx context.Context, keyID int64, nonce string, now, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := usedNonce{keyID: keyID, nonce: nonce}
+	if expiry, ok := r.used[key]; ok && expiry.After(now) {
+		return ErrNonceUsed
+	}
+	r.used[key] = expiresAt
+	return nil
+}
+
+// Purge forgets nonces that expired before expiredBefore
+func (r *MemoryNonceRepository) Purge(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for key, expiry := range r.used {
+		if expiry.Before(expiredBefore) {
+			delete(r.used, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,52 @@
+package nonce
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresNonceRepository is an implementation of NonceRepository shared by
+// every instance of the API
+type PostgresNonceRepository struct {
+	DB *sqlx.DB
+}
+
+// NewNonceRepository creates a new nonce repository
+func NewNonceRepository(db *sqlx.DB) (NonceRepository, error) {
+	return &PostgresNonceRepository{DB: db}, nil
+}
+
+// Use records a nonce. Expired records of the same nonce are reused.
+func (r *PostgresNonceRepository) Use(ctx context.Context, keyID int64, nonce string, now, expiresAt time.Time) error {
+	query := `
+		INSERT INTO used_nonces (key_id, nonce, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key_id, nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE used_nonces.expires_at <= $4
+	`
+
+	result, err := r.DB.ExecContext(ctx, query, keyID, nonce, expiresAt, now)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNonceUsed
+	}
+	return nil
+}
+
+// Purge deletes nonces that expired before expiredBefore
+func (r *PostgresNonceRepository) Purge(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	result, err := r.DB.ExecContext(ctx, `DELETE FROM used_nonces WHERE expires_at < $1`, expiredBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,101 @@
+package nonce
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseNonce(t *testing.T) {
+	testCases := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{name: "New nonce", affected: 1, wantErr: nil},
+		{name: "Live nonce", affected: 0, wantErr: ErrNonceUsed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new mock database
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Error creating mock database: %v", err)
+			}
+			defer db.Close()
+
+			// Create repository with the mock
+			repo := &PostgresNonceRepository{DB: sqlx.NewDb(db, "sqlmock")}
+			now := time.Now().UTC()
+			expiresAt := now.Add(5 * time.Minute)
+
+			// Setup expectations: an expired record of the nonce is taken over
+			mock.ExpectExec("INSERT INTO used_nonces (.+) ON CONFLICT \\(key_id, nonce\\) DO UPDATE (.+) WHERE used_nonces.expires_at <= \\$4").
+				WithArgs(int64(7), "nonce-1", expiresAt, now).
+				WillReturnResult(sqlmock.NewResult(0, tc.affected))
+
+			// Call the Use method
+			err = repo.Use(context.Background(), 7, "nonce-1", now, expiresAt)
+
+			// Assert
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPurgeNonces(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresNonceRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	expiredBefore := time.Now().UTC()
+
+	// Setup expectations
+	mock.ExpectExec("DELETE FROM used_nonces WHERE expires_at < \\$1").
+		WithArgs(expiredBefore).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	// Call the Purge method
+	purged, err := repo.Purge(context.Background(), expiredBefore)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMemoryNonceRepository(t *testing.T) {
+	repo := NewMemoryNonceRepository()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	expiresAt := now.Add(5 * time.Minute)
+
+	// A nonce is accepted once per key while it is live
+	assert.NoError(t, repo.Use(ctx, 7, "nonce-1", now, expiresAt))
+	assert.ErrorIs(t, repo.Use(ctx, 7, "nonce-1", now.Add(time.Minute), expiresAt), ErrNonceUsed)
+	assert.NoError(t, repo.Use(ctx, 8, "nonce-1", now, expiresAt))
+
+	// Once expired it may be used again
+	assert.NoError(t, repo.Use(ctx, 7, "nonce-1", expiresAt, expiresAt.Add(5*time.Minute)))
+
+	// Purging drops expired nonces only
+	assert.NoError(t, repo.Use(ctx, 9, "nonce-2", now, now.Add(time.Second)))
+	purged, err := repo.Purge(ctx, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+}
@@ -0,0 +1,68 @@
+package order
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// orderSorts whitelists the sort parameter, mapping each value to its ORDER BY
+// clause and the comparison that continues after a cursor
+var orderSorts = map[string]struct {
+	orderBy string
+	after   string
+}{
+	"created_at":  {orderBy: "created_at ASC, id ASC", after: ">"},
+	"-created_at": {orderBy: "created_at DESC, id DESC", after: "<"},
+}
+
+// defaultOrderSort lists the newest orders first
+const defaultOrderSort = "-created_at"
+
+// orderCursor is the position after which the next page starts. It carries
+// the sort it was issued for, so it cannot be replayed against another one.
+type orderCursor struct {
+	sort      string
+	createdAt time.Time
+	id        int64
+}
+
+// encode renders the cursor as an opaque URL-safe token
+func (c orderCursor) encode() string {
+	raw := fmt.Sprintf("%s|%s|%d", c.sort, c.createdAt.Format(time.RFC3339Nano), c.id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeOrderCursor parses a token produced by encode, returning ErrInvalidCursor
+// if it is malformed or was issued for a different sort
+func decodeOrderCursor(token, sort string) (orderCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return orderCursor{}, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || parts[0] != sort {
+		return orderCursor{}, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return orderCursor{}, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return orderCursor{}, ErrInvalidCursor
+	}
+
+	return orderCursor{sort: sort, createdAt: createdAt, id: id}, nil
+}
+
+// cursorAfter returns the cursor that continues a listing after order
+func cursorAfter(sort string, order models.Order) orderCursor {
+	return orderCursor{sort: sort, createdAt: order.CreatedAt, id: order.ID}
+}
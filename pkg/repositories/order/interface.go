@@ -1,10 +1,65 @@
 package order
 
-import "github.com/Javlopez/go-api/pkg/models"
+import (
+	"context"
+	"errors"
+	"time"
 
-// OrderRepository interface for order operations
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// ErrOrderNotFound is returned when no order matches the requested ID
+var ErrOrderNotFound = errors.New("order not found")
+
+// ErrVersionConflict is returned when an order was modified after the caller last read it
+var ErrVersionConflict = errors.New("order version conflict")
+
+// ErrQuantityBelowFilled is returned when an amendment would reduce an order below what has already executed
+var ErrQuantityBelowFilled = errors.New("quantity must be greater than the filled quantity")
+
+// ErrPriceNotAmendable is returned when an amendment sets a price on a stop order, which converts to a market order
+var ErrPriceNotAmendable = errors.New("stop orders carry no price")
+
+// ErrDuplicateClientOrderID is returned when an order reuses another order's client order ID
+var ErrDuplicateClientOrderID = errors.New("duplicate client order id")
+
+// ErrAccountNotFound is returned when an order names an account that is not registered
+var ErrAccountNotFound = errors.New("account not found")
+
+// ErrInvalidCursor is returned when a listing cursor is malformed or was issued for a different sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidSort is returned when a listing asks for a sort that is not supported
+var ErrInvalidSort = errors.New("invalid sort")
+
+// Fills are what matching an order changed: the fill state of every order it
+// touched, the trades that filled them and the stop orders it triggered
+type Fills struct {
+	Orders   []models.Order
+	Trades   []models.Trade
+	Triggers []models.OrderTrigger
+}
+
+// MatchFunc hands an order that has just been written to the matching engine
+// and returns its fills. They are stored in the same transaction as the
+// order, so a failure to store them leaves no trace of the write either; the
+// stored versions are written back onto the returned orders.
+type MatchFunc func(order models.Order) Fills
+
+// OrderRepository interface for order operations. Calls that take an account
+// only see that account's orders, as if others did not exist; "" lifts the
+// restriction for admin and internal use.
 type OrderRepository interface {
-	Create(order *models.Order) error
-	GetAll() ([]models.Order, error)
+	Create(ctx context.Context, order *models.Order, match MatchFunc) error
+	CreateBatch(ctx context.Context, orders []*models.Order, atomic bool, match MatchFunc) ([]int, error)
+	GetAll(ctx context.Context, filter models.OrderFilter) (*models.Page[models.Order], error)
+	GetByID(ctx context.Context, account string, id int64) (*models.Order, error)
+	GetByClientOrderID(ctx context.Context, account, clientOrderID string) (*models.Order, error)
+	GetOpen(ctx context.Context) ([]models.Order, error)
+	Cancel(ctx context.Context, account string, id int64, expectedVersion int) (*models.Order, error)
+	Amend(ctx context.Context, account string, id int64, expectedVersion int, price *models.Decimal, quantity *int, match MatchFunc) (*models.Order, error)
+	GetRevisions(ctx context.Context, account string, id int64) ([]models.OrderRevision, error)
+	GetTriggers(ctx context.Context, account string, id int64) ([]models.OrderTrigger, error)
+	Expire(ctx context.Context, now time.Time) ([]models.Order, error)
 	Close() error
 }
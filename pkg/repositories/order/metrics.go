@@ -0,0 +1,139 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Javlopez/go-api/pkg/lifecycle"
+	"github.com/Javlopez/go-api/pkg/metrics"
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// repositoryLabel identifies this repository in the repository metrics
+const repositoryLabel = "order"
+
+// instrumentedRepository records the latency and errors of every call to the repository it wraps
+type instrumentedRepository struct {
+	repo OrderRepository
+}
+
+// NewInstrumentedRepository wraps repo so that each call is observed in the repository metrics
+func NewInstrumentedRepository(repo OrderRepository) OrderRepository {
+	return &instrumentedRepository{repo: repo}
+}
+
+// clientErrors are the outcomes a caller can cause by asking for something
+// missing, stale or not allowed. They are answered with 4xx responses and are
+// not counted as repository errors.
+var clientErrors = []error{
+	ErrOrderNotFound,
+	ErrVersionConflict,
+	ErrQuantityBelowFilled,
+	ErrPriceNotAmendable,
+	ErrDuplicateClientOrderID,
+	ErrAccountNotFound,
+	ErrInvalidCursor,
+	ErrInvalidSort,
+	lifecycle.ErrInvalidTransition,
+	lifecycle.ErrOrderClosed,
+}
+
+// isClientError reports whether err is one of clientErrors
+func isClientError(err error) bool {
+	for _, target := range clientErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// observe records one call to method that started at start and returned err
+func observe(method string, start time.Time, err error) {
+	metrics.RepositoryCallDuration.WithLabelValues(repositoryLabel, method).Observe(time.Since(start).Seconds())
+	if err != nil && !isClientError(err) {
+		metrics.RepositoryErrors.WithLabelValues(repositoryLabel, method).Inc()
+	}
+}
+
+func (r *instrumentedRepository) Create(ctx context.Context, order *models.Order, match MatchFunc) error {
+	start := time.Now()
+	err := r.repo.Create(ctx, order, match)
+	observe("Create", start, err)
+	return err
+}
+
+func (r *instrumentedRepository) CreateBatch(ctx context.Context, orders []*models.Order, atomic bool, match MatchFunc) ([]int, error) {
+	start := time.Now()
+	duplicates, err := r.repo.CreateBatch(ctx, orders, atomic, match)
+	observe("CreateBatch", start, err)
+	return duplicates, err
+}
+
+func (r *instrumentedRepository) GetAll(ctx context.Context, filter models.OrderFilter) (*models.Page[models.Order], error) {
+	start := time.Now()
+	page, err := r.repo.GetAll(ctx, filter)
+	observe("GetAll", start, err)
+	return page, err
+}
+
+func (r *instrumentedRepository) GetByID(ctx context.Context, account string, id int64) (*models.Order, error) {
+	start := time.Now()
+	order, err := r.repo.GetByID(ctx, account, id)
+	observe("GetByID", start, err)
+	return order, err
+}
+
+func (r *instrumentedRepository) GetByClientOrderID(ctx context.Context, account, clientOrderID string) (*models.Order, error) {
+	start := time.Now()
+	order, err := r.repo.GetByClientOrderID(ctx, account, clientOrderID)
+	observe("GetByClientOrderID", start, err)
+	return order, err
+}
+
+func (r *instrumentedRepository) GetOpen(ctx context.Context) ([]models.Order, error) {
+	start := time.Now()
+	orders, err := r.repo.GetOpen(ctx)
+	observe("GetOpen", start, err)
+	return orders, err
+}
+
+func (r *instrumentedRepository) Cancel(ctx context.Context, account string, id int64, expectedVersion int) (*models.Order, error) {
+	start := time.Now()
+	order, err := r.repo.Cancel(ctx, account, id, expectedVersion)
+	observe("Cancel", start, err)
+	return order, err
+}
+
+func (r *instrumentedRepository) Amend(ctx context.Context, account string, id int64, expectedVersion int, price *models.Decimal, quantity *int, match MatchFunc) (*models.Order, error) {
+	start := time.Now()
+	order, err := r.repo.Amend(ctx, account, id, expectedVersion, price, quantity, match)
+	observe("Amend", start, err)
+	return order, err
+}
+
+func (r *instrumentedRepository) GetRevisions(ctx context.Context, account string, id int64) ([]models.OrderRevision, error) {
+	start := time.Now()
+	revisions, err := r.repo.GetRevisions(ctx, account, id)
+	observe("GetRevisions", start, err)
+	return revisions, err
+}
+
+func (r *instrumentedRepository) GetTriggers(ctx context.Context, account string, id int64) ([]models.OrderTrigger, error) {
+	start := time.Now()
+	triggers, err := r.repo.GetTriggers(ctx, account, id)
+	observe("GetTriggers", start, err)
+	return triggers, err
+}
+
+func (r *instrumentedRepository) Expire(ctx context.Context, now time.Time) ([]models.Order, error) {
+	start := time.Now()
+	orders, err := r.repo.Expire(ctx, now)
+	observe("Expire", start, err)
+	return orders, err
+}
+
+func (r *instrumentedRepository) Close() error {
+	return r.repo.Close()
+}
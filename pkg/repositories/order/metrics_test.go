@@ -0,0 +1,64 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Javlopez/go-api/pkg/lifecycle"
+	"github.com/Javlopez/go-api/pkg/metrics"
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+func TestInstrumentedRepositoryCountsErrors(t *testing.T) {
+	now := time.Now()
+
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create instrumented repository with the mock
+	repo := NewInstrumentedRepository(&PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")})
+	errors := metrics.RepositoryErrors.WithLabelValues(repositoryLabel, "GetByID")
+	errorsBefore := testutil.ToFloat64(errors)
+
+	// Setup expectations: the first lookup finds the order, the second does
+	// not, and the third fails in the database
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id").
+		WithArgs(int64(42), "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).AddRow(42, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusNew, 0, 1, now, now))
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id").
+		WithArgs(int64(43), "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames))
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id").
+		WithArgs(int64(44), "acme").
+		WillReturnError(assert.AnError)
+
+	// Call the GetByID method
+	_, err = repo.GetByID(context.Background(), "acme", 42)
+	assert.NoError(t, err)
+	_, err = repo.GetByID(context.Background(), "acme", 43)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	assert.Equal(t, errorsBefore, testutil.ToFloat64(errors), "not found is not a repository error")
+	_, err = repo.GetByID(context.Background(), "acme", 44)
+	assert.ErrorIs(t, err, assert.AnError)
+
+	// Assert: only the failed call is counted as an error
+	assert.Equal(t, errorsBefore+1, testutil.ToFloat64(errors))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsClientError(t *testing.T) {
+	assert.True(t, isClientError(ErrVersionConflict))
+	assert.True(t, isClientError(fmt.Errorf("cancel order 42: %w", lifecycle.ErrOrderClosed)))
+	assert.False(t, isClientError(assert.AnError))
+}
@@ -1,60 +1,710 @@
 package order
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/Javlopez/go-api/pkg/lifecycle"
 	"github.com/Javlopez/go-api/pkg/models"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"sort"
+	"strings"
 	"time"
 )
 
+// orderColumns lists the columns selected for every models.Order
+const orderColumns = "id, account_id, client_order_id, symbol, price, quantity, order_type, kind, time_in_force, expires_at, trigger_price, triggered_at, status, filled_quantity, version, created_at, updated_at"
+
+// Postgres error codes for constraint violations
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// clientOrderIDIndex is the unique index that keeps an account's client order IDs distinct
+const clientOrderIDIndex = "uq_orders_client_order_id"
+
+// accountForeignKey ties every order to a registered account
+const accountForeignKey = "fk_orders_account_id"
+
+// inAccount is appended to the WHERE clause of single-order queries with the
+// account as its parameter, matching any order when the account is ""
+const inAccount = " AND ($2 = '' OR account_id = $2)"
+
 // PostgresOrderRepository is an implementation of OrderRepository
 type PostgresOrderRepository struct {
 	DB *sqlx.DB
+	// QueryTimeout bounds each call, including every statement of its
+	// transaction. Zero leaves calls bounded only by the caller's context.
+	QueryTimeout time.Duration
 }
 
 // NewOrderRepository creates a new order repository
-func NewOrderRepository(db *sqlx.DB) (OrderRepository, error) {
-	return &PostgresOrderRepository{DB: db}, nil
+func NewOrderRepository(db *sqlx.DB, queryTimeout time.Duration) (OrderRepository, error) {
+	return &PostgresOrderRepository{DB: db, QueryTimeout: queryTimeout}, nil
 }
 
-// Create inserts a new order
-func (r *PostgresOrderRepository) Create(order *models.Order) error {
+// withTimeout derives the context a call runs its queries under
+func (r *PostgresOrderRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.QueryTimeout)
+}
+
+// Create inserts a new order and, in the same transaction, the fills match
+// returns for it. It returns ErrAccountNotFound if the order names an account
+// that is not registered.
+func (r *PostgresOrderRepository) Create(ctx context.Context, order *models.Order, match MatchFunc) error {
 	if order == nil {
 		return errors.New("order cannot be nil")
 	}
 
-	// Set created_at to current time
-	order.CreatedAt = time.Now()
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	prepareInsert(order, time.Now())
 
 	query := `
-		INSERT INTO orders (symbol, price, quantity, order_type, created_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
+		INSERT INTO orders (` + insertColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, version
+	`
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, insertArgs(order)...).Scan(&order.ID, &order.Version)
+	if isViolation(err, uniqueViolation, clientOrderIDIndex) {
+		return ErrDuplicateClientOrderID
+	}
+	if isViolation(err, foreignKeyViolation, accountForeignKey) {
+		return ErrAccountNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := storeMatch(ctx, tx, match, *order); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// isViolation reports whether err is a Postgres violation of constraint
+func isViolation(err error, code pq.ErrorCode, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code && pqErr.Constraint == constraint
+}
+
+// CreateBatch inserts orders with a single multi-row INSERT in one transaction,
+// assigning their IDs in the order given. The orders must belong to one
+// account. Orders whose client order ID is already in use, by a stored order
+// or an earlier order in the batch, are skipped and their indexes returned.
+// When atomic is set any such order rolls the whole batch back and
+// ErrDuplicateClientOrderID is returned with them. The created orders are
+// then matched in the order given and their fills stored in the same
+// transaction; a failure to store any of them rolls back the whole batch, so
+// no order is left stored without having been matched.
+func (r *PostgresOrderRepository) CreateBatch(ctx context.Context, orders []*models.Order, atomic bool, match MatchFunc) ([]int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	values := make([]string, 0, len(orders))
+	args := make([]interface{}, 0, len(orders)*insertColumnCount)
+	for _, order := range orders {
+		prepareInsert(order, now)
+
+		placeholders := make([]string, insertColumnCount)
+		for i := range placeholders {
+			placeholders[i] = fmt.Sprintf("$%d", len(args)+i+1)
+		}
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args, insertArgs(order)...)
+	}
+
+	query := `
+		INSERT INTO orders (` + insertColumns + `)
+		VALUES ` + strings.Join(values, ", ") + `
+		ON CONFLICT (account_id, client_order_id) WHERE client_order_id IS NOT NULL DO NOTHING
+		RETURNING id, version, client_order_id
 	`
 
-	return r.DB.QueryRow(
-		query,
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var rows []struct {
+		ID            int64   `db:"id"`
+		Version       int     `db:"version"`
+		ClientOrderID *string `db:"client_order_id"`
+	}
+	err = tx.SelectContext(ctx, &rows, query, args...)
+	if isViolation(err, foreignKeyViolation, accountForeignKey) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// IDs come from a sequence in VALUES order, so the inserted rows sorted by
+	// ID line up with the orders that were not skipped
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+
+	inserted := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if row.ClientOrderID != nil {
+			inserted[*row.ClientOrderID] = true
+		}
+	}
+
+	var duplicates []int
+	created := make([]*models.Order, 0, len(rows))
+	for i, order := range orders {
+		if order.ClientOrderID != nil {
+			if !inserted[*order.ClientOrderID] {
+				duplicates = append(duplicates, i)
+				continue
+			}
+			// Only the first order with a client order ID was inserted
+			delete(inserted, *order.ClientOrderID)
+		}
+		created = append(created, order)
+	}
+	if atomic && len(duplicates) > 0 {
+		return duplicates, ErrDuplicateClientOrderID
+	}
+
+	for i, order := range created {
+		order.ID = rows[i].ID
+		order.Version = rows[i].Version
+	}
+	for _, order := range created {
+		if err := storeMatch(ctx, tx, match, *order); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return duplicates, nil
+}
+
+// insertColumns lists the columns written for every new order, matching insertArgs
+const insertColumns = "account_id, client_order_id, symbol, price, quantity, order_type, kind, time_in_force, expires_at, trigger_price, status, created_at, updated_at"
+
+// insertColumnCount is the number of columns in insertColumns
+const insertColumnCount = 13
+
+// prepareInsert stamps a new order's creation time and fills in its defaults
+func prepareInsert(order *models.Order, now time.Time) {
+	order.CreatedAt = now
+	order.UpdatedAt = now
+
+	// Every order starts its lifecycle as NEW
+	if order.Status == "" {
+		order.Status = models.StatusNew
+	}
+	if order.Kind == "" {
+		order.Kind = models.Limit
+	}
+	if order.TimeInForce == "" {
+		order.TimeInForce = models.GoodTillCancel
+	}
+
+	// expires_at has no time zone; keep it in UTC so Expire compares like with like
+	if order.ExpiresAt != nil {
+		expiresAt := order.ExpiresAt.UTC()
+		order.ExpiresAt = &expiresAt
+	}
+}
+
+// insertArgs returns the values of insertColumns for order
+func insertArgs(order *models.Order) []interface{} {
+	return []interface{}{
+		order.AccountID,
+		order.ClientOrderID,
 		order.Symbol,
 		order.Price,
 		order.Quantity,
 		order.OrderType,
+		order.Kind,
+		order.TimeInForce,
+		order.ExpiresAt,
+		order.TriggerPrice,
+		order.Status,
 		order.CreatedAt,
-	).Scan(&order.ID)
+		order.UpdatedAt,
+	}
 }
 
-// GetAll retrieves all orders
-func (r *PostgresOrderRepository) GetAll() ([]models.Order, error) {
+// GetAll retrieves one page of the orders matching the filter. Pages are
+// keyed on (created_at, id), so they stay stable while new orders arrive and
+// cost the same however deep the listing goes.
+func (r *PostgresOrderRepository) GetAll(ctx context.Context, filter models.OrderFilter) (*models.Page[models.Order], error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	sort := filter.Sort
+	if sort == "" {
+		sort = defaultOrderSort
+	}
+	sortOrder, ok := orderSorts[sort]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = models.DefaultOrderPageSize
+	}
+	limit = min(limit, models.MaxOrderPageSize)
+
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.AccountID != "" {
+		addCondition("account_id = $%d", filter.AccountID)
+	}
+	if filter.Symbol != "" {
+		addCondition("symbol = $%d", filter.Symbol)
+	}
+	if filter.OrderType != "" {
+		addCondition("order_type = $%d", filter.OrderType)
+	}
+	if len(filter.Status) > 0 {
+		addCondition("status = ANY($%d)", statusArray(filter.Status))
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeOrderCursor(filter.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, cursor.createdAt, cursor.id)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) %s ($%d, $%d)", sortOrder.after, len(args)-1, len(args)))
+	}
+
+	query := `SELECT ` + orderColumns + ` FROM orders`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, limit+1)
+	query += fmt.Sprintf(` ORDER BY %s LIMIT $%d`, sortOrder.orderBy, len(args))
+
 	orders := []models.Order{}
+	if err := r.DB.SelectContext(ctx, &orders, query, args...); err != nil {
+		return nil, err
+	}
+
+	page := &models.Page[models.Order]{Data: orders}
+	if len(orders) > limit {
+		page.Data = orders[:limit]
+		page.NextCursor = cursorAfter(sort, page.Data[limit-1]).encode()
+	}
+	return page, nil
+}
+
+// GetByID retrieves a single order of account, returning ErrOrderNotFound if
+// it does not exist or belongs to another account
+func (r *PostgresOrderRepository) GetByID(ctx context.Context, account string, id int64) (*models.Order, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var order models.Order
 	query := `
-		SELECT id, symbol, price, quantity, order_type, created_at
+		SELECT ` + orderColumns + `
 		FROM orders
-		ORDER BY created_at DESC
+		WHERE id = $1` + inAccount
+
+	err := r.DB.GetContext(ctx, &order, query, id, account)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetByClientOrderID retrieves the order account submitted under clientOrderID,
+// returning ErrOrderNotFound if there is none
+func (r *PostgresOrderRepository) GetByClientOrderID(ctx context.Context, account, clientOrderID string) (*models.Order, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var order models.Order
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE client_order_id = $1` + inAccount
+
+	err := r.DB.GetContext(ctx, &order, query, clientOrderID, account)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOpen retrieves every live order in arrival order, for rebuilding the order book
+func (r *PostgresOrderRepository) GetOpen(ctx context.Context) ([]models.Order, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	orders := []models.Order{}
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE status IN ($1, $2)
+		ORDER BY created_at, id
 	`
 
-	err := r.DB.Select(&orders, query)
+	err := r.DB.SelectContext(ctx, &orders, query, models.StatusNew, models.StatusPartiallyFilled)
+	return orders, err
+}
+
+// Cancel marks an order of account as cancelled, provided it is still at
+// expectedVersion. A stale version yields ErrVersionConflict so two concurrent
+// writers cannot both win.
+func (r *PostgresOrderRepository) Cancel(ctx context.Context, account string, id int64, expectedVersion int) (*models.Order, error) {
+	return r.transition(ctx, account, id, expectedVersion, models.StatusCancelled)
+}
+
+// transition applies a validated status change to an order of account at
+// expectedVersion and bumps its version. The current row is locked while the
+// transition is validated, so concurrent updates cannot race an order into a
+// state the lifecycle does not allow.
+func (r *PostgresOrderRepository) transition(ctx context.Context, account string, id int64, expectedVersion int, status models.OrderStatus) (*models.Order, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current struct {
+		Status  models.OrderStatus `db:"status"`
+		Version int                `db:"version"`
+	}
+	err = tx.GetContext(ctx, &current, `SELECT status, version FROM orders WHERE id = $1`+inAccount+` FOR UPDATE`, id, account)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedVersion != current.Version {
+		return nil, ErrVersionConflict
+	}
+
+	if err := lifecycle.Transition(current.Status, status); err != nil {
+		return nil, err
+	}
+
+	var updated models.Order
+	query := `
+		UPDATE orders SET status = $1, version = version + 1, updated_at = $2
+		WHERE id = $3
+		RETURNING ` + orderColumns
+
+	if err := tx.GetContext(ctx, &updated, query, status, time.Now(), id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &updated, nil
+}
+
+// Expire marks every live order whose expires_at is at or before now as
+// EXPIRED, bumping their versions, and returns the expired orders. Only orders
+// the lifecycle lets expire are touched. The update is a single statement, so
+// an order is either filled or expired, never both.
+func (r *PostgresOrderRepository) Expire(ctx context.Context, now time.Time) ([]models.Order, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	orders := []models.Order{}
+	query := `
+		UPDATE orders SET status = $1, version = version + 1, updated_at = $2
+		WHERE status = ANY($3) AND expires_at <= $2
+		RETURNING ` + orderColumns
+
+	err := r.DB.SelectContext(ctx, &orders, query,
+		models.StatusExpired,
+		now.UTC(),
+		statusArray(lifecycle.Sources(models.StatusExpired)),
+	)
 	return orders, err
 }
 
+// Amend replaces the price and/or quantity of a live order of account at
+// expectedVersion and stores the fills match returns for the amended order in
+// the same transaction. The order keeps its ID and created_at; the replaced
+// terms are recorded as a revision so the full cancel/replace chain can be
+// audited.
+func (r *PostgresOrderRepository) Amend(ctx context.Context, account string, id int64, expectedVersion int, price *models.Decimal, quantity *int, match MatchFunc) (*models.Order, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current models.Order
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1` + inAccount + ` FOR UPDATE`
+	err = tx.GetContext(ctx, &current, query, id, account)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Version != expectedVersion {
+		return nil, ErrVersionConflict
+	}
+
+	if err := lifecycle.RequireLive(current.Status); err != nil {
+		return nil, err
+	}
+
+	if quantity != nil && *quantity <= current.FilledQuantity {
+		return nil, ErrQuantityBelowFilled
+	}
+
+	if price != nil && current.Kind == models.Stop {
+		return nil, ErrPriceNotAmendable
+	}
+
+	revision := models.OrderRevision{
+		OrderID:          id,
+		Version:          current.Version + 1,
+		PreviousPrice:    current.Price,
+		PreviousQuantity: current.Quantity,
+		Price:            current.Price,
+		Quantity:         current.Quantity,
+		CreatedAt:        time.Now(),
+	}
+	if price != nil {
+		revision.Price = *price
+	}
+	if quantity != nil {
+		revision.Quantity = *quantity
+	}
+
+	var updated models.Order
+	query = `
+		UPDATE orders SET price = $1, quantity = $2, version = $3, updated_at = $4
+		WHERE id = $5
+		RETURNING ` + orderColumns
+
+	err = tx.GetContext(ctx, &updated, query, revision.Price, revision.Quantity, revision.Version, revision.CreatedAt, id)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO order_revisions (order_id, version, previous_price, previous_quantity, price, quantity, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		revision.OrderID,
+		revision.Version,
+		revision.PreviousPrice,
+		revision.PreviousQuantity,
+		revision.Price,
+		revision.Quantity,
+		revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := storeMatch(ctx, tx, match, updated); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &updated, nil
+}
+
+// GetRevisions retrieves the amendment history of an order of account, oldest first
+func (r *PostgresOrderRepository) GetRevisions(ctx context.Context, account string, id int64) ([]models.OrderRevision, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.GetByID(ctx, account, id); err != nil {
+		return nil, err
+	}
+
+	revisions := []models.OrderRevision{}
+	query := `
+		SELECT id, order_id, version, previous_price, previous_quantity, price, quantity, created_at
+		FROM order_revisions
+		WHERE order_id = $1
+		ORDER BY version
+	`
+
+	err := r.DB.SelectContext(ctx, &revisions, query, id)
+	return revisions, err
+}
+
+// GetTriggers retrieves the trigger audit of a stop order of account
+func (r *PostgresOrderRepository) GetTriggers(ctx context.Context, account string, id int64) ([]models.OrderTrigger, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.GetByID(ctx, account, id); err != nil {
+		return nil, err
+	}
+
+	triggers := []models.OrderTrigger{}
+	query := `
+		SELECT id, order_id, kind, activated_kind, trigger_price, last_price, reason, triggered_at
+		FROM order_triggers
+		WHERE order_id = $1
+		ORDER BY triggered_at, id
+	`
+
+	err := r.DB.SelectContext(ctx, &triggers, query, id)
+	return triggers, err
+}
+
+// storeMatch hands an order written in tx to match, if there is one, and
+// stores the fills it returns in tx
+func storeMatch(ctx context.Context, tx *sqlx.Tx, match MatchFunc, order models.Order) error {
+	if match == nil {
+		return nil
+	}
+	return storeFills(ctx, tx, match(order))
+}
+
+// storeFills persists the filled quantity and status the matching engine
+// computed for each order, bumping their versions, and records the trades
+// that produced those fills. Stop orders the engine triggered are converted to
+// their activated kind and their triggers audited. Fills are written in the
+// caller's transaction so they always reconcile against trades; an order whose
+// current status the lifecycle does not let move to its new one fails it.
+func storeFills(ctx context.Context, tx *sqlx.Tx, fills Fills) error {
+	orders, trades, triggers := fills.Orders, fills.Trades, fills.Triggers
+
+	query := `
+		UPDATE orders SET filled_quantity = $1, status = $2, kind = $3, triggered_at = $4, version = version + 1, updated_at = $5
+		WHERE id = $6 AND status = ANY($7)
+		RETURNING version, updated_at
+	`
+
+	now := time.Now()
+	for i := range orders {
+		err := tx.QueryRowContext(ctx, query,
+			orders[i].FilledQuantity,
+			orders[i].Status,
+			orders[i].Kind,
+			orders[i].TriggeredAt,
+			now,
+			orders[i].ID,
+			statusArray(lifecycle.Sources(orders[i].Status)),
+		).Scan(&orders[i].Version, &orders[i].UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: order %d", lifecycle.ErrOrderClosed, orders[i].ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `
+		INSERT INTO trades (buy_order_id, sell_order_id, symbol, price, quantity, executed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	for i := range trades {
+		err := tx.QueryRowContext(ctx, query,
+			trades[i].BuyOrderID,
+			trades[i].SellOrderID,
+			trades[i].Symbol,
+			trades[i].Price,
+			trades[i].Quantity,
+			trades[i].ExecutedAt,
+		).Scan(&trades[i].ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	query = `
+		INSERT INTO order_triggers (order_id, kind, activated_kind, trigger_price, last_price, reason, triggered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	for i := range triggers {
+		err := tx.QueryRowContext(ctx, query,
+			triggers[i].OrderID,
+			triggers[i].Kind,
+			triggers[i].ActivatedKind,
+			triggers[i].TriggerPrice,
+			triggers[i].LastPrice,
+			triggers[i].Reason,
+			triggers[i].TriggeredAt,
+		).Scan(&triggers[i].ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statusArray binds statuses as a Postgres text array, for status = ANY(...)
+func statusArray(statuses []models.OrderStatus) interface{} {
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+	return pq.Array(values)
+}
+
 // Close closes the database connection
 func (r *PostgresOrderRepository) Close() error {
 	return r.DB.Close()
@@ -1,17 +1,21 @@
 package order
 
 import (
+	"context"
+	"github.com/Javlopez/go-api/pkg/lifecycle"
 	"github.com/Javlopez/go-api/pkg/models"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
-
-	_ "github.com/lib/pq"
 )
 
+// orderColumnNames mirrors orderColumns for building mocked result rows
+var orderColumnNames = []string{"id", "account_id", "client_order_id", "symbol", "price", "quantity", "order_type", "kind", "time_in_force", "expires_at", "trigger_price", "triggered_at", "status", "filled_quantity", "version", "created_at", "updated_at"}
+
 func TestCreateOrder(t *testing.T) {
 	// Create a new mock database
 	db, mock, err := sqlmock.New()
@@ -28,25 +32,237 @@ func TestCreateOrder(t *testing.T) {
 
 	// Create test order
 	now := time.Now()
+	account := "acme"
 	order := &models.Order{
+		AccountID: &account,
 		Symbol:    "AAPL",
-		Price:     150.5,
+		Price:     models.MustParseDecimal("150.5"),
 		Quantity:  10,
 		OrderType: models.Buy,
 		CreatedAt: now,
 	}
 
 	// Setup expectations
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO orders").
+		WithArgs(&account, nil, order.Symbol, order.Price, order.Quantity, order.OrderType, models.Limit, models.GoodTillCancel, nil, nil, models.StatusNew, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+	mock.ExpectCommit()
+
+	// Call the Create method
+	err = repo.Create(context.Background(), order, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), order.ID)
+	assert.Equal(t, models.StatusNew, order.Status)
+	assert.Equal(t, 1, order.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateOrderDuplicateClientOrderID(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	clientOrderID := "oms-1"
+	order := &models.Order{
+		ClientOrderID: &clientOrderID,
+		Symbol:        "AAPL",
+		Price:         models.MustParseDecimal("150.5"),
+		Quantity:      10,
+		OrderType:     models.Buy,
+	}
+
+	// Setup expectations: the unique index rejects the insert
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO orders").
+		WithArgs(nil, &clientOrderID, order.Symbol, order.Price, order.Quantity, order.OrderType, models.Limit, models.GoodTillCancel, nil, nil, models.StatusNew, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnError(&pq.Error{Code: uniqueViolation, Constraint: clientOrderIDIndex})
+	mock.ExpectRollback()
+
+	// Call the Create method
+	err = repo.Create(context.Background(), order, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDuplicateClientOrderID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateOrderUnknownAccount(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	account := "unregistered"
+	order := newBatchOrder("")
+	order.AccountID = &account
+
+	// Setup expectations: the account foreign key rejects the insert
+	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO orders").
-		WithArgs(order.Symbol, order.Price, order.Quantity, order.OrderType, sqlmock.AnyArg()).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		WillReturnError(&pq.Error{Code: foreignKeyViolation, Constraint: accountForeignKey})
+	mock.ExpectRollback()
 
 	// Call the Create method
-	err = repo.Create(order)
+	err = repo.Create(context.Background(), order, nil)
 
 	// Assert
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrderByClientOrderID(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE client_order_id = (.+)").
+		WithArgs("oms-1", "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", "oms-1", "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusNew, 0, 1, now, now))
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE client_order_id = (.+)").
+		WithArgs("oms-2", "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames))
+
+	// Call the GetByClientOrderID method
+	order, err := repo.GetByClientOrderID(context.Background(), "acme", "oms-1")
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), order.ID)
+	if assert.NotNil(t, order.ClientOrderID) {
+		assert.Equal(t, "oms-1", *order.ClientOrderID)
+	}
+
+	_, err = repo.GetByClientOrderID(context.Background(), "acme", "oms-2")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// newBatchOrder returns a limit order for a batch, with an optional client order ID
+func newBatchOrder(clientOrderID string) *models.Order {
+	order := &models.Order{
+		Symbol:    "AAPL",
+		Price:     models.MustParseDecimal("150.5"),
+		Quantity:  10,
+		OrderType: models.Buy,
+	}
+	if clientOrderID != "" {
+		order.ClientOrderID = &clientOrderID
+	}
+	return order
+}
+
+func TestCreateOrderBatch(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	orders := []*models.Order{newBatchOrder("oms-1"), newBatchOrder(""), newBatchOrder("oms-2")}
+
+	// Setup expectations: one statement for the whole batch, rows returned out of order
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO orders (.+) VALUES \\(\\$1, (.+)\\), \\(\\$14, (.+)\\), \\(\\$27, (.+)\\) ON CONFLICT (.+) DO NOTHING RETURNING id, version, client_order_id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "client_order_id"}).
+			AddRow(12, 1, "oms-2").
+			AddRow(10, 1, "oms-1").
+			AddRow(11, 1, nil))
+	mock.ExpectCommit()
+
+	// Call the CreateBatch method
+	duplicates, err := repo.CreateBatch(context.Background(), orders, true, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, duplicates)
+	assert.Equal(t, int64(10), orders[0].ID)
+	assert.Equal(t, int64(11), orders[1].ID)
+	assert.Equal(t, int64(12), orders[2].ID)
+	assert.Equal(t, models.StatusNew, orders[1].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateOrderBatchSkipsDuplicates(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	orders := []*models.Order{newBatchOrder("oms-1"), newBatchOrder("oms-taken"), newBatchOrder(""), newBatchOrder("oms-1")}
+
+	// Setup expectations: oms-taken is already stored and oms-1 repeats within the batch
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO orders").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "client_order_id"}).
+			AddRow(10, 1, "oms-1").
+			AddRow(12, 1, nil))
+	mock.ExpectCommit()
+
+	// Call the CreateBatch method
+	duplicates, err := repo.CreateBatch(context.Background(), orders, false, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 3}, duplicates)
+	assert.Equal(t, int64(10), orders[0].ID)
+	assert.Equal(t, int64(0), orders[1].ID)
+	assert.Equal(t, int64(12), orders[2].ID)
+	assert.Equal(t, int64(0), orders[3].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateOrderBatchAtomicRollsBackDuplicates(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	orders := []*models.Order{newBatchOrder("oms-1"), newBatchOrder("oms-taken")}
+
+	// Setup expectations: the duplicate undoes the insert of the other order
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO orders").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "client_order_id"}).
+			AddRow(10, 1, "oms-1"))
+	mock.ExpectRollback()
+
+	// Call the CreateBatch method
+	duplicates, err := repo.CreateBatch(context.Background(), orders, true, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDuplicateClientOrderID)
+	assert.Equal(t, []int{1}, duplicates)
+	assert.Equal(t, int64(0), orders[0].ID)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -68,21 +284,130 @@ func TestGetAllOrders(t *testing.T) {
 	now := time.Now()
 
 	// Setup expected rows
-	rows := sqlmock.NewRows([]string{"id", "symbol", "price", "quantity", "order_type", "created_at"}).
-		AddRow(1, "AAPL", 150.5, 10, models.Buy, now).
-		AddRow(2, "MSFT", 250.75, 5, models.Sell, now)
+	rows := sqlmock.NewRows(orderColumnNames).
+		AddRow(1, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusNew, 0, 1, now, now).
+		AddRow(2, "acme", nil, "MSFT", 250.75, 5, models.Sell, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusFilled, 5, 3, now, now)
 
-	// Setup expectations
-	mock.ExpectQuery("SELECT (.+) FROM orders").WillReturnRows(rows)
+	// Setup expectations: newest first, one row beyond the default page size
+	mock.ExpectQuery("SELECT (.+) FROM orders ORDER BY created_at DESC, id DESC LIMIT").
+		WithArgs(models.DefaultOrderPageSize + 1).
+		WillReturnRows(rows)
 
 	// Call the GetAll method
-	orders, err := repo.GetAll()
+	page, err := repo.GetAll(context.Background(), models.OrderFilter{})
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Len(t, orders, 2)
-	assert.Equal(t, "AAPL", orders[0].Symbol)
-	assert.Equal(t, "MSFT", orders[1].Symbol)
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, "AAPL", page.Data[0].Symbol)
+	assert.Equal(t, "MSFT", page.Data[1].Symbol)
+	assert.Equal(t, models.StatusFilled, page.Data[1].Status)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllOrdersInAccount(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations: the account narrows the listing before any other filter
+	mock.ExpectQuery(`SELECT (.+) FROM orders WHERE account_id = \$1 AND symbol = \$2 ORDER BY created_at DESC, id DESC LIMIT \$3`).
+		WithArgs("acme", "AAPL", models.DefaultOrderPageSize+1).
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusNew, 0, 1, now, now))
+
+	// Call the GetAll method
+	page, err := repo.GetAll(context.Background(), models.OrderFilter{AccountID: "acme", Symbol: "AAPL"})
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, page.Data, 1) && assert.NotNil(t, page.Data[0].AccountID) {
+		assert.Equal(t, "acme", *page.Data[0].AccountID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllOrdersPaginates(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	first := time.Date(2025, 1, 2, 15, 4, 5, 123456000, time.UTC)
+	second := first.Add(time.Second)
+	from := first.Add(-time.Hour)
+
+	// Setup expectations: the first page returns an extra row, so a cursor follows
+	mock.ExpectQuery(`SELECT (.+) FROM orders WHERE symbol = \$1 AND order_type = \$2 AND status = ANY\(\$3\) AND created_at >= \$4 ORDER BY created_at ASC, id ASC LIMIT \$5`).
+		WithArgs("AAPL", models.Buy, pq.Array([]string{"NEW", "PARTIALLY_FILLED"}), from, 2).
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusNew, 0, 1, first, first).
+			AddRow(2, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusNew, 0, 1, second, second))
+
+	// The second page continues after the last row of the first
+	mock.ExpectQuery(`SELECT (.+) FROM orders WHERE symbol = \$1 AND \(created_at, id\) > \(\$2, \$3\) ORDER BY created_at ASC, id ASC LIMIT \$4`).
+		WithArgs("AAPL", first, int64(1), 2).
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(2, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusNew, 0, 1, second, second))
+
+	// Call the GetAll method
+	page, err := repo.GetAll(context.Background(), models.OrderFilter{
+		Symbol:    "AAPL",
+		OrderType: models.Buy,
+		Status:    []models.OrderStatus{models.StatusNew, models.StatusPartiallyFilled},
+		From:      &from,
+		Sort:      "created_at",
+		Limit:     1,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, int64(1), page.Data[0].ID)
+	assert.NotEmpty(t, page.NextCursor)
+
+	next, err := repo.GetAll(context.Background(), models.OrderFilter{Symbol: "AAPL", Sort: "created_at", Limit: 1, Cursor: page.NextCursor})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, next.Data, 1)
+	assert.Equal(t, int64(2), next.Data[0].ID)
+	assert.Empty(t, next.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllOrdersInvalidCursor(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	issued := orderCursor{sort: "-created_at", createdAt: time.Now(), id: 1}.encode()
+
+	// A malformed cursor, or one issued for another sort, never reaches the database
+	_, err = repo.GetAll(context.Background(), models.OrderFilter{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = repo.GetAll(context.Background(), models.OrderFilter{Sort: "created_at", Cursor: issued})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = repo.GetAll(context.Background(), models.OrderFilter{Sort: "price"})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -101,9 +426,585 @@ func TestCreateOrderNilOrder(t *testing.T) {
 	repo := &PostgresOrderRepository{DB: sqlxDB}
 
 	// Call the Create method with nil
-	err = repo.Create(nil)
+	err = repo.Create(context.Background(), nil, nil)
 
 	// Assert
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetAllOrdersQueryTimeout(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository whose queries time out well before the database answers
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock"), QueryTimeout: 10 * time.Millisecond}
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT (.+) FROM orders").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows(orderColumnNames))
+
+	// Call the GetAll method
+	start := time.Now()
+	_, err = repo.GetAll(context.Background(), models.OrderFilter{})
+
+	// Assert: the query is abandoned at the timeout, not when the database answers
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestCreateOrderCanceledContext(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock"), QueryTimeout: time.Second}
+
+	// Setup expectations: the caller goes away while the insert is running
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO orders").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	// Call the Create method
+	start := time.Now()
+	err = repo.Create(ctx, &models.Order{Symbol: "AAPL", Price: models.MustParseDecimal("150.5"), Quantity: 10, OrderType: models.Buy}, nil)
+
+	// Assert: the insert is abandoned as soon as the caller cancels
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestCancelOrderFilled(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: the order is already filled, so no update is issued
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status, version FROM orders WHERE id = (.+) FOR UPDATE").
+		WithArgs(int64(1), "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "version"}).AddRow(models.StatusFilled, 2))
+	mock.ExpectRollback()
+
+	// Call the Cancel method
+	order, err := repo.Cancel(context.Background(), "acme", 1, 2)
+
+	// Assert
+	assert.ErrorIs(t, err, lifecycle.ErrInvalidTransition)
+	assert.Nil(t, order)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelOrderNotFound(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status, version FROM orders WHERE id = (.+) FOR UPDATE").
+		WithArgs(int64(42), "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "version"}))
+	mock.ExpectRollback()
+
+	// Call the Cancel method
+	order, err := repo.Cancel(context.Background(), "acme", 42, 1)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	assert.Nil(t, order)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrderByID(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = (.+)").
+		WithArgs(int64(1), "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusNew, 0, 1, now, now))
+
+	// Call the GetByID method
+	order, err := repo.GetByID(context.Background(), "acme", 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), order.ID)
+	assert.Equal(t, "AAPL", order.Symbol)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrderByIDNotFound(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = (.+)").
+		WithArgs(int64(42), "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames))
+
+	// Call the GetByID method
+	order, err := repo.GetByID(context.Background(), "acme", 42)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	assert.Nil(t, order)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelOrder(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status, version FROM orders WHERE id = (.+) FOR UPDATE").
+		WithArgs(int64(1), "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "version"}).AddRow(models.StatusPartiallyFilled, 3))
+	mock.ExpectQuery("UPDATE orders SET status = (.+), version = version \\+ 1").
+		WithArgs(models.StatusCancelled, sqlmock.AnyArg(), int64(1)).
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusCancelled, 0, 4, now, now))
+	mock.ExpectCommit()
+
+	// Call the Cancel method
+	order, err := repo.Cancel(context.Background(), "acme", 1, 3)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusCancelled, order.Status)
+	assert.Equal(t, 4, order.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelOrderStaleVersion(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: another writer already bumped the version
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status, version FROM orders WHERE id = (.+) FOR UPDATE").
+		WithArgs(int64(1), "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"status", "version"}).AddRow(models.StatusNew, 2))
+	mock.ExpectRollback()
+
+	// Call the Cancel method with the version the caller last saw
+	order, err := repo.Cancel(context.Background(), "acme", 1, 1)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.Nil(t, order)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAmendOrder(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	created := time.Now().Add(-time.Hour)
+	now := time.Now()
+	price := models.MustParseDecimal("151.25")
+
+	// Setup expectations: price is replaced, quantity is carried over
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = (.+) FOR UPDATE").
+		WithArgs(int64(1), "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusNew, 0, 1, created, created))
+	mock.ExpectQuery("UPDATE orders SET price").
+		WithArgs(price, 10, 2, sqlmock.AnyArg(), int64(1)).
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", "151.2500", 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusNew, 0, 2, created, now))
+	mock.ExpectExec("INSERT INTO order_revisions").
+		WithArgs(int64(1), 2, models.MustParseDecimal("150.5"), 10, price, 10, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Call the Amend method
+	order, err := repo.Amend(context.Background(), "acme", 1, 1, &price, nil, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, price, order.Price)
+	assert.Equal(t, 2, order.Version)
+	assert.Equal(t, created, order.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAmendOrderClosed(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+	quantity := 20
+
+	// Setup expectations: filled orders cannot be amended
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = (.+) FOR UPDATE").
+		WithArgs(int64(1), "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusFilled, 10, 2, now, now))
+	mock.ExpectRollback()
+
+	// Call the Amend method
+	order, err := repo.Amend(context.Background(), "acme", 1, 2, nil, &quantity, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, lifecycle.ErrOrderClosed)
+	assert.Nil(t, order)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAmendOrderQuantityBelowFilled(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+	quantity := 4
+
+	// Setup expectations: 6 of 10 have already executed
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = (.+) FOR UPDATE").
+		WithArgs(int64(1), "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusPartiallyFilled, 6, 2, now, now))
+	mock.ExpectRollback()
+
+	// Call the Amend method
+	order, err := repo.Amend(context.Background(), "acme", 1, 2, nil, &quantity, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrQuantityBelowFilled)
+	assert.Nil(t, order)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAmendStopOrderPrice(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+	price := models.NewDecimal(151, 0)
+
+	// Setup expectations: stop orders become market orders and carry no price
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = (.+) FOR UPDATE").
+		WithArgs(int64(1), "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 0, 10, models.Sell, models.Stop, models.GoodTillCancel, nil, 145.0, nil, models.StatusNew, 0, 1, now, now))
+	mock.ExpectRollback()
+
+	// Call the Amend method
+	order, err := repo.Amend(context.Background(), "acme", 1, 1, &price, nil, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrPriceNotAmendable)
+	assert.Nil(t, order)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrderTriggers(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE id = (.+)").
+		WithArgs(int64(1), "acme").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 0, 10, models.Sell, models.Market, models.GoodTillCancel, nil, 145.0, now, models.StatusFilled, 10, 2, now, now))
+	mock.ExpectQuery("SELECT (.+) FROM order_triggers WHERE order_id = (.+) ORDER BY triggered_at, id").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "kind", "activated_kind", "trigger_price", "last_price", "reason", "triggered_at"}).
+			AddRow(1, 1, models.Stop, models.Market, 145.0, 144.5, "last trade price 144.5 is at or below trigger price 145", now))
+
+	// Call the GetTriggers method
+	triggers, err := repo.GetTriggers(context.Background(), "acme", 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, triggers, 1)
+	assert.Equal(t, models.Market, triggers[0].ActivatedKind)
+	assert.Equal(t, models.MustParseDecimal("144.5"), triggers[0].LastPrice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOpenOrders(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT (.+) FROM orders WHERE status IN (.+) ORDER BY created_at, id").
+		WithArgs(models.StatusNew, models.StatusPartiallyFilled).
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillCancel, nil, nil, nil, models.StatusPartiallyFilled, 4, 2, now, now))
+
+	// Call the GetOpen method
+	orders, err := repo.GetOpen(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, 6, orders[0].Remaining())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExpireOrders(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+	expiresAt := now.Add(-time.Minute)
+
+	// Setup expectations: only live orders past their expiry are touched
+	mock.ExpectQuery("UPDATE orders SET status = (.+) WHERE status = ANY(.+) AND expires_at <= (.+) RETURNING").
+		WithArgs(models.StatusExpired, now.UTC(), pq.Array([]string{"NEW", "PARTIALLY_FILLED"})).
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(1, "acme", nil, "AAPL", 150.5, 10, models.Buy, models.Limit, models.GoodTillDate, expiresAt, nil, nil, models.StatusExpired, 0, 2, now, now))
+
+	// Call the Expire method
+	orders, err := repo.Expire(context.Background(), now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, models.StatusExpired, orders[0].Status)
+	assert.Equal(t, models.GoodTillDate, orders[0].TimeInForce)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateOrderStoresFills(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+	order := &models.Order{Symbol: "AAPL", Price: models.NewDecimal(150, 0), Quantity: 4, OrderType: models.Buy}
+
+	// The new buy order 2 trades with sell stop 1, which the trade triggered
+	fills := Fills{
+		Orders: []models.Order{
+			{ID: 2, Kind: models.Limit, FilledQuantity: 4, Status: models.StatusPartiallyFilled, Version: 1},
+			{ID: 1, Kind: models.Market, FilledQuantity: 4, Status: models.StatusFilled, Version: 1, TriggeredAt: &now},
+		},
+		Trades: []models.Trade{{BuyOrderID: 2, SellOrderID: 1, Symbol: "AAPL", Price: models.NewDecimal(150, 0), Quantity: 4, ExecutedAt: now}},
+		Triggers: []models.OrderTrigger{{
+			OrderID:       1,
+			Kind:          models.Stop,
+			ActivatedKind: models.Market,
+			TriggerPrice:  models.NewDecimal(151, 0),
+			LastPrice:     models.NewDecimal(150, 0),
+			Reason:        "last trade price 150 is at or below trigger price 151",
+			TriggeredAt:   now,
+		}},
+	}
+
+	// Setup expectations: the order and its fills are written in one transaction
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO orders").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(2, 1))
+	mock.ExpectQuery("UPDATE orders SET filled_quantity").
+		WithArgs(4, models.StatusPartiallyFilled, models.Limit, nil, sqlmock.AnyArg(), int64(2), pq.Array([]string{"NEW", "PARTIALLY_FILLED"})).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "updated_at"}).AddRow(2, now))
+	mock.ExpectQuery("UPDATE orders SET filled_quantity").
+		WithArgs(4, models.StatusFilled, models.Market, now, sqlmock.AnyArg(), int64(1), pq.Array([]string{"NEW", "PARTIALLY_FILLED"})).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "updated_at"}).AddRow(2, now))
+	mock.ExpectQuery("INSERT INTO trades").
+		WithArgs(int64(2), int64(1), "AAPL", models.NewDecimal(150, 0), 4, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectQuery("INSERT INTO order_triggers").
+		WithArgs(int64(1), models.Stop, models.Market, models.NewDecimal(151, 0), models.NewDecimal(150, 0), "last trade price 150 is at or below trigger price 151", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectCommit()
+
+	// Call the Create method, matching the stored order
+	var matched models.Order
+	err = repo.Create(context.Background(), order, func(o models.Order) Fills {
+		matched = o
+		return fills
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), matched.ID)
+	assert.Equal(t, 2, fills.Orders[0].Version)
+	assert.Equal(t, 2, fills.Orders[1].Version)
+	assert.Equal(t, int64(7), fills.Trades[0].ID)
+	assert.Equal(t, int64(3), fills.Triggers[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateOrderFillsClosedOrder(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: the resting order is no longer live, so nothing is
+	// returned and the new order is rolled back with its fills
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO orders").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(2, 1))
+	mock.ExpectQuery("UPDATE orders SET filled_quantity").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "updated_at"}))
+	mock.ExpectRollback()
+
+	// Call the Create method
+	err = repo.Create(context.Background(), newBatchOrder(""), func(o models.Order) Fills {
+		return Fills{Orders: []models.Order{{ID: 1, FilledQuantity: 10, Status: models.StatusFilled}}}
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, lifecycle.ErrOrderClosed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateOrderBatchRollsBackUnstoredFills(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresOrderRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	orders := []*models.Order{newBatchOrder(""), newBatchOrder("")}
+
+	// Setup expectations: the second order's fills fail, taking the first
+	// order and its fills with them
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO orders").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "client_order_id"}).AddRow(10, 1, nil).AddRow(11, 1, nil))
+	mock.ExpectQuery("UPDATE orders SET filled_quantity").
+		WithArgs(5, models.StatusPartiallyFilled, models.Limit, nil, sqlmock.AnyArg(), int64(10), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "updated_at"}).AddRow(2, time.Now()))
+	mock.ExpectQuery("UPDATE orders SET filled_quantity").
+		WithArgs(5, models.StatusPartiallyFilled, models.Limit, nil, sqlmock.AnyArg(), int64(11), sqlmock.AnyArg()).
+		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	// Call the CreateBatch method, matching every order
+	var matched []int64
+	_, err = repo.CreateBatch(context.Background(), orders, false, func(o models.Order) Fills {
+		matched = append(matched, o.ID)
+		return Fills{Orders: []models.Order{{ID: o.ID, Kind: models.Limit, FilledQuantity: 5, Status: models.StatusPartiallyFilled}}}
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, []int64{10, 11}, matched)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
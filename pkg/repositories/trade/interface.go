@@ -0,0 +1,22 @@
+package trade
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Javlopez/go-api/pkg/models"
+)
+
+// ErrTradeNotFound is returned when no trade matches the requested ID
+var ErrTradeNotFound = errors.New("trade not found")
+
+// TradeRepository interface for trade operations. Trades are written together
+// with the orders and fills that produced them by order.OrderRepository.
+// An account of "" sees every trade; any other account only sees trades in
+// which one of its orders took part.
+type TradeRepository interface {
+	GetAll(ctx context.Context, filter models.TradeFilter) ([]models.Trade, error)
+	GetByID(ctx context.Context, account string, id int64) (*models.Trade, error)
+	// GetLatest returns the most recent trade of every symbol that has traded
+	GetLatest(ctx context.Context) ([]models.Trade, error)
+}
@@ -0,0 +1,100 @@
+package trade
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// tradeColumns lists the columns selected for every models.Trade
+const tradeColumns = "id, buy_order_id, sell_order_id, symbol, price, quantity, executed_at"
+
+// byAccount matches trades in which an order of the account given by its
+// parameter took part
+const byAccount = `EXISTS (SELECT 1 FROM orders WHERE orders.id IN (trades.buy_order_id, trades.sell_order_id) AND orders.account_id = $%d)`
+
+// PostgresTradeRepository is an implementation of TradeRepository
+type PostgresTradeRepository struct {
+	DB *sqlx.DB
+}
+
+// NewTradeRepository creates a new trade repository
+func NewTradeRepository(db *sqlx.DB) (TradeRepository, error) {
+	return &PostgresTradeRepository{DB: db}, nil
+}
+
+// GetAll retrieves trades matching the filter, most recent first
+func (r *PostgresTradeRepository) GetAll(ctx context.Context, filter models.TradeFilter) ([]models.Trade, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.AccountID != "" {
+		addCondition(byAccount, filter.AccountID)
+	}
+	if filter.Symbol != "" {
+		addCondition("symbol = $%d", filter.Symbol)
+	}
+	if filter.OrderID != 0 {
+		addCondition("$%d IN (buy_order_id, sell_order_id)", filter.OrderID)
+	}
+	if filter.From != nil {
+		addCondition("executed_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("executed_at < $%d", *filter.To)
+	}
+
+	query := `SELECT ` + tradeColumns + ` FROM trades`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY executed_at DESC, id DESC`
+
+	trades := []models.Trade{}
+	err := r.DB.SelectContext(ctx, &trades, query, args...)
+	return trades, err
+}
+
+// GetByID retrieves a single trade of account, returning ErrTradeNotFound if
+// it does not exist or the account took no part in it
+func (r *PostgresTradeRepository) GetByID(ctx context.Context, account string, id int64) (*models.Trade, error) {
+	var trade models.Trade
+	query := `
+		SELECT ` + tradeColumns + `
+		FROM trades
+		WHERE id = $1 AND ($2 = '' OR ` + fmt.Sprintf(byAccount, 2) + `)
+	`
+
+	err := r.DB.GetContext(ctx, &trade, query, id, account)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTradeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &trade, nil
+}
+
+// GetLatest retrieves the most recent trade of each symbol, which the
+// matching engine needs on startup to know every book's last trade price
+func (r *PostgresTradeRepository) GetLatest(ctx context.Context) ([]models.Trade, error) {
+	query := `
+		SELECT DISTINCT ON (symbol) ` + tradeColumns + `
+		FROM trades
+		ORDER BY symbol, executed_at DESC, id DESC
+	`
+
+	trades := []models.Trade{}
+	err := r.DB.SelectContext(ctx, &trades, query)
+	return trades, err
+}
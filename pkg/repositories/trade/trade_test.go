@@ -0,0 +1,161 @@
+package trade
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/lib/pq"
+)
+
+// tradeColumnNames mirrors tradeColumns for building mocked result rows
+var tradeColumnNames = []string{"id", "buy_order_id", "sell_order_id", "symbol", "price", "quantity", "executed_at"}
+
+func TestGetAllTrades(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresTradeRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations: no filters, no WHERE clause
+	mock.ExpectQuery("SELECT (.+) FROM trades ORDER BY executed_at DESC, id DESC").
+		WillReturnRows(sqlmock.NewRows(tradeColumnNames).
+			AddRow(2, 3, 1, "AAPL", 150.5, 4, now).
+			AddRow(1, 2, 1, "AAPL", 150.5, 6, now))
+
+	// Call the GetAll method
+	trades, err := repo.GetAll(context.Background(), models.TradeFilter{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, trades, 2)
+	assert.Equal(t, int64(2), trades[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllTradesWithFilters(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresTradeRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	from := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	// Setup expectations
+	mock.ExpectQuery(`SELECT (.+) FROM trades WHERE EXISTS \(SELECT 1 FROM orders WHERE orders.id IN \(trades.buy_order_id, trades.sell_order_id\) AND orders.account_id = \$1\) AND symbol = \$2 AND \$3 IN \(buy_order_id, sell_order_id\) AND executed_at >= \$4 AND executed_at < \$5`).
+		WithArgs("acme", "AAPL", int64(42), from, to).
+		WillReturnRows(sqlmock.NewRows(tradeColumnNames))
+
+	// Call the GetAll method
+	trades, err := repo.GetAll(context.Background(), models.TradeFilter{
+		AccountID: "acme",
+		Symbol:    "AAPL",
+		OrderID:   42,
+		From:      &from,
+		To:        &to,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, trades)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTradeByID(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresTradeRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations
+	mock.ExpectQuery("SELECT (.+) FROM trades WHERE id = (.+)").
+		WithArgs(int64(1), "").
+		WillReturnRows(sqlmock.NewRows(tradeColumnNames).AddRow(1, 2, 1, "AAPL", 150.5, 6, now))
+
+	// Call the GetByID method
+	trade, err := repo.GetByID(context.Background(), "", 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), trade.BuyOrderID)
+	assert.Equal(t, int64(1), trade.SellOrderID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLatestTrades(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresTradeRepository{DB: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	// Setup expectations: one row per symbol, the latest first
+	mock.ExpectQuery(`SELECT DISTINCT ON \(symbol\) (.+) FROM trades ORDER BY symbol, executed_at DESC, id DESC`).
+		WillReturnRows(sqlmock.NewRows(tradeColumnNames).
+			AddRow(7, 5, 6, "AAPL", 151, 3, now).
+			AddRow(4, 2, 3, "MSFT", 410.25, 1, now))
+
+	// Call the GetLatest method
+	trades, err := repo.GetLatest(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, "AAPL", trades[0].Symbol)
+		assert.Equal(t, "151", trades[0].Price.String())
+		assert.Equal(t, "MSFT", trades[1].Symbol)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTradeByIDNotFound(t *testing.T) {
+	// Create a new mock database
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error creating mock database: %v", err)
+	}
+	defer db.Close()
+
+	// Create repository with the mock
+	repo := &PostgresTradeRepository{DB: sqlx.NewDb(db, "sqlmock")}
+
+	// Setup expectations: the trade exists, but none of its orders are acme's
+	mock.ExpectQuery(`SELECT (.+) FROM trades WHERE id = \$1 AND \(\$2 = '' OR EXISTS \(SELECT 1 FROM orders WHERE (.+) AND orders.account_id = \$2\)\)`).
+		WithArgs(int64(42), "acme").
+		WillReturnRows(sqlmock.NewRows(tradeColumnNames))
+
+	// Call the GetByID method
+	trade, err := repo.GetByID(context.Background(), "acme", 42)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrTradeNotFound)
+	assert.Nil(t, trade)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
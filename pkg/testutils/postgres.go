@@ -2,10 +2,16 @@ package testutils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Javlopez/go-api/pkg/database"
+	"path/filepath"
+	"runtime"
 	"time"
 
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"github.com/testcontainers/testcontainers-go"
@@ -101,30 +107,67 @@ func (p *PostgresContainer) Connect() error {
 	return nil
 }
 
-// SetupOrdersTable creates the orders table in the test database
-func (p *PostgresContainer) SetupOrdersTable() error {
-	_, err := p.DB.Exec(`
-		CREATE TABLE IF NOT EXISTS orders (
-			id SERIAL PRIMARY KEY,
-			symbol VARCHAR(20) NOT NULL,
-			price DECIMAL(12, 4) NOT NULL,
-			quantity INTEGER NOT NULL,
-			order_type VARCHAR(10) NOT NULL,
-			created_at TIMESTAMP NOT NULL DEFAULT NOW()
-		)
-	`)
+// Migrate builds the test database schema by running the service's own
+// migrations, so tests write against the same tables and constraints as
+// production does
+func (p *PostgresContainer) Migrate() error {
+	driver, err := postgres.WithInstance(p.DB.DB, &postgres.Config{})
 	if err != nil {
-		return fmt.Errorf("failed to create test table: %w", err)
+		return fmt.Errorf("failed to create postgres driver: %w", err)
+	}
+
+	_, filename, _, _ := runtime.Caller(0)
+	migrationsPath := "file://" + filepath.Join(filepath.Dir(filename), "..", "..", "cmd", "migrate", "migrations")
+
+	m, err := migrate.NewWithDatabaseInstance(migrationsPath, "postgres", driver)
+	if err != nil {
+		return fmt.Errorf("failed to create migration instance: %w", err)
+	}
+
+	// m is not closed: that would close the container's connection with it
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 	return nil
 }
 
-// CleanupData removes all data from the orders table
+// CleanupData removes all data from the orders, trades, instruments, idempotency keys, used nonces, API keys and accounts tables
 func (p *PostgresContainer) CleanupData() error {
-	_, err := p.DB.Exec("DELETE FROM orders")
+	_, err := p.DB.Exec("DELETE FROM trades; DELETE FROM orders; DELETE FROM instruments; DELETE FROM idempotency_keys; DELETE FROM used_nonces; DELETE FROM api_keys; DELETE FROM accounts")
 	return err
 }
 
+// SeedAccounts registers accounts named after their IDs
+func (p *PostgresContainer) SeedAccounts(ids ...string) error {
+	for _, id := range ids {
+		_, err := p.DB.Exec(`
+			INSERT INTO accounts (id, name)
+			VALUES ($1, $1)
+			ON CONFLICT (id) DO NOTHING
+		`, id)
+		if err != nil {
+			return fmt.Errorf("failed to seed account %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// SeedInstruments lists tradable USD instruments with the finest tick and
+// single-unit lots, so orders in them are only bound by the order rules
+func (p *PostgresContainer) SeedInstruments(symbols ...string) error {
+	for _, symbol := range symbols {
+		_, err := p.DB.Exec(`
+			INSERT INTO instruments (symbol, currency, tick_size, lot_size, min_quantity)
+			VALUES ($1, 'USD', 0.0001, 1, 1)
+			ON CONFLICT (symbol) DO NOTHING
+		`, symbol)
+		if err != nil {
+			return fmt.Errorf("failed to seed instrument %s: %w", symbol, err)
+		}
+	}
+	return nil
+}
+
 // Close closes the database connection
 func (p *PostgresContainer) Close() {
 	if p.DB != nil {
@@ -0,0 +1,23 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), "jaeger")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestSetupWithoutExporter(t *testing.T) {
+	shutdown, err := Setup(context.Background(), ExporterNone)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
@@ -4,16 +4,29 @@ package integration
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/Javlopez/go-api/cmd/api/handlers"
+	"github.com/Javlopez/go-api/cmd/api/middleware"
+	"github.com/Javlopez/go-api/pkg/auth"
+	"github.com/Javlopez/go-api/pkg/expiry"
+	"github.com/Javlopez/go-api/pkg/matching"
 	"github.com/Javlopez/go-api/pkg/models"
+	"github.com/Javlopez/go-api/pkg/repositories/account"
+	"github.com/Javlopez/go-api/pkg/repositories/apikey"
+	"github.com/Javlopez/go-api/pkg/repositories/idempotency"
+	"github.com/Javlopez/go-api/pkg/repositories/instrument"
+	"github.com/Javlopez/go-api/pkg/repositories/nonce"
 	"github.com/Javlopez/go-api/pkg/repositories/order"
+	"github.com/Javlopez/go-api/pkg/repositories/trade"
 	"github.com/Javlopez/go-api/pkg/testutils"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -21,9 +34,13 @@ import (
 )
 
 var (
-	pgContainer *testutils.PostgresContainer
-	testRepo    order.OrderRepository
-	router      *gin.Engine
+	pgContainer    *testutils.PostgresContainer
+	testRepo       order.OrderRepository
+	tradeRepo      trade.TradeRepository
+	instrumentRepo instrument.InstrumentRepository
+	keyRepo        idempotency.KeyRepository
+	apiKeyRepo     apikey.APIKeyRepository
+	router         *gin.Engine
 )
 
 func TestMain(m *testing.M) {
@@ -38,18 +55,19 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
-	// Setup database schema
-	if err := pgContainer.SetupOrdersTable(); err != nil {
+	// Setup database schema from the migrations
+	if err := pgContainer.Migrate(); err != nil {
 		fmt.Printf("Failed to set up test database: %v\n", err)
 		pgContainer.Terminate(ctx)
 		os.Exit(1)
 	}
 
 	// Initialize repository
-	testRepo = &order.PostgresOrderRepository{DB: pgContainer.DB}
-
-	// Configure router
-	router = setupRouter()
+	testRepo = &order.PostgresOrderRepository{DB: pgContainer.DB, QueryTimeout: 5 * time.Second}
+	tradeRepo = &trade.PostgresTradeRepository{DB: pgContainer.DB}
+	instrumentRepo = &instrument.PostgresInstrumentRepository{DB: pgContainer.DB}
+	keyRepo = &idempotency.PostgresKeyRepository{DB: pgContainer.DB}
+	apiKeyRepo = &apikey.PostgresAPIKeyRepository{DB: pgContainer.DB}
 
 	// Run tests
 	code := m.Run()
@@ -62,19 +80,52 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+// resetState removes all orders, lists AAPL and MSFT again and starts a fresh
+// router with an empty order book
+func resetState() {
+	pgContainer.CleanupData()
+	pgContainer.SeedInstruments("AAPL", "MSFT")
+	pgContainer.SeedAccounts(testAccount)
+	router = setupRouter(matching.NewEngine())
+}
+
+// testAccount is the account every request of the test router acts for
+const testAccount = "acme"
+
 // setupRouter configures the test router
-func setupRouter() *gin.Engine {
+func setupRouter(engine *matching.Engine) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
 
+	// Act for the test account
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.PrincipalKey, &models.Principal{Subject: "test", Account: testAccount, Scopes: models.Scopes{models.ScopeAdmin}})
+	})
+
 	// Initialize handlers
-	orderHandler := handlers.NewOrderHandler(testRepo)
+	orderHandler := handlers.NewOrderHandler(testRepo, instrumentRepo, engine, nil)
+	tradeHandler := handlers.NewTradeHandler(tradeRepo)
+	instrumentHandler := handlers.NewInstrumentHandler(instrumentRepo)
+	idempotent := middleware.NewIdempotency(keyRepo, 24*time.Hour, time.Minute)
 
 	// Set up routes
 	api := r.Group("/api/v1")
 	{
-		api.POST("/orders", orderHandler.CreateOrder)
+		api.POST("/orders", idempotent.Handler(), orderHandler.CreateOrder)
+		api.POST("/orders/batch", idempotent.Handler(), orderHandler.CreateOrderBatch)
 		api.GET("/orders", orderHandler.GetOrders)
+		api.GET("/orders/:id", orderHandler.GetOrder)
+		api.GET("/orders/by-client-id/:clOrdId", orderHandler.GetOrderByClientID)
+		api.PATCH("/orders/:id", orderHandler.AmendOrder)
+		api.DELETE("/orders/:id", orderHandler.CancelOrder)
+		api.GET("/orders/:id/revisions", orderHandler.GetOrderRevisions)
+		api.GET("/orders/:id/triggers", orderHandler.GetOrderTriggers)
+		api.GET("/trades", tradeHandler.GetTrades)
+		api.GET("/trades/:id", tradeHandler.GetTrade)
+		api.POST("/admin/instruments", instrumentHandler.CreateInstrument)
+		api.GET("/admin/instruments/:symbol", instrumentHandler.GetInstrument)
+		api.PUT("/admin/instruments/:symbol", instrumentHandler.UpdateInstrument)
+		api.DELETE("/admin/instruments/:symbol", instrumentHandler.DeleteInstrument)
 	}
 
 	return r
@@ -83,12 +134,12 @@ func setupRouter() *gin.Engine {
 // TestCreateAndGetOrders tests creating an order and retrieving it
 func TestCreateAndGetOrders(t *testing.T) {
 	// Clean up any existing data first
-	pgContainer.CleanupData()
+	resetState()
 
 	// Test data
 	orderRequest := models.OrderRequest{
 		Symbol:    "AAPL",
-		Price:     150.5,
+		Price:     models.MustParseDecimal("150.5"),
 		Quantity:  10,
 		OrderType: models.Buy,
 	}
@@ -108,9 +159,10 @@ func TestCreateAndGetOrders(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &createdOrder)
 	require.NoError(t, err)
 	assert.Equal(t, "AAPL", createdOrder.Symbol)
-	assert.Equal(t, 150.5, createdOrder.Price)
+	assert.Equal(t, models.MustParseDecimal("150.5"), createdOrder.Price)
 	assert.Equal(t, 10, createdOrder.Quantity)
 	assert.Equal(t, models.Buy, createdOrder.OrderType)
+	assert.Equal(t, models.StatusNew, createdOrder.Status)
 	assert.Greater(t, createdOrder.ID, int64(0))
 
 	req, _ = http.NewRequest(http.MethodGet, "/api/v1/orders", nil)
@@ -120,16 +172,969 @@ func TestCreateAndGetOrders(t *testing.T) {
 	require.Equal(t, http.StatusOK, w.Code)
 
 	// Parse the response
-	var orders []models.Order
-	err = json.Unmarshal(w.Body.Bytes(), &orders)
+	var page models.Page[models.Order]
+	err = json.Unmarshal(w.Body.Bytes(), &page)
+	require.NoError(t, err)
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, createdOrder.ID, page.Data[0].ID)
+	assert.Equal(t, "AAPL", page.Data[0].Symbol)
+	assert.Empty(t, page.NextCursor)
+}
+
+// TestListOrdersPagination walks a filtered listing page by page
+func TestListOrdersPagination(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	var created []int64
+	for i := 0; i < 5; i++ {
+		body := fmt.Sprintf(`{"symbol": "AAPL", "price": %d, "quantity": 1, "order_type": "BUY"}`, 100+i)
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var order models.Order
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &order))
+		created = append(created, order.ID)
+	}
+
+	// A different symbol is filtered out
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(`{"symbol": "MSFT", "price": 250, "quantity": 1, "order_type": "BUY"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	var seen []int64
+	cursor := ""
+	for pages := 0; pages < 5; pages++ {
+		url := "/api/v1/orders?symbol=AAPL&status=NEW&sort=created_at&limit=2"
+		if cursor != "" {
+			url += "&cursor=" + cursor
+		}
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page models.Page[models.Order]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		for _, order := range page.Data {
+			seen = append(seen, order.ID)
+		}
+
+		cursor = page.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	// Every order appears exactly once, oldest first
+	assert.Equal(t, created, seen)
+}
+
+// TestGetOrderByID tests retrieving a single order after creating it
+func TestGetOrderByID(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	// Create an order
+	orderRequest := models.OrderRequest{
+		Symbol:    "MSFT",
+		Price:     models.MustParseDecimal("250.75"),
+		Quantity:  5,
+		OrderType: models.Sell,
+	}
+	jsonData, _ := json.Marshal(orderRequest)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var createdOrder models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdOrder))
+
+	// Fetch it back by ID
+	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/orders/%d", createdOrder.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var fetchedOrder models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetchedOrder))
+	assert.Equal(t, createdOrder.ID, fetchedOrder.ID)
+	assert.Equal(t, "MSFT", fetchedOrder.Symbol)
+	assert.Equal(t, models.MustParseDecimal("250.75"), fetchedOrder.Price)
+	assert.Equal(t, 5, fetchedOrder.Quantity)
+	assert.Equal(t, models.Sell, fetchedOrder.OrderType)
+}
+
+// TestGetOrderByIDNotFound tests that unknown order IDs return 404
+func TestGetOrderByIDNotFound(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/999999", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Order not found", response.Error)
+}
+
+// TestCancelOrder tests cancelling an order and rejecting a stale second cancel
+func TestCancelOrder(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	// Create an order
+	orderRequest := models.OrderRequest{
+		Symbol:    "AAPL",
+		Price:     models.MustParseDecimal("150.5"),
+		Quantity:  10,
+		OrderType: models.Buy,
+	}
+	jsonData, _ := json.Marshal(orderRequest)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var createdOrder models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdOrder))
+	require.Equal(t, 1, createdOrder.Version)
+
+	// Cancel it at the version we just read
+	cancelURL := fmt.Sprintf("/api/v1/orders/%d?version=%d", createdOrder.ID, createdOrder.Version)
+	req, _ = http.NewRequest(http.MethodDelete, cancelURL, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var cancelledOrder models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cancelledOrder))
+	assert.Equal(t, models.StatusCancelled, cancelledOrder.Status)
+	assert.Equal(t, 2, cancelledOrder.Version)
+
+	// A second cancel with the old version loses
+	req, _ = http.NewRequest(http.MethodDelete, cancelURL, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// The row is kept, only its status changed
+	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/orders/%d", createdOrder.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var fetchedOrder models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetchedOrder))
+	assert.Equal(t, models.StatusCancelled, fetchedOrder.Status)
+}
+
+// TestAmendOrder tests replacing an order's terms and reading back its revisions
+func TestAmendOrder(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	// Create an order
+	orderRequest := models.OrderRequest{
+		Symbol:    "AAPL",
+		Price:     models.MustParseDecimal("150.5"),
+		Quantity:  10,
+		OrderType: models.Buy,
+	}
+	jsonData, _ := json.Marshal(orderRequest)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var createdOrder models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &createdOrder))
+
+	// Replace the price
+	orderURL := fmt.Sprintf("/api/v1/orders/%d", createdOrder.ID)
+	req, _ = http.NewRequest(http.MethodPatch, orderURL, bytes.NewBufferString(`{"version": 1, "price": 151.25}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var amendedOrder models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &amendedOrder))
+	assert.Equal(t, createdOrder.ID, amendedOrder.ID)
+	assert.Equal(t, models.MustParseDecimal("151.25"), amendedOrder.Price)
+	assert.Equal(t, 10, amendedOrder.Quantity)
+	assert.Equal(t, 2, amendedOrder.Version)
+	assert.WithinDuration(t, createdOrder.CreatedAt, amendedOrder.CreatedAt, time.Millisecond)
+	assert.True(t, amendedOrder.UpdatedAt.After(amendedOrder.CreatedAt))
+
+	// Amending again with the old version loses
+	req, _ = http.NewRequest(http.MethodPatch, orderURL, bytes.NewBufferString(`{"version": 1, "quantity": 20}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// The replacement is recorded as a revision
+	req, _ = http.NewRequest(http.MethodGet, orderURL+"/revisions", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var revisions []models.OrderRevision
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &revisions))
+	require.Len(t, revisions, 1)
+	assert.Equal(t, 2, revisions[0].Version)
+	assert.Equal(t, models.MustParseDecimal("150.5"), revisions[0].PreviousPrice)
+	assert.Equal(t, models.MustParseDecimal("151.25"), revisions[0].Price)
+}
+
+// TestMatchingOrders tests that crossing orders execute and persist their fills
+func TestMatchingOrders(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	submit := func(request models.OrderRequest) models.Order {
+		jsonData, _ := json.Marshal(request)
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var created models.Order
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		return created
+	}
+
+	// A resting sell, then a larger crossing buy
+	sell := submit(models.OrderRequest{Symbol: "AAPL", Price: models.MustParseDecimal("150"), Quantity: 4, OrderType: models.Sell})
+	assert.Equal(t, models.StatusNew, sell.Status)
+
+	buy := submit(models.OrderRequest{Symbol: "AAPL", Price: models.MustParseDecimal("151"), Quantity: 10, OrderType: models.Buy})
+	assert.Equal(t, models.StatusPartiallyFilled, buy.Status)
+	assert.Equal(t, 4, buy.FilledQuantity)
+
+	// The resting sell is stored as filled
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/orders/%d", sell.ID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var fetchedSell models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetchedSell))
+	assert.Equal(t, models.StatusFilled, fetchedSell.Status)
+	assert.Equal(t, 4, fetchedSell.FilledQuantity)
+
+	// The rest of the buy is live and restored by GetOpen
+	openOrders, err := testRepo.GetOpen(context.Background())
 	require.NoError(t, err)
-	assert.Len(t, orders, 1)
-	assert.Equal(t, createdOrder.ID, orders[0].ID)
-	assert.Equal(t, "AAPL", orders[0].Symbol)
+	require.Len(t, openOrders, 1)
+	assert.Equal(t, buy.ID, openOrders[0].ID)
+	assert.Equal(t, 6, openOrders[0].Remaining())
+
+	// The execution is recorded as a trade
+	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/trades?symbol=AAPL&order_id=%d", sell.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var trades []models.Trade
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trades))
+	require.Len(t, trades, 1)
+	assert.Equal(t, buy.ID, trades[0].BuyOrderID)
+	assert.Equal(t, sell.ID, trades[0].SellOrderID)
+	assert.Equal(t, models.MustParseDecimal("150.0"), trades[0].Price)
+	assert.Equal(t, 4, trades[0].Quantity)
+
+	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/trades/%d", trades[0].ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Nothing traded in a window that ended before the execution
+	to := trades[0].ExecutedAt.Add(-time.Minute).UTC().Format(time.RFC3339)
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/trades?to="+to, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trades))
+	assert.Empty(t, trades)
+}
+
+// TestTradesAreScopedToAccount tests that an account only sees the trades
+// one of its orders took part in, while administrators see every trade
+func TestTradesAreScopedToAccount(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+	pgContainer.SeedAccounts("globex")
+
+	// One engine shared by routers acting for each account
+	engine := matching.NewEngine()
+	orderHandler := handlers.NewOrderHandler(testRepo, instrumentRepo, engine, nil)
+	tradeHandler := handlers.NewTradeHandler(tradeRepo)
+	routerFor := func(account string) *gin.Engine {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			c.Set(middleware.PrincipalKey, &models.Principal{Subject: account + "-bot", Account: account, Scopes: models.Scopes{models.ScopeOrdersWrite}})
+		})
+		r.POST("/api/v1/orders", orderHandler.CreateOrder)
+		r.GET("/api/v1/trades", tradeHandler.GetTrades)
+		r.GET("/api/v1/trades/:id", tradeHandler.GetTrade)
+		r.GET("/api/v1/admin/trades", tradeHandler.AdminGetTrades)
+		r.GET("/api/v1/admin/trades/:id", tradeHandler.AdminGetTrade)
+		return r
+	}
+	routers := map[string]*gin.Engine{testAccount: routerFor(testAccount), "globex": routerFor("globex"), "": routerFor("")}
+
+	send := func(account, method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		routers[account].ServeHTTP(w, req)
+		return w
+	}
+	listTrades := func(account, path string) []models.Trade {
+		w := send(account, http.MethodGet, path, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var trades []models.Trade
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trades))
+		return trades
+	}
+
+	// acme trades with globex, then globex trades with itself
+	for _, placed := range []struct{ account, body string }{
+		{testAccount, `{"symbol": "AAPL", "price": 150, "quantity": 4, "order_type": "SELL"}`},
+		{"globex", `{"symbol": "AAPL", "price": 150, "quantity": 4, "order_type": "BUY"}`},
+		{"globex", `{"symbol": "AAPL", "price": 160, "quantity": 2, "order_type": "SELL"}`},
+		{"globex", `{"symbol": "AAPL", "price": 160, "quantity": 2, "order_type": "BUY"}`},
+	} {
+		w := send(placed.account, http.MethodPost, "/api/v1/orders", placed.body)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	globexTrades := listTrades("globex", "/api/v1/trades")
+	require.Len(t, globexTrades, 2)
+	acmeTrades := listTrades(testAccount, "/api/v1/trades")
+	require.Len(t, acmeTrades, 1)
+	assert.Equal(t, 4, acmeTrades[0].Quantity)
+
+	// The trade acme took no part in is hidden from it
+	w := send(testAccount, http.MethodGet, fmt.Sprintf("/api/v1/trades/%d", acmeTrades[0].ID), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = send(testAccount, http.MethodGet, fmt.Sprintf("/api/v1/trades/%d", globexTrades[0].ID), "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Administrators reconcile across accounts
+	assert.Len(t, listTrades("", "/api/v1/admin/trades"), 2)
+	assert.Len(t, listTrades("", "/api/v1/admin/trades?account_id="+testAccount), 1)
+	w = send("", http.MethodGet, fmt.Sprintf("/api/v1/admin/trades/%d", globexTrades[0].ID), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestMarketOrders tests that market orders take liquidity and are rejected on an empty book
+func TestMarketOrders(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	submit := func(body string) models.Order {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var created models.Order
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		return created
+	}
+
+	// Nothing to trade against
+	rejected := submit(`{"symbol": "AAPL", "quantity": 5, "order_type": "BUY", "kind": "MARKET"}`)
+	assert.Equal(t, models.Market, rejected.Kind)
+	assert.Equal(t, models.StatusRejected, rejected.Status)
+
+	// Against resting liquidity the order fills at the resting price
+	submit(`{"symbol": "AAPL", "price": 150.5, "quantity": 5, "order_type": "SELL"}`)
+	filled := submit(`{"symbol": "AAPL", "quantity": 5, "order_type": "BUY", "kind": "MARKET"}`)
+	assert.Equal(t, models.StatusFilled, filled.Status)
+	assert.Equal(t, 5, filled.FilledQuantity)
+
+	// Market orders must not carry a price
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(`{"symbol": "AAPL", "price": 150.5, "quantity": 5, "order_type": "BUY", "kind": "MARKET"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestTimeInForce tests IOC, FOK and GTD orders end to end
+func TestTimeInForce(t *testing.T) {
+	// Clean up any existing data first, keeping hold of the engine for the expiry worker
+	pgContainer.CleanupData()
+	pgContainer.SeedInstruments("AAPL")
+	pgContainer.SeedAccounts(testAccount)
+	engine := matching.NewEngine()
+	router = setupRouter(engine)
+
+	submit := func(body string) models.Order {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var created models.Order
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		return created
+	}
+
+	// The default is GTC, which rests
+	resting := submit(`{"symbol": "AAPL", "price": 150, "quantity": 5, "order_type": "SELL"}`)
+	assert.Equal(t, models.GoodTillCancel, resting.TimeInForce)
+	assert.Nil(t, resting.ExpiresAt)
+
+	// FOK cannot fill 10 against 5 and does not trade
+	killed := submit(`{"symbol": "AAPL", "price": 150, "quantity": 10, "order_type": "BUY", "time_in_force": "FOK"}`)
+	assert.Equal(t, models.StatusCancelled, killed.Status)
+	assert.Equal(t, 0, killed.FilledQuantity)
+
+	// IOC takes the 5 available and cancels the rest
+	ioc := submit(`{"symbol": "AAPL", "price": 150, "quantity": 10, "order_type": "BUY", "time_in_force": "IOC"}`)
+	assert.Equal(t, models.StatusCancelled, ioc.Status)
+	assert.Equal(t, 5, ioc.FilledQuantity)
+
+	// GTD rests until its expiry, then the worker expires it
+	expiresAt := time.Now().Add(time.Second).UTC().Format(time.RFC3339Nano)
+	gtd := submit(`{"symbol": "AAPL", "price": 149, "quantity": 5, "order_type": "BUY", "time_in_force": "GTD", "expires_at": "` + expiresAt + `"}`)
+	assert.Equal(t, models.StatusNew, gtd.Status)
+	require.NotNil(t, gtd.ExpiresAt)
+
+	time.Sleep(1500 * time.Millisecond)
+	expired, err := expiry.NewWorker(testRepo, engine, time.Minute).ExpireDue(context.Background())
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, gtd.ID, expired[0].ID)
+
+	stored, err := testRepo.GetByID(context.Background(), "", gtd.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusExpired, stored.Status)
+	assert.Equal(t, gtd.Version+1, stored.Version)
+
+	// The expired order no longer matches
+	bids, _ := engine.Depth("AAPL")
+	assert.Empty(t, bids)
+}
+
+// TestStopOrders tests that a trade triggers a stop order and audits the trigger
+func TestStopOrders(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	submit := func(body string) models.Order {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var created models.Order
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		return created
+	}
+
+	// A sell stop waits below the market
+	submit(`{"symbol": "AAPL", "price": 144, "quantity": 5, "order_type": "BUY"}`)
+	submit(`{"symbol": "AAPL", "price": 140, "quantity": 5, "order_type": "BUY"}`)
+	stop := submit(`{"symbol": "AAPL", "trigger_price": 145, "quantity": 5, "order_type": "SELL", "kind": "STOP"}`)
+	assert.Equal(t, models.Stop, stop.Kind)
+	assert.Equal(t, models.StatusNew, stop.Status)
+
+	// A trade at 144 triggers it, and it sells into the next bid
+	submit(`{"symbol": "AAPL", "price": 144, "quantity": 5, "order_type": "SELL"}`)
+
+	stored, err := testRepo.GetByID(context.Background(), "", stop.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Market, stored.Kind)
+	assert.Equal(t, models.StatusFilled, stored.Status)
+	require.NotNil(t, stored.TriggeredAt)
+
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/orders/%d/triggers", stop.ID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var triggers []models.OrderTrigger
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &triggers))
+	require.Len(t, triggers, 1)
+	assert.Equal(t, models.Stop, triggers[0].Kind)
+	assert.Equal(t, models.Market, triggers[0].ActivatedKind)
+	assert.Equal(t, models.MustParseDecimal("144.0"), triggers[0].LastPrice)
+	assert.NotEmpty(t, triggers[0].Reason)
+}
+
+// TestIdempotentCreateOrder tests that retries with an Idempotency-Key create one order
+func TestIdempotentCreateOrder(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	submit := func(key, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	body := `{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"}`
+
+	first := submit("retry-1", body)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	// An identical retry replays the original response
+	retry := submit("retry-1", body)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+
+	// Reusing the key for another order is rejected
+	other := submit("retry-1", `{"symbol": "AAPL", "price": 151, "quantity": 10, "order_type": "BUY"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, other.Code)
+
+	// A rejected request leaves the key free for a corrected one
+	invalid := submit("retry-2", `{"symbol": "AAPL", "quantity": 10, "order_type": "BUY"}`)
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, http.StatusCreated, submit("retry-2", body).Code)
+
+	page, err := testRepo.GetAll(context.Background(), models.OrderFilter{})
+	require.NoError(t, err)
+	assert.Len(t, page.Data, 2)
+}
+
+// TestIdempotencyTakesOverAbandonedKey tests that a key left reserved by a
+// request that never answered is only blocked until the reservation times out
+func TestIdempotencyTakesOverAbandonedKey(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	submit := func(key string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(`{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// A request that is still within the reservation timeout holds its key
+	_, err := keyRepo.Reserve(ctx, "test", "in-flight", "hash", now, now.Add(-24*time.Hour), now.Add(-time.Minute))
+	require.NoError(t, err)
+	existing, err := keyRepo.Reserve(ctx, "test", "in-flight", "hash", now, now.Add(-24*time.Hour), now.Add(-time.Minute))
+	require.NoError(t, err)
+	if assert.NotNil(t, existing) {
+		assert.Nil(t, existing.StatusCode)
+	}
+
+	// One that crashed before answering is taken over by the retry
+	crashedAt := now.Add(-2 * time.Minute)
+	_, err = keyRepo.Reserve(ctx, "test", "crashed", "other", crashedAt, crashedAt.Add(-24*time.Hour), crashedAt.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, submit("crashed").Code)
+
+	page, err := testRepo.GetAll(ctx, models.OrderFilter{})
+	require.NoError(t, err)
+	assert.Len(t, page.Data, 1)
+}
+
+// TestClientOrderID tests duplicate detection and lookup by client order ID
+func TestClientOrderID(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	submit := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := submit(`{"client_order_id": "oms-1", "symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	// Reusing the client order ID returns the existing order
+	w = submit(`{"client_order_id": "oms-1", "symbol": "AAPL", "price": 151, "quantity": 5, "order_type": "BUY"}`)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	var duplicate models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &duplicate))
+	assert.Equal(t, created.ID, duplicate.ID)
+
+	// Orders without a client order ID never collide
+	assert.Equal(t, http.StatusCreated, submit(`{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"}`).Code)
+	assert.Equal(t, http.StatusCreated, submit(`{"symbol": "AAPL", "price": 150.5, "quantity": 10, "order_type": "BUY"}`).Code)
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders/by-client-id/oms-1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var found models.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &found))
+	assert.Equal(t, created.ID, found.ID)
+
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/orders/by-client-id/oms-9", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestOrderBatch tests that batches are created atomically or per order
+func TestOrderBatch(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	submit := func(mode, body string) (*httptest.ResponseRecorder, models.BatchOrderResponse) {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orders/batch?mode="+mode, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response models.BatchOrderResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+	countOrders := func() int {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var page models.Page[models.Order]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return len(page.Data)
+	}
+
+	// An all-or-nothing batch is created in full
+	w, response := submit("all_or_nothing", `[
+		{"client_order_id": "oms-1", "symbol": "AAPL", "price": 150, "quantity": 10, "order_type": "SELL"},
+		{"symbol": "AAPL", "price": 150, "quantity": 4, "order_type": "BUY"}
+	]`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Len(t, response.Results, 2)
+	assert.Equal(t, models.StatusFilled, response.Results[1].Order.Status)
+	assert.Equal(t, 2, countOrders())
+
+	// A duplicate client order ID rolls back the whole batch
+	w, response = submit("all_or_nothing", `[
+		{"symbol": "MSFT", "price": 310, "quantity": 5, "order_type": "BUY"},
+		{"client_order_id": "oms-1", "symbol": "MSFT", "price": 310, "quantity": 5, "order_type": "BUY"}
+	]`)
+	require.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, http.StatusConflict, response.Results[1].Status)
+	assert.Equal(t, 2, countOrders())
+
+	// Best effort creates the valid orders and reports the rest
+	w, response = submit("best_effort", `[
+		{"symbol": "MSFT", "price": 310, "quantity": 5, "order_type": "BUY"},
+		{"symbol": "TSLA", "price": 200, "quantity": 5, "order_type": "BUY"},
+		{"client_order_id": "oms-1", "symbol": "MSFT", "price": 310, "quantity": 5, "order_type": "BUY"}
+	]`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, response.Results, 3)
+	assert.Equal(t, http.StatusCreated, response.Results[0].Status)
+	assert.Equal(t, http.StatusBadRequest, response.Results[1].Status)
+	assert.Equal(t, http.StatusConflict, response.Results[2].Status)
+	assert.Equal(t, 3, countOrders())
+}
+
+// TestInstruments tests that orders follow the instrument master
+func TestInstruments(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// List TSLA on a 0.05 tick in lots of 10
+	w := send(http.MethodPost, "/api/v1/admin/instruments", `{"symbol": "TSLA", "description": "Tesla Inc.", "currency": "USD", "tick_size": 0.05, "lot_size": 10}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = send(http.MethodPost, "/api/v1/admin/instruments", `{"symbol": "TSLA", "currency": "USD", "tick_size": 0.05, "lot_size": 10}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Unknown symbols and orders off the tick or lot are rejected
+	w = send(http.MethodPost, "/api/v1/orders", `{"symbol": "TSLX", "price": 250, "quantity": 10, "order_type": "BUY"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = send(http.MethodPost, "/api/v1/orders", `{"symbol": "TSLA", "price": 250.01, "quantity": 10, "order_type": "BUY"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = send(http.MethodPost, "/api/v1/orders", `{"symbol": "TSLA", "price": 250.05, "quantity": 15, "order_type": "BUY"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = send(http.MethodPost, "/api/v1/orders", `{"symbol": "TSLA", "price": 250.05, "quantity": 20, "order_type": "BUY"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// A halted instrument takes no new orders and cannot be deleted while it has orders
+	w = send(http.MethodPut, "/api/v1/admin/instruments/TSLA", `{"currency": "USD", "tick_size": 0.05, "lot_size": 10, "tradable": false}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = send(http.MethodPost, "/api/v1/orders", `{"symbol": "TSLA", "price": 250.05, "quantity": 20, "order_type": "BUY"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = send(http.MethodDelete, "/api/v1/admin/instruments/TSLA", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// MSFT has no orders and can be deleted
+	w = send(http.MethodDelete, "/api/v1/admin/instruments/MSFT", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = send(http.MethodGet, "/api/v1/admin/instruments/MSFT", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestAPIKeys tests issuing, using, rotating and revoking API keys
+func TestAPIKeys(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	// Authenticated routes: key administration and reading orders
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := r.Group("/api/v1", middleware.Authenticate(auth.NewAPIKeyAuthenticator(apiKeyRepo, "bootstrap-secret")))
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyRepo)
+	orderHandler := handlers.NewOrderHandler(testRepo, instrumentRepo, matching.NewEngine(), nil)
+	api.GET("/orders", middleware.RequireScope(models.ScopeOrdersRead), orderHandler.GetOrders)
+	admin := api.Group("/admin", middleware.RequireScope(models.ScopeAdmin))
+	admin.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+	admin.GET("/api-keys", apiKeyHandler.GetAPIKeys)
+	admin.POST("/api-keys/:id/rotate", apiKeyHandler.RotateAPIKey)
+	admin.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+
+	send := func(method, path, key, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+key)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// The bootstrap key issues a read-only key
+	w := send(http.MethodPost, "/api/v1/admin/api-keys", "bootstrap-secret", `{"name": "reader", "scopes": ["orders:read"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var issued models.APIKeySecret
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &issued))
+
+	// It can read orders but not administer keys
+	w = send(http.MethodGet, "/api/v1/orders", issued.Key, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = send(http.MethodGet, "/api/v1/admin/api-keys", issued.Key, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Rotating it retires the old secret
+	w = send(http.MethodPost, fmt.Sprintf("/api/v1/admin/api-keys/%d/rotate", issued.ID), "bootstrap-secret", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var rotated models.APIKeySecret
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	w = send(http.MethodGet, "/api/v1/orders", issued.Key, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = send(http.MethodGet, "/api/v1/orders", rotated.Key, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Revoking it locks it out for good
+	w = send(http.MethodDelete, fmt.Sprintf("/api/v1/admin/api-keys/%d", issued.ID), "bootstrap-secret", "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = send(http.MethodGet, "/api/v1/orders", rotated.Key, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = send(http.MethodDelete, fmt.Sprintf("/api/v1/admin/api-keys/%d", issued.ID), "bootstrap-secret", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// The listing keeps the revoked key without its secret
+	w = send(http.MethodGet, "/api/v1/admin/api-keys", "bootstrap-secret", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var keys []models.APIKey
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &keys))
+	if assert.Len(t, keys, 1) {
+		assert.NotNil(t, keys[0].RevokedAt)
+		assert.Equal(t, rotated.Prefix, keys[0].Prefix)
+	}
+	assert.NotContains(t, w.Body.String(), rotated.Key)
+}
+
+// TestAccounts tests that API keys only see their own account's orders while
+// administrators see every account's
+func TestAccounts(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	// Authenticated routes: account administration and orders
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := r.Group("/api/v1", middleware.Authenticate(auth.NewAPIKeyAuthenticator(apiKeyRepo, "bootstrap-secret")))
+	accountHandler := handlers.NewAccountHandler(&account.PostgresAccountRepository{DB: pgContainer.DB})
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyRepo)
+	orderHandler := handlers.NewOrderHandler(testRepo, instrumentRepo, matching.NewEngine(), nil)
+	orders := api.Group("/orders", middleware.RequireAccount())
+	orders.POST("", orderHandler.CreateOrder)
+	orders.GET("", orderHandler.GetOrders)
+	orders.GET("/:id", orderHandler.GetOrder)
+	admin := api.Group("/admin", middleware.RequireScope(models.ScopeAdmin))
+	admin.POST("/accounts", accountHandler.CreateAccount)
+	admin.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+	admin.GET("/orders", orderHandler.AdminGetOrders)
+	admin.GET("/orders/:id", orderHandler.AdminGetOrder)
+
+	send := func(method, path, key, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+key)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Open a second account; keys can only name registered accounts
+	w := send(http.MethodPost, "/api/v1/admin/accounts", "bootstrap-secret", `{"id": "globex", "name": "Globex"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = send(http.MethodPost, "/api/v1/admin/accounts", "bootstrap-secret", `{"id": "globex", "name": "Globex"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = send(http.MethodPost, "/api/v1/admin/api-keys", "bootstrap-secret", `{"name": "ghost", "account_id": "ghost", "scopes": ["orders:write"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	keys := map[string]string{}
+	for _, id := range []string{testAccount, "globex"} {
+		w = send(http.MethodPost, "/api/v1/admin/api-keys", "bootstrap-secret", fmt.Sprintf(`{"name": "%s-bot", "account_id": "%s", "scopes": ["orders:write"]}`, id, id))
+		require.Equal(t, http.StatusCreated, w.Code)
+		var issued models.APIKeySecret
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &issued))
+		keys[id] = issued.Key
+	}
+
+	// Each account places an order under the same client order ID
+	placed := map[string]models.Order{}
+	for id, key := range keys {
+		w = send(http.MethodPost, "/api/v1/orders", key, `{"client_order_id": "clord-1", "symbol": "AAPL", "price": 150, "quantity": 10, "order_type": "BUY"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var created models.Order
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		require.NotNil(t, created.AccountID)
+		assert.Equal(t, id, *created.AccountID)
+		placed[id] = created
+	}
+
+	// An account sees its own order but not the other's
+	w = send(http.MethodGet, fmt.Sprintf("/api/v1/orders/%d", placed[testAccount].ID), keys[testAccount], "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = send(http.MethodGet, fmt.Sprintf("/api/v1/orders/%d", placed["globex"].ID), keys[testAccount], "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send(http.MethodGet, "/api/v1/orders?account_id=globex", keys[testAccount], "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var page models.Page[models.Order]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	if assert.Len(t, page.Data, 1) {
+		assert.Equal(t, placed[testAccount].ID, page.Data[0].ID)
+	}
+
+	// Keys without an account cannot reach the order routes
+	w = send(http.MethodGet, "/api/v1/orders", "bootstrap-secret", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Administrators see every account's orders
+	w = send(http.MethodGet, fmt.Sprintf("/api/v1/admin/orders/%d", placed["globex"].ID), "bootstrap-secret", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = send(http.MethodGet, "/api/v1/admin/orders", "bootstrap-secret", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Len(t, page.Data, 2)
+	w = send(http.MethodGet, "/api/v1/admin/orders?account_id=globex", "bootstrap-secret", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	if assert.Len(t, page.Data, 1) {
+		assert.Equal(t, placed["globex"].ID, page.Data[0].ID)
+	}
+}
+
+// TestSignedRequests tests that signed requests are verified against the
+// stored signing secret and that nonces cannot be replayed
+func TestSignedRequests(t *testing.T) {
+	// Clean up any existing data first
+	resetState()
+
+	// Routes that require API key callers to sign
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	signatures := middleware.NewSignatures(apiKeyRepo, &nonce.PostgresNonceRepository{DB: pgContainer.DB}, middleware.SigningRequired, 5*time.Minute, false)
+	api := r.Group("/api/v1", middleware.Authenticate(auth.NewAPIKeyAuthenticator(apiKeyRepo, "")), signatures.Handler())
+	orderHandler := handlers.NewOrderHandler(testRepo, instrumentRepo, matching.NewEngine(), nil)
+	api.GET("/orders", orderHandler.GetOrders)
+
+	// Issue a key with its signing secret
+	key, prefix, keyHash, err := apikey.Generate()
+	require.NoError(t, err)
+	signingSecret, err := apikey.GenerateSigningSecret()
+	require.NoError(t, err)
+	stored := &models.APIKey{Name: "signer", Prefix: prefix, KeyHash: keyHash, Scopes: models.Scopes{models.ScopeOrdersRead}, SigningSecret: signingSecret}
+	require.NoError(t, apiKeyRepo.Create(context.Background(), stored))
+
+	send := func(secret, nonceValue string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/orders?limit=5", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		if secret != "" {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			req.Header.Set(middleware.SignatureKeyIDHeader, strconv.FormatInt(stored.ID, 10))
+			req.Header.Set(middleware.SignatureTimestampHeader, timestamp)
+			req.Header.Set(middleware.SignatureNonceHeader, nonceValue)
+			req.Header.Set(middleware.SignatureHeader, hex.EncodeToString(middleware.Sign(secret, timestamp, nonceValue, req, nil)))
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Unsigned and wrongly signed requests are refused
+	assert.Equal(t, http.StatusUnauthorized, send("", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("guessed", "nonce-0001").Code)
+
+	// A signed request passes once per nonce
+	assert.Equal(t, http.StatusOK, send(signingSecret, "nonce-0002").Code)
+	assert.Equal(t, http.StatusUnauthorized, send(signingSecret, "nonce-0002").Code)
+	assert.Equal(t, http.StatusOK, send(signingSecret, "nonce-0003").Code)
 }
 
 // TestCreateOrderValidation tests validation on order creation
 func TestCreateOrderValidation(t *testing.T) {
+	resetState()
+
 	// Test cases
 	testCases := []struct {
 		name        string
@@ -139,7 +1144,7 @@ func TestCreateOrderValidation(t *testing.T) {
 		{
 			name: "Missing Symbol",
 			request: models.OrderRequest{
-				Price:     150.5,
+				Price:     models.MustParseDecimal("150.5"),
 				Quantity:  10,
 				OrderType: models.Buy,
 			},
@@ -149,7 +1154,7 @@ func TestCreateOrderValidation(t *testing.T) {
 			name: "Invalid Price",
 			request: models.OrderRequest{
 				Symbol:    "AAPL",
-				Price:     -10, // Invalid: price must be > 0
+				Price:     models.NewDecimal(-10, 0), // Invalid: price must be > 0
 				Quantity:  10,
 				OrderType: models.Buy,
 			},
@@ -159,7 +1164,7 @@ func TestCreateOrderValidation(t *testing.T) {
 			name: "Invalid Quantity",
 			request: models.OrderRequest{
 				Symbol:    "AAPL",
-				Price:     150.5,
+				Price:     models.MustParseDecimal("150.5"),
 				Quantity:  0, // Invalid: quantity must be > 0
 				OrderType: models.Buy,
 			},
@@ -169,7 +1174,7 @@ func TestCreateOrderValidation(t *testing.T) {
 			name: "Invalid Order Type",
 			request: models.OrderRequest{
 				Symbol:    "AAPL",
-				Price:     150.5,
+				Price:     models.MustParseDecimal("150.5"),
 				Quantity:  10,
 				OrderType: "INVALID", // Invalid: must be BUY or SELL
 			},